@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/omer/go-bot/internal/artifact"
+	"github.com/omer/go-bot/internal/config"
+	"github.com/omer/go-bot/internal/logger"
+	"github.com/omer/go-bot/internal/task"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Failures command flags
+	failuresDB    string
+	failuresQueue string
+	failuresType  string
+	failuresAll   bool
+)
+
+// newFailuresCmd creates the failures command and its subcommands
+func newFailuresCmd() *cobra.Command {
+	failuresCmd := &cobra.Command{
+		Use:   "failures",
+		Short: "Inspect and requeue failed tasks",
+		Long: `Inspect the tasks that failed after all retries and requeue them.
+The bot must not be running: it keeps the failure and queue databases locked.`,
+	}
+	failuresCmd.PersistentFlags().StringVar(&failuresDB, "db", "data/failures.db", "Path to the failed task database")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List failed tasks",
+		Args:  cobra.NoArgs,
+		RunE:  runFailuresList,
+	}
+	listCmd.Flags().StringVarP(&failuresType, "type", "t", "", "Only failures of this error type, e.g. timeout, captcha or selector (empty = all)")
+
+	showCmd := &cobra.Command{
+		Use:   "show <task-id>",
+		Short: "Show the details of a failed task",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runFailuresShow,
+	}
+
+	requeueCmd := &cobra.Command{
+		Use:   "requeue [task-id...]",
+		Short: "Requeue failed tasks",
+		Long:  "Add failed tasks to the durable task queue; they run on the next start",
+		RunE:  runFailuresRequeue,
+	}
+	requeueCmd.Flags().StringVar(&failuresQueue, "queue", "data/queue.db", "Path to the durable task queue")
+	requeueCmd.Flags().BoolVar(&failuresAll, "all", false, "Requeue every failed task that is not already requeued")
+	requeueCmd.Flags().StringVarP(&failuresType, "type", "t", "", "With --all, only failures of this error type (empty = all)")
+
+	failuresCmd.AddCommand(listCmd, showCmd, requeueCmd)
+	return failuresCmd
+}
+
+// runFailuresList executes the failures list command
+func runFailuresList(cmd *cobra.Command, args []string) error {
+	store, err := task.OpenDeadLetterStore(failuresDB)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	letters, err := listDeadLetters(store, failuresType)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🪦 %d failed tasks\n", len(letters))
+	fmt.Println("─────────────────────────────")
+	for _, letter := range letters {
+		status := "❌"
+		if letter.Pending() {
+			status = "🔁"
+		}
+		fmt.Printf("%s %s  [%s] %s  %s (%d attempts) %s\n",
+			status, letter.Task.ID, letter.Task.Keyword, letter.Task.TargetURL,
+			letter.ErrorType, len(letter.Attempts), letter.FailedAt.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+// runFailuresShow executes the failures show command
+func runFailuresShow(cmd *cobra.Command, args []string) error {
+	store, err := task.OpenDeadLetterStore(failuresDB)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	letter, err := store.Get(args[0])
+	if err != nil {
+		return err
+	}
+
+	t := letter.Task
+	fmt.Printf("🪦 Task %s\n", t.ID)
+	fmt.Println("═══════════════════════")
+	fmt.Printf("Keyword: %s\n", t.Keyword)
+	fmt.Printf("Target: %s\n", t.TargetURL)
+	fmt.Printf("Type: %s\n", t.Type)
+	if !t.Locale.IsZero() {
+		fmt.Printf("Locale: %s\n", t.Locale)
+	}
+	fmt.Printf("Failed at: %s\n", letter.FailedAt.Format(time.RFC3339))
+	fmt.Printf("Error type: %s\n", letter.ErrorType)
+	fmt.Printf("Error: %s\n", letter.Error)
+	if letter.RequeuedAt != nil {
+		fmt.Printf("Requeued: %d times, last at %s\n", letter.Requeued, letter.RequeuedAt.Format(time.RFC3339))
+	}
+
+	if page := letter.Page; page != nil {
+		fmt.Println("\n📄 Last page")
+		fmt.Printf("  URL: %s\n", page.URL)
+		fmt.Printf("  Title: %s\n", page.Title)
+		if page.Artifacts != "" {
+			fmt.Printf("  Artifacts: %s\n", page.Artifacts)
+		}
+	}
+
+	fmt.Printf("\n🔁 %d attempts\n", len(letter.Attempts))
+	for i, attempt := range letter.Attempts {
+		fmt.Printf("%d. %s (%.2fs)", i+1, attempt.StartedAt.Format("2006-01-02 15:04:05"), attempt.Duration.Seconds())
+		if attempt.Error != "" {
+			fmt.Printf(" [%s] %s", attempt.ErrorType, attempt.Error)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// runFailuresRequeue executes the failures requeue command
+func runFailuresRequeue(cmd *cobra.Command, args []string) error {
+	if failuresAll == (len(args) > 0) {
+		return fmt.Errorf("pass task IDs or --all")
+	}
+
+	store, err := task.OpenDeadLetterStore(failuresDB)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ids := args
+	if failuresAll {
+		letters, err := listDeadLetters(store, failuresType)
+		if err != nil {
+			return err
+		}
+		for _, letter := range letters {
+			if !letter.Pending() {
+				ids = append(ids, letter.Task.ID)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		fmt.Println("No failed tasks to requeue")
+		return nil
+	}
+
+	queue, err := task.OpenBoltQueue(failuresQueue, task.BoltQueueConfig{})
+	if err != nil {
+		return err
+	}
+	defer queue.Close()
+
+	for _, id := range ids {
+		if err := store.Requeue(context.Background(), id, queue); err != nil {
+			return err
+		}
+		fmt.Printf("🔁 Requeued %s\n", id)
+	}
+	fmt.Printf("\n%d tasks will run on the next start\n", len(ids))
+	return nil
+}
+
+// listDeadLetters lists the dead letters, optionally of one error type only
+func listDeadLetters(store *task.DeadLetterStore, errorType string) ([]*task.DeadLetter, error) {
+	letters, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list failed tasks: %w", err)
+	}
+	if errorType == "" {
+		return letters, nil
+	}
+
+	filtered := letters[:0]
+	for _, letter := range letters {
+		if letter.ErrorType == errorType {
+			filtered = append(filtered, letter)
+		}
+	}
+	return filtered, nil
+}
+
+// openDeadLetters opens the store of failed tasks. It returns nil, and
+// failures are only logged, when the store cannot be opened.
+func openDeadLetters(log *logger.Logger) *task.DeadLetterStore {
+	store, err := task.OpenDeadLetterStore("data/failures.db")
+	if err != nil {
+		log.Warn("Failed task store disabled", map[string]interface{}{
+			"error": err,
+		})
+		return nil
+	}
+	return store
+}
+
+// newArtifactStore creates the store for debug artifacts of failed tasks and
+// drops the artifacts that expired while the bot was not running
+func newArtifactStore(cfg *config.Config, log *logger.Logger) *artifact.Store {
+	store := artifact.NewStore(artifact.StoreConfig{
+		Dir:      cfg.Artifacts.Dir,
+		MaxTasks: cfg.Artifacts.MaxTasks,
+		MaxAge:   time.Duration(cfg.Artifacts.RetentionDays) * 24 * time.Hour,
+	})
+	if err := store.Prune(); err != nil {
+		log.Warn("Failed to prune debug artifacts", map[string]interface{}{
+			"error": err,
+		})
+	}
+	return store
+}
+
+// closeDeadLetters closes the store of failed tasks
+func closeDeadLetters(store *task.DeadLetterStore, log *logger.Logger) {
+	if store == nil {
+		return
+	}
+
+	if err := store.Close(); err != nil {
+		log.Error("Failed to close failed task store", map[string]interface{}{
+			"error": err,
+		})
+	}
+}
+
+// recordDeadLetter keeps a failed task for triage, and forgets the failure
+// of a requeued task once it succeeds. Tasks aborted by a shutdown are not
+// failures; they are never delivered as results.
+func recordDeadLetter(store *task.DeadLetterStore, result *task.TaskResult, log *logger.Logger) {
+	if store == nil {
+		return
+	}
+
+	var err error
+	if result.Success {
+		err = store.Resolve(result.Task.ID)
+	} else {
+		err = store.Add(task.NewDeadLetter(result))
+	}
+	if err != nil {
+		log.Error("Failed to record failed task", map[string]interface{}{
+			"task_id": result.Task.ID,
+			"error":   err,
+		})
+	}
+}
@@ -2,17 +2,27 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/omer/go-bot/internal/alert"
+	"github.com/omer/go-bot/internal/api"
+	"github.com/omer/go-bot/internal/browser"
 	"github.com/omer/go-bot/internal/config"
+	"github.com/omer/go-bot/internal/health"
 	"github.com/omer/go-bot/internal/logger"
+	"github.com/omer/go-bot/internal/metrics"
 	"github.com/omer/go-bot/internal/proxy"
+	"github.com/omer/go-bot/internal/report"
+	"github.com/omer/go-bot/internal/serp"
 	"github.com/omer/go-bot/internal/stats"
 	"github.com/omer/go-bot/internal/task"
+	"github.com/omer/go-bot/internal/urlmatch"
 	"github.com/spf13/cobra"
 )
 
@@ -29,6 +39,23 @@ var (
 	interval    int
 	continuous  bool
 	enableStats bool
+	maxPages    int
+	listenAddr  string
+
+	// History command flags
+	historyDB          string
+	historyKeyword     string
+	historyTarget      string
+	historyLocale      string
+	historyDays        int
+	historyGranularity string
+
+	// Report command flags (also uses the history flags above)
+	reportDomain string
+	reportFrom   string
+	reportTo     string
+	reportFormat string
+	reportOutput string
 )
 
 func main() {
@@ -42,6 +69,7 @@ finding target URLs, and clicking on them to improve search rankings.
 Features:
   - Automated Google search
   - Target URL finding and clicking
+  - Read-only rank tracking
   - Proxy rotation support
   - Concurrent task execution
   - Statistics tracking
@@ -61,9 +89,24 @@ Features:
 	startCmd.Flags().StringVarP(&logLevel, "log-level", "l", "", "Log level (debug, info, warn, error)")
 	startCmd.Flags().BoolVar(&headless, "headless", true, "Run browser in headless mode")
 	startCmd.Flags().IntVarP(&workers, "workers", "w", 0, "Number of worker goroutines (0 = use config value)")
-	startCmd.Flags().IntVarP(&interval, "interval", "i", 0, "Interval between cycles in seconds (0 = use config value)")
-	startCmd.Flags().BoolVar(&continuous, "continuous", false, "Run continuously in a loop")
+	startCmd.Flags().IntVarP(&interval, "interval", "i", 0, "Interval between checks of keywords without a schedule, in seconds (0 = use config value)")
+	startCmd.Flags().BoolVar(&continuous, "continuous", false, "Run continuously, checking every keyword on its schedule")
 	startCmd.Flags().BoolVar(&enableStats, "stats", true, "Enable statistics collection")
+	startCmd.Flags().IntVarP(&maxPages, "pages", "p", 0, "Maximum result pages to scan per keyword (0 = use config value)")
+	startCmd.Flags().StringVar(&listenAddr, "listen", "", "Serve the read-only status API and /metrics on this address, e.g. \":8080\" (empty = disabled)")
+
+	// Track command
+	trackCmd := &cobra.Command{
+		Use:   "track",
+		Short: "Track keyword rankings",
+		Long:  "Look up the ranking position of every configured target without clicking any result",
+		RunE:  runTrack,
+	}
+	trackCmd.Flags().StringVarP(&configFile, "config", "c", "configs/config.json", "Path to configuration file")
+	trackCmd.Flags().StringVarP(&logLevel, "log-level", "l", "", "Log level (debug, info, warn, error)")
+	trackCmd.Flags().IntVarP(&workers, "workers", "w", 0, "Number of worker goroutines (0 = use config value)")
+	trackCmd.Flags().IntVarP(&maxPages, "pages", "p", 0, "Maximum result pages to scan per keyword (0 = use config value)")
+	trackCmd.Flags().BoolVar(&enableStats, "stats", true, "Enable statistics collection")
 
 	// Stats command
 	statsCmd := &cobra.Command{
@@ -74,6 +117,37 @@ Features:
 	}
 	statsCmd.Flags().IntVarP(&workers, "recent", "n", 10, "Number of recent tasks to show")
 
+	// History command
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show rank history",
+		Long:  "Show the rank history of a keyword and target over time, or list the tracked series when no keyword is given",
+		RunE:  runHistory,
+	}
+	historyCmd.Flags().StringVar(&historyDB, "db", "data/history.db", "Path to the rank history database")
+	historyCmd.Flags().StringVarP(&historyKeyword, "keyword", "k", "", "Keyword to show")
+	historyCmd.Flags().StringVarP(&historyTarget, "target", "t", "", "Target domain or URL as configured")
+	historyCmd.Flags().StringVar(&historyLocale, "locale", "", "Search context, e.g. \"hl=tr,gl=TR\" (empty = no locale)")
+	historyCmd.Flags().IntVarP(&historyDays, "days", "d", 30, "Number of days to show")
+	historyCmd.Flags().StringVarP(&historyGranularity, "granularity", "g", "day", "Bucket size (hour, day, week)")
+
+	// Report command
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Export a rank report",
+		Long:  "Export per-keyword position tables, trends and success rates from the rank history as CSV, JSON or a static HTML page",
+		RunE:  runReport,
+	}
+	reportCmd.Flags().StringVar(&historyDB, "db", "data/history.db", "Path to the rank history database")
+	reportCmd.Flags().StringVarP(&historyKeyword, "keyword", "k", "", "Only this keyword (empty = all)")
+	reportCmd.Flags().StringVar(&reportDomain, "domain", "", "Only targets on this domain and its subdomains (empty = all)")
+	reportCmd.Flags().StringVar(&reportFrom, "from", "", "Start date, YYYY-MM-DD (default: --days ago)")
+	reportCmd.Flags().StringVar(&reportTo, "to", "", "End date, YYYY-MM-DD, inclusive (default: today)")
+	reportCmd.Flags().IntVarP(&historyDays, "days", "d", 30, "Number of days to report when --from is not set")
+	reportCmd.Flags().StringVarP(&historyGranularity, "granularity", "g", "day", "Bucket size (hour, day, week)")
+	reportCmd.Flags().StringVarP(&reportFormat, "format", "f", "html", "Output format (csv, json, html)")
+	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", "", "Output file (default: stdout)")
+
 	// Health command
 	healthCmd := &cobra.Command{
 		Use:   "health",
@@ -85,8 +159,13 @@ Features:
 
 	// Add commands
 	rootCmd.AddCommand(startCmd)
+	rootCmd.AddCommand(trackCmd)
 	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(reportCmd)
 	rootCmd.AddCommand(healthCmd)
+	rootCmd.AddCommand(newFailuresCmd())
+	rootCmd.AddCommand(newParseCmd())
 
 	// Execute
 	if err := rootCmd.Execute(); err != nil {
@@ -99,11 +178,304 @@ Features:
 func runStart(cmd *cobra.Command, args []string) error {
 	fmt.Printf("🚀 SERP Bot v%s\n\n", version)
 
-	// Load configuration
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if interval > 0 {
+		cfg.Interval = interval
+	}
+
+	log, err := newLogger(cfg)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	log.Info("SERP Bot starting", map[string]interface{}{
+		"version": version,
+		"workers": cfg.Workers,
+	})
+
+	proxyPool, err := newProxyPool(cfg, log)
+	if err != nil {
+		return err
+	}
+
+	statsCollector := newStatsCollector(cfg, log)
+	defer closeHistory(statsCollector, log)
+	alerts := newAlertEngine(cfg, statsCollector, log)
+
+	// Metrics are served by the status API
+	var appMetrics *metrics.Metrics
+	if listenAddr != "" {
+		appMetrics = metrics.New()
+	}
+
+	// Queued and running tasks survive restarts in the durable queue
+	queue := openQueue(cfg, log)
+	defer closeQueue(queue, log)
+
+	// Failed tasks are kept for triage with the failures command
+	deadLetters := openDeadLetters(log)
+	defer closeDeadLetters(deadLetters, log)
+	artifacts := newArtifactStore(cfg, log)
+
+	// Tasks share the pooled browser processes, if configured
+	browsers := newBrowserManager(cfg, appMetrics)
+	defer closeBrowserManager(browsers, log)
+
+	selectors, err := cfg.SelectorProfile()
+	if err != nil {
+		return err
+	}
+
+	// Initialize worker pool. The queue holds a full cycle, so the
+	// scheduler can submit every keyword before it collects results.
+	workerPool := task.NewWorkerPool(task.WorkerPoolConfig{
+		Workers:       cfg.Workers,
+		QueueSize:     max(cfg.Workers*2, len(cfg.Keywords)),
+		Queue:         queue,
+		ProxyPool:     proxyPool,
+		Logger:        log,
+		MaxPages:      cfg.MaxPages,
+		Metrics:       appMetrics,
+		PageTimeout:   time.Duration(cfg.PageTimeout) * time.Second,
+		SearchTimeout: time.Duration(cfg.SearchTimeout) * time.Second,
+		MaxRetries:    cfg.MaxRetries,
+		RetryDelay:    time.Duration(cfg.RetryDelay) * time.Second,
+		Artifacts:     artifacts,
+		Selectors:     selectors,
+		SearchURL:     cfg.SearchURL,
+		DriverFactory: driverFactory(browsers),
+	})
+
+	// Start worker pool
+	if err := workerPool.Start(); err != nil {
+		return fmt.Errorf("failed to start worker pool: %w", err)
+	}
+
+	// The scheduler is the only reader of the worker pool results
+	scheduler := task.NewScheduler(task.SchedulerConfig{
+		Config:     cfg,
+		WorkerPool: workerPool,
+		Logger:     log,
+		Interval:   time.Duration(cfg.Interval) * time.Second,
+		TaskType:   task.TaskTypeSearch,
+		Metrics:    appMetrics,
+		StatePath:  "data/schedule.json",
+		OnResult: func(result *task.TaskResult) {
+			logResult(log, result)
+			recordResult(statsCollector, alerts, result, log)
+			recordDeadLetter(deadLetters, result, log)
+		},
+		OnCycle: func(cycle int, err error) {
+			if err != nil {
+				fmt.Printf("\n❌ Cycle %d failed: %v\n", cycle, err)
+				return
+			}
+			fmt.Printf("\n✅ Cycle %d completed\n", cycle)
+			saveStats(statsCollector, log)
+		},
+	})
+
+	// Start the status API (opt-in)
+	var apiServer *api.Server
+	if listenAddr != "" {
+		apiServer, err = startAPI(api.Config{
+			Addr:       listenAddr,
+			WorkerPool: workerPool,
+			Scheduler:  scheduler,
+			ProxyPool:  proxyPool,
+			Health:     health.NewHealthChecker(cfg, log),
+			Stats:      statsCollector,
+			Metrics:    appMetrics,
+			Logger:     log,
+		})
+		if err != nil {
+			return err
+		}
+		defer stopAPI(apiServer, log)
+	}
+
+	// Setup graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	if err := scheduler.Start(continuous); err != nil {
+		return fmt.Errorf("failed to start scheduler: %w", err)
+	}
+
+	if apiServer != nil {
+		apiServer.SetReady(true)
+	}
+
+	if continuous {
+		fmt.Printf("\n🔁 Checking %d keywords on their schedules (Ctrl+C to stop)\n", len(cfg.Keywords))
+	} else {
+		fmt.Printf("\n⏳ Checking %d keywords... (Ctrl+C to stop)\n", len(cfg.Keywords))
+	}
+
+	// Wait for the single cycle to finish or for a shutdown signal
+	interrupted := false
+	select {
+	case <-scheduler.Done():
+	case <-sigChan:
+		interrupted = true
+		fmt.Println("\n\n🛑 Shutdown signal received...")
+	}
+
+	if err := scheduler.Stop(); err != nil {
+		log.Error("Error stopping scheduler", map[string]interface{}{
+			"error": err,
+		})
+	}
+
+	// Stop worker pool
+	log.Info("Stopping worker pool", nil)
+	if err := workerPool.Stop(); err != nil {
+		log.Error("Error stopping worker pool", map[string]interface{}{
+			"error": err,
+		})
+	}
+
+	// Completed cycles are already saved; save the results of an interrupted one
+	if interrupted {
+		saveStats(statsCollector, log)
+	}
+
+	fmt.Println("\n✨ Shutdown complete. Goodbye!")
+	return nil
+}
+
+// runTrack executes the track command
+// It runs one read-only rank check per keyword and exits when all are done.
+func runTrack(cmd *cobra.Command, args []string) error {
+	fmt.Printf("📈 SERP Bot v%s - rank tracking\n\n", version)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	log, err := newLogger(cfg)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	proxyPool, err := newProxyPool(cfg, log)
+	if err != nil {
+		return err
+	}
+
+	provider, err := newProvider(cfg)
+	if err != nil {
+		return err
+	}
+
+	statsCollector := newStatsCollector(cfg, log)
+	defer closeHistory(statsCollector, log)
+	alerts := newAlertEngine(cfg, statsCollector, log)
+
+	deadLetters := openDeadLetters(log)
+	defer closeDeadLetters(deadLetters, log)
+	artifacts := newArtifactStore(cfg, log)
+
+	browsers := newBrowserManager(cfg, nil)
+	defer closeBrowserManager(browsers, log)
+
+	selectors, err := cfg.SelectorProfile()
+	if err != nil {
+		return err
+	}
+
+	workerPool := task.NewWorkerPool(task.WorkerPoolConfig{
+		Workers:       cfg.Workers,
+		QueueSize:     len(cfg.Keywords),
+		ProxyPool:     proxyPool,
+		Logger:        log,
+		MaxPages:      cfg.MaxPages,
+		Provider:      provider,
+		PageTimeout:   time.Duration(cfg.PageTimeout) * time.Second,
+		SearchTimeout: time.Duration(cfg.SearchTimeout) * time.Second,
+		MaxRetries:    cfg.MaxRetries,
+		RetryDelay:    time.Duration(cfg.RetryDelay) * time.Second,
+		Artifacts:     artifacts,
+		Selectors:     selectors,
+		SearchURL:     cfg.SearchURL,
+		DriverFactory: driverFactory(browsers),
+	})
+	if err := workerPool.Start(); err != nil {
+		return fmt.Errorf("failed to start worker pool: %w", err)
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	submitted := submitTasks(workerPool, cfg, task.TaskTypeRankCheck, log)
+	fmt.Printf("🔎 Checking %d keywords (up to %d pages each)\n\n", submitted, cfg.MaxPages)
+
+	// Collect one result per submitted task
+	results := make([]*task.TaskResult, 0, submitted)
+collect:
+	for len(results) < submitted {
+		select {
+		case result := <-workerPool.GetResults():
+			logResult(log, result)
+			recordResult(statsCollector, alerts, result, log)
+			recordDeadLetter(deadLetters, result, log)
+			results = append(results, result)
+		case <-sigChan:
+			fmt.Println("\n🛑 Shutdown signal received...")
+			break collect
+		}
+	}
+
+	if err := workerPool.Stop(); err != nil {
+		log.Error("Error stopping worker pool", map[string]interface{}{
+			"error": err,
+		})
+	}
+
+	fmt.Println("\n📊 Rankings:")
+	fmt.Println("─────────────────────────────")
+	for _, result := range results {
+		switch {
+		case result.Success && result.Outcome == string(serp.OutcomeNotInTop):
+			fmt.Printf("➖ [%s] %s -> Not in top %d\n",
+				result.Task.Keyword, result.Task.TargetURL, result.Depth)
+		case result.Success:
+			fmt.Printf("✅ [%s] %s -> Position: %d (page %d, visual %d)\n",
+				result.Task.Keyword, result.Task.TargetURL, result.Position, result.PageNumber, result.VisualPosition)
+		default:
+			fmt.Printf("❌ [%s] %s -> %v\n", result.Task.Keyword, result.Task.TargetURL, result.Error)
+		}
+		if !result.Task.Locale.IsZero() {
+			fmt.Printf("   Locale: %s\n", result.Task.Locale)
+		}
+		if len(result.Features) > 0 {
+			fmt.Printf("   SERP features: %s\n", strings.Join(result.Features, ", "))
+		}
+		for _, competitor := range result.Competitors {
+			if competitor.Position > 0 {
+				fmt.Printf("   vs %s -> Position: %d (page %d)\n", competitor.TargetURL, competitor.Position, competitor.PageNumber)
+			} else if result.Success {
+				fmt.Printf("   vs %s -> Not in top %d\n", competitor.TargetURL, result.Depth)
+			}
+		}
+	}
+
+	saveStats(statsCollector, log)
+	return nil
+}
+
+// loadConfig loads the configuration file and applies command line overrides
+func loadConfig() (*config.Config, error) {
 	fmt.Printf("📋 Loading configuration from: %s\n", configFile)
 	cfg, err := config.Load(configFile)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
 	// Override with flags
@@ -113,16 +485,44 @@ func runStart(cmd *cobra.Command, args []string) error {
 	if workers > 0 {
 		cfg.Workers = workers
 	}
-	if interval > 0 {
-		cfg.Interval = interval
+	if maxPages > 0 {
+		cfg.MaxPages = maxPages
+	}
+	if cfg.MaxPages == 0 {
+		cfg.MaxPages = 5
 	}
 
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
-		return fmt.Errorf("invalid configuration: %w", err)
+		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	// Initialize logger
+	return cfg, nil
+}
+
+// startAPI starts the status API
+func startAPI(apiConfig api.Config) (*api.Server, error) {
+	server := api.NewServer(apiConfig)
+	if err := server.Start(); err != nil {
+		return nil, err
+	}
+	fmt.Printf("🌐 Status API listening on http://%s\n", server.Addr())
+	return server, nil
+}
+
+// stopAPI shuts the status API down, waiting up to 5 seconds for open requests
+func stopAPI(server *api.Server, log *logger.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Stop(ctx); err != nil {
+		log.Error("Error stopping API server", map[string]interface{}{
+			"error": err,
+		})
+	}
+}
+
+// newLogger initializes the application logger from the configuration
+func newLogger(cfg *config.Config) (*logger.Logger, error) {
 	logConfig := logger.Config{
 		Level:      logger.LogLevel(cfg.LogLevel),
 		LogFile:    cfg.LogFile,
@@ -130,105 +530,296 @@ func runStart(cmd *cobra.Command, args []string) error {
 	}
 	log, err := logger.New(logConfig)
 	if err != nil {
-		return fmt.Errorf("failed to initialize logger: %w", err)
+		return nil, fmt.Errorf("failed to initialize logger: %w", err)
 	}
-	defer log.Close()
+	return log, nil
+}
 
-	log.Info("SERP Bot starting", map[string]interface{}{
-		"version": version,
-		"workers": cfg.Workers,
+// newProxyPool initializes the proxy pool, or returns nil when no proxies are configured
+func newProxyPool(cfg *config.Config, log *logger.Logger) (*proxy.ProxyPool, error) {
+	var proxyPool *proxy.ProxyPool
+	if len(cfg.Proxies) == 0 {
+		log.Warn("No proxies configured - running without proxy", nil)
+		return proxyPool, nil
+	}
+
+	log.Info("Initializing proxy pool", map[string]interface{}{
+		"proxies": len(cfg.Proxies),
+	})
+	proxyPool, err := proxy.NewProxyPool(cfg.Proxies, proxy.RotationStrategyRoundRobin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize proxy pool: %w", err)
+	}
+	return proxyPool, nil
+}
+
+// newBrowserManager creates the browser processes tasks share.
+// It returns nil when every task launches its own browser.
+func newBrowserManager(cfg *config.Config, appMetrics *metrics.Metrics) *browser.Manager {
+	if cfg.BrowserPool.Size == 0 {
+		return nil
+	}
+
+	manager := browser.NewManager(browser.ManagerConfig{
+		Size:     cfg.BrowserPool.Size,
+		MaxUses:  cfg.BrowserPool.MaxUses,
+		Headless: true,
 	})
+	appMetrics.WatchBrowserPool(func() metrics.BrowserPoolStats {
+		stats := manager.Stats()
+		return metrics.BrowserPoolStats{
+			Launches: stats.Launches,
+			Recycles: stats.Recycles,
+			Crashes:  stats.Crashes,
+			Active:   stats.Active,
+		}
+	})
+	return manager
+}
 
-	// Initialize proxy pool
-	var proxyPool *proxy.ProxyPool
-	if len(cfg.Proxies) > 0 {
-		log.Info("Initializing proxy pool", map[string]interface{}{
-			"proxies": len(cfg.Proxies),
+// driverFactory returns the factory of task browsers; nil selects the
+// worker pool default of one browser per task
+func driverFactory(manager *browser.Manager) browser.DriverFactory {
+	if manager == nil {
+		return nil
+	}
+	return manager.NewDriver
+}
+
+// closeBrowserManager stops the pooled browser processes and logs the pool counters
+func closeBrowserManager(manager *browser.Manager, log *logger.Logger) {
+	if manager == nil {
+		return
+	}
+
+	stats := manager.Stats()
+	log.Info("Stopping browser pool", map[string]interface{}{
+		"contexts":        stats.Contexts,
+		"launches":        stats.Launches,
+		"launch_failures": stats.LaunchFailures,
+		"recycles":        stats.Recycles,
+		"crashes":         stats.Crashes,
+	})
+	if err := manager.Close(); err != nil {
+		log.Error("Failed to stop browser pool", map[string]interface{}{
+			"error": err,
 		})
-		proxyPool, err = proxy.NewProxyPool(cfg.Proxies, proxy.RotationStrategyRoundRobin)
+	}
+}
+
+// newProvider creates the configured result provider.
+// It returns nil for the browser provider, which workers create per task.
+func newProvider(cfg *config.Config) (serp.Provider, error) {
+	var provider serp.Provider
+	switch cfg.Provider.Type {
+	case config.ProviderFixture:
+		profile, err := cfg.SelectorProfile()
 		if err != nil {
-			return fmt.Errorf("failed to initialize proxy pool: %w", err)
+			return nil, err
 		}
-	} else {
-		log.Warn("No proxies configured - running without proxy", nil)
+		provider = serp.NewFixtureProvider(cfg.Provider.FixtureDir).WithProfile(profile)
+	case config.ProviderHTTP:
+		httpProvider, err := serp.NewHTTPProvider(serp.HTTPProviderConfig{
+			Endpoint: cfg.Provider.Endpoint,
+			Headers:  cfg.Provider.Headers,
+			Timeout:  time.Duration(cfg.SearchTimeout) * time.Second,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize http provider: %w", err)
+		}
+		provider = httpProvider
+	}
+	return provider, nil
+}
+
+// newStatsCollector loads the statistics collector, or returns nil when stats are disabled.
+// Ranks are also appended to the history store; if it cannot be opened the
+// collector runs without it.
+func newStatsCollector(cfg *config.Config, log *logger.Logger) *stats.StatsCollector {
+	if !enableStats {
+		return nil
 	}
 
-	// Initialize statistics collector
 	var statsCollector *stats.StatsCollector
-	if enableStats {
+	if store := openHistory(cfg, log); store != nil {
+		statsCollector = stats.NewStatsCollectorWithStore("data/stats.json", store)
+	} else {
 		statsCollector = stats.NewStatsCollector("data/stats.json")
-		if err := statsCollector.Load(); err != nil {
-			log.Warn("Failed to load previous stats", map[string]interface{}{
-				"error": err,
-			})
-		}
 	}
+	if err := statsCollector.Load(); err != nil {
+		log.Warn("Failed to load previous stats", map[string]interface{}{
+			"error": err,
+		})
+	}
+	return statsCollector
+}
 
-	// Initialize worker pool
-	workerPool := task.NewWorkerPool(task.WorkerPoolConfig{
-		Workers:   cfg.Workers,
-		QueueSize: cfg.Workers * 2,
-		ProxyPool: proxyPool,
-		Logger:    log,
-	})
+// openHistory opens the rank history store and applies the retention policy
+func openHistory(cfg *config.Config, log *logger.Logger) stats.Store {
+	path := cfg.History.Path
+	if path == "" {
+		path = "data/history.db"
+	}
 
-	// Start worker pool
-	if err := workerPool.Start(); err != nil {
-		return fmt.Errorf("failed to start worker pool: %w", err)
+	store, err := stats.OpenBoltStore(path)
+	if err != nil {
+		log.Warn("Rank history disabled", map[string]interface{}{
+			"error": err,
+		})
+		return nil
 	}
 
-	// Setup graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	policy := stats.RetentionPolicy{
+		RawFor:  time.Duration(cfg.History.RawDays) * 24 * time.Hour,
+		KeepFor: time.Duration(cfg.History.RetentionDays) * 24 * time.Hour,
+	}
+	result, err := store.Compact(policy, time.Now())
+	if err != nil {
+		log.Warn("Failed to compact rank history", map[string]interface{}{
+			"error": err,
+		})
+	} else if result.RolledUp > 0 || result.Deleted > 0 {
+		log.Info("Compacted rank history", map[string]interface{}{
+			"rolled_up": result.RolledUp,
+			"deleted":   result.Deleted,
+		})
+	}
 
-	// Result processor goroutine
-	go func() {
-		for result := range workerPool.GetResults() {
-			if result.Success {
-				log.Info("Task completed successfully", map[string]interface{}{
-					"task_id":  result.Task.ID,
-					"keyword":  result.Task.Keyword,
-					"position": result.Position,
-					"duration": fmt.Sprintf("%.2fs", result.Duration.Seconds()),
-				})
-			} else {
-				log.Error("Task failed", map[string]interface{}{
-					"task_id": result.Task.ID,
-					"keyword": result.Task.Keyword,
-					"error":   result.Error,
-				})
-			}
+	return store
+}
 
-			// Record statistics
-			if enableStats && statsCollector != nil {
-				taskStats := stats.TaskStats{
-					TaskID:     result.Task.ID,
-					Keyword:    result.Task.Keyword,
-					TargetURL:  result.Task.TargetURL,
-					Success:    result.Success,
-					Position:   result.Position,
-					PageNumber: result.PageNumber,
-					Duration:   float64(result.Duration.Milliseconds()),
-					ProxyUsed:  result.Task.ProxyURL,
-					Timestamp:  time.Now(),
-				}
-				if result.Error != nil {
-					taskStats.Error = result.Error.Error()
-				}
-				statsCollector.RecordTask(taskStats)
-			}
+// openQueue opens the durable task queue and requeues the tasks the previous
+// run left unfinished. Tasks are leased for as long as a worker may run one
+// with its retries. It returns nil, and the worker pool falls back to an
+// in-memory queue, when the queue cannot be opened.
+func openQueue(cfg *config.Config, log *logger.Logger) task.Queue {
+	lease := task.WorkerPoolConfig{
+		MaxPages:      cfg.MaxPages,
+		PageTimeout:   time.Duration(cfg.PageTimeout) * time.Second,
+		SearchTimeout: time.Duration(cfg.SearchTimeout) * time.Second,
+		MaxRetries:    cfg.MaxRetries,
+		RetryDelay:    time.Duration(cfg.RetryDelay) * time.Second,
+	}.LeaseTimeout()
+
+	queue, err := task.OpenBoltQueue("data/queue.db", task.BoltQueueConfig{LeaseTimeout: lease})
+	if err != nil {
+		log.Warn("Durable task queue disabled", map[string]interface{}{
+			"error": err,
+		})
+		return nil
+	}
+
+	if waiting := queue.Len(); waiting > 0 {
+		log.Info("Resuming unfinished tasks", map[string]interface{}{
+			"waiting":   waiting,
+			"recovered": queue.Recovered(),
+		})
+	}
+	return queue
+}
+
+// closeQueue closes the durable task queue; its remaining tasks run on the next start
+func closeQueue(queue task.Queue, log *logger.Logger) {
+	if queue == nil {
+		return
+	}
+
+	if err := queue.Close(); err != nil {
+		log.Error("Failed to close task queue", map[string]interface{}{
+			"error": err,
+		})
+	}
+}
+
+// closeHistory closes the rank history store of a collector
+func closeHistory(statsCollector *stats.StatsCollector, log *logger.Logger) {
+	if statsCollector == nil || statsCollector.Store() == nil {
+		return
+	}
+
+	if err := statsCollector.Store().Close(); err != nil {
+		log.Error("Failed to close rank history", map[string]interface{}{
+			"error": err,
+		})
+	}
+}
+
+// newAlertEngine creates the rank change engine, or returns nil when no
+// notifier is configured. Changes are detected against the stats collector,
+// so alerts are disabled when stats are.
+func newAlertEngine(cfg *config.Config, statsCollector *stats.StatsCollector, log *logger.Logger) *alert.Engine {
+	if !cfg.Alerts.Enabled() {
+		return nil
+	}
+	if statsCollector == nil {
+		log.Warn("Alerts disabled: they require stats", nil)
+		return nil
+	}
+
+	var notifiers []alert.Notifier
+	if cfg.Alerts.Webhook.URL != "" {
+		webhook, err := alert.NewWebhookNotifier(alert.WebhookConfig{
+			URL:     cfg.Alerts.Webhook.URL,
+			Headers: cfg.Alerts.Webhook.Headers,
+		})
+		if err != nil {
+			log.Warn("Webhook alerts disabled", map[string]interface{}{
+				"error": err,
+			})
+		} else {
+			notifiers = append(notifiers, webhook)
+		}
+	}
+	if cfg.Alerts.Email.Host != "" {
+		email, err := alert.NewEmailNotifier(alert.EmailConfig{
+			Host:     cfg.Alerts.Email.Host,
+			Port:     cfg.Alerts.Email.Port,
+			Username: cfg.Alerts.Email.Username,
+			Password: cfg.Alerts.Email.Password,
+			From:     cfg.Alerts.Email.From,
+			To:       cfg.Alerts.Email.To,
+		})
+		if err != nil {
+			log.Warn("Email alerts disabled", map[string]interface{}{
+				"error": err,
+			})
+		} else {
+			notifiers = append(notifiers, email)
 		}
-	}()
+	}
+	if cfg.Alerts.File != "" {
+		notifiers = append(notifiers, alert.NewFileNotifier(cfg.Alerts.File))
+	}
 
-	// Submit tasks
+	log.Info("Rank change alerts enabled", map[string]interface{}{
+		"notifiers": len(notifiers),
+	})
+	return alert.NewEngine(alert.EngineConfig{
+		DropThreshold: cfg.Alerts.DropThreshold,
+		GainThreshold: cfg.Alerts.GainThreshold,
+		TopThresholds: cfg.Alerts.TopThresholds,
+		Competitors:   cfg.Alerts.Competitors,
+		Notifiers:     notifiers,
+	})
+}
+
+// submitTasks creates and submits one task of the given type per configured keyword.
+// It returns the number of tasks that were accepted by the pool.
+func submitTasks(workerPool *task.WorkerPool, cfg *config.Config, taskType task.TaskType, log *logger.Logger) int {
 	log.Info("Submitting tasks", map[string]interface{}{
 		"keywords": len(cfg.Keywords),
+		"type":     taskType,
 	})
 
+	submitted := 0
 	for _, keyword := range cfg.Keywords {
 		t, err := task.NewTask(task.TaskConfig{
-			Keyword:   keyword.Term,
-			TargetURL: keyword.TargetURL,
+			Keyword:     keyword.Term,
+			TargetURL:   keyword.TargetURL,
+			Match:       urlmatch.Mode(keyword.Match),
+			Competitors: keyword.Competitors,
+			Locale:      keyword.Locale(),
+			Type:        taskType,
 		})
 		if err != nil {
 			log.Error("Failed to create task", map[string]interface{}{
@@ -241,43 +832,80 @@ func runStart(cmd *cobra.Command, args []string) error {
 			log.Error("Failed to submit task", map[string]interface{}{
 				"error": err,
 			})
+			continue
 		}
+		submitted++
 	}
 
-	fmt.Printf("\n✅ %d tasks submitted\n", len(cfg.Keywords))
-	fmt.Println("⏳ Waiting for tasks to complete... (Ctrl+C to stop)")
+	return submitted
+}
 
-	// Wait for shutdown signal
-	<-sigChan
-	fmt.Println("\n\n🛑 Shutdown signal received...")
+// logResult logs the outcome of a finished task
+func logResult(log *logger.Logger, result *task.TaskResult) {
+	if result.Success {
+		log.Info("Task completed successfully", map[string]interface{}{
+			"task_id":  result.Task.ID,
+			"keyword":  result.Task.Keyword,
+			"position": result.Position,
+			"duration": fmt.Sprintf("%.2fs", result.Duration.Seconds()),
+		})
+	} else {
+		log.Error("Task failed", map[string]interface{}{
+			"task_id":  result.Task.ID,
+			"keyword":  result.Task.Keyword,
+			"error":    result.Error,
+			"attempts": result.Attempts,
+		})
+	}
+}
 
-	// Stop worker pool
-	log.Info("Stopping worker pool", nil)
-	if err := workerPool.Stop(); err != nil {
-		log.Error("Error stopping worker pool", map[string]interface{}{
+// recordResult records a finished task in the statistics collector
+// and sends alerts for the rank changes it caused
+func recordResult(statsCollector *stats.StatsCollector, alerts *alert.Engine, result *task.TaskResult, log *logger.Logger) {
+	if statsCollector == nil {
+		return
+	}
+	if alerts == nil {
+		statsCollector.RecordTask(result.ToStats())
+		return
+	}
+
+	events, err := alerts.Record(context.Background(), statsCollector, result.ToStats())
+	for _, event := range events {
+		log.Info("Rank change", map[string]interface{}{
+			"type":    event.Type,
+			"keyword": event.Keyword,
+			"target":  event.TargetURL,
+			"message": event.Message(),
+		})
+	}
+	if err != nil {
+		log.Error("Failed to send alerts", map[string]interface{}{
 			"error": err,
 		})
 	}
+}
 
-	// Save statistics
-	if enableStats && statsCollector != nil {
-		log.Info("Saving statistics", nil)
-		if err := statsCollector.Save(); err != nil {
-			log.Error("Failed to save statistics", map[string]interface{}{
-				"error": err,
-			})
-		} else {
-			summary := statsCollector.GetSummary()
-			fmt.Println("\n📊 Statistics:")
-			fmt.Printf("  Total tasks: %d\n", summary["total_tasks"])
-			fmt.Printf("  Success: %d\n", summary["success_tasks"])
-			fmt.Printf("  Failed: %d\n", summary["failed_tasks"])
-			fmt.Printf("  Success rate: %s\n", summary["success_rate"])
-		}
+// saveStats saves the collected statistics and prints a summary
+func saveStats(statsCollector *stats.StatsCollector, log *logger.Logger) {
+	if statsCollector == nil {
+		return
 	}
 
-	fmt.Println("\n✨ Shutdown complete. Goodbye!")
-	return nil
+	log.Info("Saving statistics", nil)
+	if err := statsCollector.Save(); err != nil {
+		log.Error("Failed to save statistics", map[string]interface{}{
+			"error": err,
+		})
+		return
+	}
+
+	summary := statsCollector.GetSummary()
+	fmt.Println("\n📊 Statistics:")
+	fmt.Printf("  Total tasks: %d\n", summary["total_tasks"])
+	fmt.Printf("  Success: %d\n", summary["success_tasks"])
+	fmt.Printf("  Failed: %d\n", summary["failed_tasks"])
+	fmt.Printf("  Success rate: %s\n", summary["success_rate"])
 }
 
 // runStats executes the stats command
@@ -310,6 +938,11 @@ func runStats(cmd *cobra.Command, args []string) error {
 			if !t.Success {
 				status = "❌"
 			}
+			if t.Outcome == stats.OutcomeNotInTop {
+				fmt.Printf("%d. %s [%s] %s -> Not in top %d (%.2fs)\n",
+					i+1, status, t.Keyword, t.TargetURL, t.Depth, t.Duration/1000)
+				continue
+			}
 			fmt.Printf("%d. %s [%s] %s -> Position: %d (%.2fs)\n",
 				i+1, status, t.Keyword, t.TargetURL, t.Position, t.Duration/1000)
 		}
@@ -318,6 +951,150 @@ func runStats(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runHistory executes the history command
+func runHistory(cmd *cobra.Command, args []string) error {
+	granularity, err := stats.ParseGranularity(historyGranularity)
+	if err != nil {
+		return err
+	}
+
+	store, err := stats.OpenBoltStore(historyDB)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if historyKeyword == "" {
+		series, err := store.Series()
+		if err != nil {
+			return fmt.Errorf("failed to list series: %w", err)
+		}
+		fmt.Printf("📚 %d tracked series\n", len(series))
+		for _, key := range series {
+			fmt.Printf("  [%s] %s", key.Keyword, key.TargetURL)
+			if key.Locale != "" {
+				fmt.Printf(" (%s)", key.Locale)
+			}
+			fmt.Println()
+		}
+		return nil
+	}
+	if historyTarget == "" {
+		return fmt.Errorf("--target is required with --keyword")
+	}
+
+	buckets, err := store.Query(stats.RankQuery{
+		Keyword:     historyKeyword,
+		TargetURL:   historyTarget,
+		Locale:      historyLocale,
+		From:        time.Now().AddDate(0, 0, -historyDays),
+		Granularity: granularity,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to query history: %w", err)
+	}
+
+	fmt.Printf("📈 [%s] %s - last %d days by %s\n", historyKeyword, historyTarget, historyDays, granularity)
+	fmt.Println("─────────────────────────────")
+	if len(buckets) == 0 {
+		fmt.Println("No history found")
+		return nil
+	}
+	for _, bucket := range buckets {
+		start := bucket.Start.Format("2006-01-02")
+		if granularity == stats.GranularityHour {
+			start = bucket.Start.Format("2006-01-02 15:04")
+		}
+		if bucket.Ranked == 0 {
+			fmt.Printf("%s  not ranked (%d checks)\n", start, bucket.Samples)
+			continue
+		}
+		fmt.Printf("%s  avg %.1f  best %d  worst %d  last %d (%d checks)\n",
+			start, bucket.AvgPosition, bucket.BestPosition, bucket.WorstPosition, bucket.LastPosition, bucket.Samples)
+	}
+
+	return nil
+}
+
+// runReport executes the report command
+func runReport(cmd *cobra.Command, args []string) error {
+	format, err := report.ParseFormat(reportFormat)
+	if err != nil {
+		return err
+	}
+	granularity, err := stats.ParseGranularity(historyGranularity)
+	if err != nil {
+		return err
+	}
+	from, to, err := reportRange(time.Now())
+	if err != nil {
+		return err
+	}
+
+	store, err := stats.OpenBoltStore(historyDB)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	rep, err := report.Build(store, report.Filter{
+		Keyword:     historyKeyword,
+		Domain:      reportDomain,
+		From:        from,
+		To:          to,
+		Granularity: granularity,
+	}, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to build report: %w", err)
+	}
+
+	if reportOutput == "" {
+		return report.Write(os.Stdout, rep, format)
+	}
+
+	f, err := os.Create(reportOutput)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	if err := report.Write(f, rep, format); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+
+	fmt.Printf("📄 Report with %d series written to %s\n", len(rep.Series), reportOutput)
+	return nil
+}
+
+// reportRange parses the --from and --to flags.
+// Dates are UTC days; --to includes the whole day.
+func reportRange(now time.Time) (from, to time.Time, err error) {
+	to = now
+	if reportTo != "" {
+		day, err := time.Parse("2006-01-02", reportTo)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid --to date: %w", err)
+		}
+		to = day.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	from = to.AddDate(0, 0, -historyDays)
+	if reportFrom != "" {
+		day, err := time.Parse("2006-01-02", reportFrom)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid --from date: %w", err)
+		}
+		from = day
+	}
+
+	if to.Before(from) {
+		return from, to, fmt.Errorf("--to is before --from")
+	}
+	return from, to, nil
+}
+
 // runHealth executes the health command
 func runHealth(cmd *cobra.Command, args []string) error {
 	fmt.Println("🏥 SERP Bot Health Check")
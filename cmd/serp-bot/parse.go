@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/omer/go-bot/internal/config"
+	"github.com/omer/go-bot/internal/serp"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Parse command flags
+	parseProfile string
+	parseConfig  string
+)
+
+// newParseCmd creates the parse command
+func newParseCmd() *cobra.Command {
+	parseCmd := &cobra.Command{
+		Use:   "parse <file.html>",
+		Short: "Parse a saved result page",
+		Long: `Run the results parser on a saved SERP HTML file ("-" reads stdin) and print the
+results as JSON. Use it on the DOM snapshots of failed tasks in data/artifacts
+to check whether the selectors still match.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runParse,
+	}
+	parseCmd.Flags().StringVarP(&parseProfile, "profile", "p", "", fmt.Sprintf("Selector profile (built-in: %s; default: the configured profile)", strings.Join(serp.ProfileNames(), ", ")))
+	parseCmd.Flags().StringVarP(&parseConfig, "config", "c", "", "Configuration file with selector overrides and profiles (empty = built-in profiles only)")
+	return parseCmd
+}
+
+// runParse executes the parse command
+func runParse(cmd *cobra.Command, args []string) error {
+	profile, err := parseSelectorProfile()
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open page: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	page, err := serp.ParsePage(r, profile)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(page)
+}
+
+// parseSelectorProfile returns the selector profile of the parse command
+func parseSelectorProfile() (serp.SelectorProfile, error) {
+	cfg := &config.Config{}
+	if parseConfig != "" {
+		var err error
+		if cfg, err = config.Load(parseConfig); err != nil {
+			return serp.SelectorProfile{}, fmt.Errorf("failed to load config: %w", err)
+		}
+	}
+	if parseProfile != "" {
+		cfg.Selectors.Profile = parseProfile
+	}
+	return cfg.SelectorProfile()
+}
@@ -0,0 +1,231 @@
+// Package alert detects rank changes between consecutive rank checks and
+// delivers them to pluggable notifiers (webhook, email, file).
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/omer/go-bot/internal/stats"
+)
+
+// EventType identifies the kind of rank change
+type EventType string
+
+const (
+	// EventDrop means the position got worse by at least the drop threshold
+	EventDrop EventType = "drop"
+	// EventGain means the position improved by at least the gain threshold
+	EventGain EventType = "gain"
+	// EventEnteredTop means the target moved into the top N (see Event.Threshold)
+	EventEnteredTop EventType = "entered_top"
+	// EventLeftTop means the target moved out of the top N (see Event.Threshold)
+	EventLeftTop EventType = "left_top"
+	// EventLost means a ranked target disappeared from the scanned results
+	EventLost EventType = "lost"
+	// EventFound means a target that was not in the scanned results is ranked again
+	EventFound EventType = "found"
+)
+
+// Event describes one rank change
+type Event struct {
+	Type       EventType `json:"type"`
+	Keyword    string    `json:"keyword"`
+	TargetURL  string    `json:"target_url"`
+	Locale     string    `json:"locale,omitempty"`
+	Competitor bool      `json:"competitor,omitempty"` // TargetURL is a competitor, not our own domain
+	Previous   int       `json:"previous"`             // Previous position (0 = not in top)
+	Current    int       `json:"current"`              // New position (0 = not in top)
+	Delta      int       `json:"delta"`                // Positions gained (negative for a drop)
+	Threshold  int       `json:"threshold,omitempty"`  // N of entered_top and left_top events
+	Time       time.Time `json:"time"`
+}
+
+// Message returns a one-line human readable description of the event
+func (e Event) Message() string {
+	subject := fmt.Sprintf("[%s] %s", e.Keyword, e.TargetURL)
+	if e.Locale != "" {
+		subject += " (" + e.Locale + ")"
+	}
+
+	switch e.Type {
+	case EventDrop:
+		return fmt.Sprintf("%s dropped %d positions: %d -> %d", subject, -e.Delta, e.Previous, e.Current)
+	case EventGain:
+		return fmt.Sprintf("%s gained %d positions: %d -> %d", subject, e.Delta, e.Previous, e.Current)
+	case EventEnteredTop:
+		return fmt.Sprintf("%s entered the top %d at position %d", subject, e.Threshold, e.Current)
+	case EventLeftTop:
+		return fmt.Sprintf("%s left the top %d", subject, e.Threshold)
+	case EventLost:
+		return fmt.Sprintf("%s disappeared from the results (was %d)", subject, e.Previous)
+	case EventFound:
+		return fmt.Sprintf("%s is back in the results at position %d", subject, e.Current)
+	default:
+		return fmt.Sprintf("%s changed: %d -> %d", subject, e.Previous, e.Current)
+	}
+}
+
+// Notifier delivers rank change events
+type Notifier interface {
+	// Name identifies the notifier in error messages
+	Name() string
+
+	// Notify delivers a batch of events
+	Notify(ctx context.Context, events []Event) error
+}
+
+// EngineConfig holds configuration for the rank change engine
+type EngineConfig struct {
+	DropThreshold int        // Positions lost that raise a drop event (default: 3)
+	GainThreshold int        // Positions won that raise a gain event (default: 3)
+	TopThresholds []int      // Top N boundaries that raise entered/left events (default: 3 and 10)
+	Competitors   bool       // Also raise events for competitor domains
+	Notifiers     []Notifier // Where events are delivered
+}
+
+// Engine compares each rank check with the previous one and notifies about changes
+type Engine struct {
+	dropThreshold int
+	gainThreshold int
+	topThresholds []int
+	competitors   bool
+	notifiers     []Notifier
+}
+
+// NewEngine creates a rank change engine
+//
+// Example:
+//
+//	engine := alert.NewEngine(alert.EngineConfig{
+//	    DropThreshold: 5,
+//	    Notifiers:     []alert.Notifier{alert.NewFileNotifier("data/alerts.jsonl")},
+//	})
+func NewEngine(config EngineConfig) *Engine {
+	// Set defaults
+	if config.DropThreshold <= 0 {
+		config.DropThreshold = 3
+	}
+	if config.GainThreshold <= 0 {
+		config.GainThreshold = 3
+	}
+	if len(config.TopThresholds) == 0 {
+		config.TopThresholds = []int{3, 10}
+	}
+
+	return &Engine{
+		dropThreshold: config.DropThreshold,
+		gainThreshold: config.GainThreshold,
+		topThresholds: config.TopThresholds,
+		competitors:   config.Competitors,
+		notifiers:     config.Notifiers,
+	}
+}
+
+// Detect compares a rank check with the keyword stats recorded before it.
+// No events are raised for the first rank of a keyword or for checks that
+// failed before a rank was known.
+func (e *Engine) Detect(previous stats.KeywordStats, current stats.TaskStats) []Event {
+	if previous.LastOutcome == "" {
+		return nil
+	}
+	position, ok := current.Rank()
+	if !ok {
+		return nil
+	}
+
+	base := Event{
+		Keyword:    current.Keyword,
+		TargetURL:  current.TargetURL,
+		Locale:     current.Locale,
+		Competitor: previous.Competitor,
+		Previous:   previous.LastPosition,
+		Current:    position,
+		Delta:      previous.LastPosition - position,
+		Time:       current.Timestamp,
+	}
+	if base.Time.IsZero() {
+		base.Time = time.Now()
+	}
+
+	var events []Event
+	add := func(eventType EventType, threshold int) {
+		event := base
+		event.Type = eventType
+		event.Threshold = threshold
+		events = append(events, event)
+	}
+
+	switch {
+	case base.Previous > 0 && base.Current == 0:
+		add(EventLost, 0)
+	case base.Previous == 0 && base.Current > 0:
+		add(EventFound, 0)
+	case base.Previous > 0 && base.Delta <= -e.dropThreshold:
+		add(EventDrop, 0)
+	case base.Previous > 0 && base.Delta >= e.gainThreshold:
+		add(EventGain, 0)
+	}
+
+	for _, n := range e.topThresholds {
+		wasIn := inTop(base.Previous, n)
+		isIn := inTop(base.Current, n)
+		if !wasIn && isIn {
+			add(EventEnteredTop, n)
+		} else if wasIn && !isIn {
+			add(EventLeftTop, n)
+		}
+	}
+
+	return events
+}
+
+// Record records a task in the collector and notifies about the rank changes
+// it caused. The collector must not be updated concurrently by other callers,
+// or changes may be compared with the wrong previous rank.
+//
+// Example:
+//
+//	events, err := engine.Record(ctx, collector, result.ToStats())
+func (e *Engine) Record(ctx context.Context, collector *stats.StatsCollector, taskStats stats.TaskStats) ([]Event, error) {
+	tasks := []stats.TaskStats{taskStats}
+	if e.competitors {
+		tasks = append(tasks, taskStats.CompetitorTasks()...)
+	}
+
+	previous := make([]stats.KeywordStats, len(tasks))
+	for i, ts := range tasks {
+		previous[i], _ = collector.GetKeywordStatsForLocale(ts.Keyword, ts.TargetURL, ts.Locale)
+	}
+
+	collector.RecordTask(taskStats)
+
+	var events []Event
+	for i, ts := range tasks {
+		events = append(events, e.Detect(previous[i], ts)...)
+	}
+	return events, e.Notify(ctx, events)
+}
+
+// Notify delivers events to every notifier.
+// All notifiers are tried; their errors are joined.
+func (e *Engine) Notify(ctx context.Context, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var errs []error
+	for _, notifier := range e.notifiers {
+		if err := notifier.Notify(ctx, events); err != nil {
+			errs = append(errs, fmt.Errorf("%s notifier: %w", notifier.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// inTop reports whether a position (0 = not ranked) is within the top n
+func inTop(position, n int) bool {
+	return position > 0 && position <= n
+}
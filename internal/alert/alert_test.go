@@ -0,0 +1,264 @@
+package alert
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/omer/go-bot/internal/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ===== Helper functions =====
+
+// previousRank returns keyword stats whose latest check ranked at position (0 = not in top)
+func previousRank(position int) stats.KeywordStats {
+	outcome := stats.OutcomeFound
+	if position == 0 {
+		outcome = stats.OutcomeNotInTop
+	}
+	return stats.KeywordStats{Keyword: "golang", TargetURL: "example.com", LastPosition: position, LastOutcome: outcome}
+}
+
+// rankCheck returns a successful rank check at position (0 = not in top)
+func rankCheck(position int) stats.TaskStats {
+	outcome := stats.OutcomeFound
+	if position == 0 {
+		outcome = stats.OutcomeNotInTop
+	}
+	return stats.TaskStats{Keyword: "golang", TargetURL: "example.com", Success: true, Position: position, Outcome: outcome}
+}
+
+func eventTypes(events []Event) []EventType {
+	var types []EventType
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+// recordingNotifier keeps the events it was sent
+type recordingNotifier struct {
+	events []Event
+	err    error
+}
+
+func (n *recordingNotifier) Name() string { return "recording" }
+
+func (n *recordingNotifier) Notify(_ context.Context, events []Event) error {
+	n.events = append(n.events, events...)
+	return n.err
+}
+
+// ===== Detect tests =====
+
+func TestDetect(t *testing.T) {
+	engine := NewEngine(EngineConfig{DropThreshold: 3, GainThreshold: 5})
+
+	tests := []struct {
+		name     string
+		previous int
+		current  int
+		expected []EventType
+	}{
+		{"unchanged", 4, 4, nil},
+		{"small_drop", 4, 6, nil},
+		{"drop", 4, 7, []EventType{EventDrop}},
+		{"drop_out_of_top_3", 2, 5, []EventType{EventDrop, EventLeftTop}},
+		{"drop_out_of_top_10", 8, 15, []EventType{EventDrop, EventLeftTop}},
+		{"small_gain", 9, 5, nil},
+		{"gain", 16, 11, []EventType{EventGain}},
+		{"gain_into_top_3", 9, 1, []EventType{EventGain, EventEnteredTop}},
+		{"gain_into_top_10", 14, 2, []EventType{EventGain, EventEnteredTop, EventEnteredTop}},
+		{"into_top_10", 11, 10, []EventType{EventEnteredTop}},
+		{"lost", 2, 0, []EventType{EventLost, EventLeftTop, EventLeftTop}},
+		{"found", 0, 12, []EventType{EventFound}},
+		{"still_not_in_top", 0, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := engine.Detect(previousRank(tt.previous), rankCheck(tt.current))
+			assert.Equal(t, tt.expected, eventTypes(events))
+			for _, event := range events {
+				assert.Equal(t, tt.previous, event.Previous)
+				assert.Equal(t, tt.current, event.Current)
+				assert.Equal(t, tt.previous-tt.current, event.Delta)
+				assert.NotEmpty(t, event.Message())
+			}
+		})
+	}
+}
+
+func TestDetect_Thresholds(t *testing.T) {
+	engine := NewEngine(EngineConfig{})
+
+	events := engine.Detect(previousRank(2), rankCheck(11))
+	require.Len(t, events, 3)
+	assert.Equal(t, 3, events[1].Threshold)
+	assert.Equal(t, 10, events[2].Threshold)
+	assert.Equal(t, "[golang] example.com left the top 10", events[2].Message())
+}
+
+func TestDetect_NoBaseline(t *testing.T) {
+	engine := NewEngine(EngineConfig{})
+
+	// First rank of a keyword
+	assert.Empty(t, engine.Detect(stats.KeywordStats{}, rankCheck(1)))
+
+	// Failed check
+	assert.Empty(t, engine.Detect(previousRank(1), stats.TaskStats{Keyword: "golang", TargetURL: "example.com", Error: "timeout"}))
+}
+
+// ===== Record tests =====
+
+func TestEngine_Record(t *testing.T) {
+	notifier := &recordingNotifier{}
+	engine := NewEngine(EngineConfig{Competitors: true, Notifiers: []Notifier{notifier}})
+	collector := stats.NewStatsCollector(filepath.Join(t.TempDir(), "stats.json"))
+
+	first := rankCheck(2)
+	first.Competitors = []stats.CompetitorStats{{TargetURL: "rival.com", Position: 12}}
+	events, err := engine.Record(context.Background(), collector, first)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	second := rankCheck(9)
+	second.Competitors = []stats.CompetitorStats{{TargetURL: "rival.com", Position: 1}}
+	events, err = engine.Record(context.Background(), collector, second)
+	require.NoError(t, err)
+	assert.Equal(t, []EventType{EventDrop, EventLeftTop, EventGain, EventEnteredTop, EventEnteredTop}, eventTypes(events))
+	assert.False(t, events[0].Competitor)
+	assert.True(t, events[2].Competitor)
+	assert.Equal(t, events, notifier.events)
+
+	kwStats, ok := collector.GetKeywordStats("golang", "example.com")
+	require.True(t, ok)
+	assert.Equal(t, 9, kwStats.LastPosition)
+}
+
+func TestEngine_NotifyErrors(t *testing.T) {
+	failing := &recordingNotifier{err: errors.New("boom")}
+	working := &recordingNotifier{}
+	engine := NewEngine(EngineConfig{Notifiers: []Notifier{failing, working}})
+
+	err := engine.Notify(context.Background(), []Event{{Type: EventLost}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "recording notifier: boom")
+	assert.Len(t, working.events, 1, "later notifiers still run")
+}
+
+// ===== Notifier tests =====
+
+func TestWebhookNotifier(t *testing.T) {
+	var payload webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+	}))
+	defer server.Close()
+
+	notifier, err := NewWebhookNotifier(WebhookConfig{
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	})
+	require.NoError(t, err)
+
+	err = notifier.Notify(context.Background(), []Event{{Type: EventDrop, Keyword: "golang", Previous: 2, Current: 9, Delta: -7}})
+	require.NoError(t, err)
+	assert.Equal(t, "serp-bot", payload.Source)
+	require.Len(t, payload.Events, 1)
+	assert.Equal(t, EventDrop, payload.Events[0].Type)
+	assert.Equal(t, -7, payload.Events[0].Delta)
+}
+
+func TestWebhookNotifier_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	notifier, err := NewWebhookNotifier(WebhookConfig{URL: server.URL})
+	require.NoError(t, err)
+
+	err = notifier.Notify(context.Background(), []Event{{Type: EventLost}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status 502")
+
+	_, err = NewWebhookNotifier(WebhookConfig{URL: "ftp://example.com"})
+	assert.Error(t, err)
+}
+
+func TestEmailNotifier(t *testing.T) {
+	notifier, err := NewEmailNotifier(EmailConfig{
+		Host:     "smtp.example.com",
+		Username: "bot",
+		Password: "secret",
+		From:     "bot@example.com",
+		To:       []string{"seo@example.com", "ops@example.com"},
+	})
+	require.NoError(t, err)
+
+	var sent struct {
+		addr string
+		auth smtp.Auth
+		to   []string
+		msg  string
+	}
+	notifier.sendMail = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		sent.addr, sent.auth, sent.to, sent.msg = addr, auth, to, string(msg)
+		return nil
+	}
+
+	err = notifier.Notify(context.Background(), []Event{
+		{Type: EventLost, Keyword: "golang", TargetURL: "example.com", Previous: 4},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "smtp.example.com:587", sent.addr)
+	assert.NotNil(t, sent.auth)
+	assert.Len(t, sent.to, 2)
+	assert.Contains(t, sent.msg, "Subject: [serp-bot] 1 rank change(s)")
+	assert.Contains(t, sent.msg, "[golang] example.com disappeared from the results (was 4)")
+}
+
+func TestNewEmailNotifier_Invalid(t *testing.T) {
+	_, err := NewEmailNotifier(EmailConfig{From: "bot@example.com", To: []string{"seo@example.com"}})
+	assert.Error(t, err)
+	_, err = NewEmailNotifier(EmailConfig{Host: "smtp.example.com", To: []string{"seo@example.com"}})
+	assert.Error(t, err)
+	_, err = NewEmailNotifier(EmailConfig{Host: "smtp.example.com", From: "bot@example.com"})
+	assert.Error(t, err)
+}
+
+func TestFileNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts", "events.jsonl")
+	notifier := NewFileNotifier(path)
+
+	now := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, notifier.Notify(context.Background(), []Event{{Type: EventDrop, Time: now}}))
+	require.NoError(t, notifier.Notify(context.Background(), []Event{{Type: EventGain, Time: now}, {Type: EventFound, Time: now}}))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var types []EventType
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []EventType{EventDrop, EventGain, EventFound}, types)
+}
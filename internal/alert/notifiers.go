@@ -0,0 +1,247 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// webhookPayload is the JSON body posted by WebhookNotifier
+type webhookPayload struct {
+	Source string  `json:"source"`
+	Sent   string  `json:"sent"`
+	Events []Event `json:"events"`
+}
+
+// WebhookConfig holds configuration for a webhook notifier
+type WebhookConfig struct {
+	URL     string            // Endpoint that receives the JSON payload
+	Headers map[string]string // Extra request headers (e.g. authorization)
+	Timeout time.Duration     // Request timeout (default: 10s)
+	Client  *http.Client      // Optional HTTP client
+}
+
+// WebhookNotifier posts events as JSON to an HTTP endpoint:
+// {"source": "serp-bot", "sent": "<RFC 3339>", "events": [...]}
+type WebhookNotifier struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewWebhookNotifier creates a notifier that posts events to a webhook
+//
+// Example:
+//
+//	notifier, err := alert.NewWebhookNotifier(alert.WebhookConfig{
+//	    URL: "https://hooks.example.com/serp",
+//	})
+func NewWebhookNotifier(config WebhookConfig) (*WebhookNotifier, error) {
+	if !strings.HasPrefix(config.URL, "http://") && !strings.HasPrefix(config.URL, "https://") {
+		return nil, fmt.Errorf("webhook URL must start with http:// or https://, got %q", config.URL)
+	}
+
+	// Set defaults
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: config.Timeout}
+	}
+
+	return &WebhookNotifier{
+		url:     config.URL,
+		headers: config.Headers,
+		client:  config.Client,
+	}, nil
+}
+
+// Name returns "webhook"
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Notify posts the events in one request
+func (n *WebhookNotifier) Notify(ctx context.Context, events []Event) error {
+	body, err := json.Marshal(webhookPayload{
+		Source: "serp-bot",
+		Sent:   time.Now().UTC().Format(time.RFC3339),
+		Events: events,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range n.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
+
+// EmailConfig holds configuration for an SMTP email notifier
+type EmailConfig struct {
+	Host     string   // SMTP server host
+	Port     int      // SMTP server port (default: 587)
+	Username string   // SMTP username (optional; enables PLAIN auth)
+	Password string   // SMTP password
+	From     string   // Sender address
+	To       []string // Recipient addresses
+}
+
+// sendMailFunc matches smtp.SendMail
+type sendMailFunc func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+
+// EmailNotifier sends one plain text email per batch of events over SMTP
+type EmailNotifier struct {
+	addr     string
+	auth     smtp.Auth
+	from     string
+	to       []string
+	sendMail sendMailFunc
+}
+
+// NewEmailNotifier creates a notifier that emails events
+//
+// Example:
+//
+//	notifier, err := alert.NewEmailNotifier(alert.EmailConfig{
+//	    Host: "smtp.example.com",
+//	    From: "serp-bot@example.com",
+//	    To:   []string{"seo@example.com"},
+//	})
+func NewEmailNotifier(config EmailConfig) (*EmailNotifier, error) {
+	if config.Host == "" {
+		return nil, fmt.Errorf("SMTP host cannot be empty")
+	}
+	if config.From == "" {
+		return nil, fmt.Errorf("sender address cannot be empty")
+	}
+	if len(config.To) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+
+	// Set defaults
+	if config.Port == 0 {
+		config.Port = 587
+	}
+
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+
+	return &EmailNotifier{
+		addr:     net.JoinHostPort(config.Host, strconv.Itoa(config.Port)),
+		auth:     auth,
+		from:     config.From,
+		to:       config.To,
+		sendMail: smtp.SendMail,
+	}, nil
+}
+
+// Name returns "email"
+func (n *EmailNotifier) Name() string {
+	return "email"
+}
+
+// Notify sends the events in one email.
+// net/smtp does not take a context; ctx is only checked before sending.
+func (n *EmailNotifier) Notify(ctx context.Context, events []Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := n.sendMail(n.addr, n.auth, n.from, n.to, n.message(events)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// message renders the email headers and body
+func (n *EmailNotifier) message(events []Event) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&b, "Subject: [serp-bot] %d rank change(s)\r\n", len(events))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	for _, event := range events {
+		fmt.Fprintf(&b, "- %s\r\n", event.Message())
+	}
+	return []byte(b.String())
+}
+
+// FileNotifier appends events to a file as JSON lines.
+// It is meant for testing and for feeding other local tools.
+type FileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileNotifier creates a notifier that appends events to path
+//
+// Example:
+//
+//	notifier := alert.NewFileNotifier("data/alerts.jsonl")
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+// Name returns "file"
+func (n *FileNotifier) Name() string {
+	return "file"
+}
+
+// Notify appends one JSON line per event
+func (n *FileNotifier) Notify(ctx context.Context, events []Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(n.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	f, err := os.OpenFile(n.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open alert file: %w", err)
+	}
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("failed to write event: %w", err)
+		}
+	}
+	return nil
+}
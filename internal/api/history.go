@@ -0,0 +1,172 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/omer/go-bot/internal/stats"
+)
+
+// Pagination limits
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+// Page is a paginated list response
+type Page[T any] struct {
+	Total  int `json:"total"`  // Items matching the filters
+	Offset int `json:"offset"` // Index of the first returned item
+	Limit  int `json:"limit"`  // Maximum number of returned items
+	Items  []T `json:"items"`
+}
+
+// paginate returns the requested page of items
+func paginate[T any](items []T, offset, limit int) Page[T] {
+	page := Page[T]{Total: len(items), Offset: offset, Limit: limit, Items: []T{}}
+	if offset < len(items) {
+		end := min(offset+limit, len(items))
+		page.Items = items[offset:end]
+	}
+	return page
+}
+
+// parsePage reads the offset and limit query parameters
+func parsePage(query url.Values) (offset, limit int, err error) {
+	limit = defaultPageLimit
+	if v := query.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return 0, 0, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+		}
+	}
+	if v := query.Get("offset"); v != "" {
+		offset, err = strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("offset must be a non-negative integer")
+		}
+	}
+	return offset, limit, nil
+}
+
+// historyFilter selects tasks from the history
+type historyFilter struct {
+	keyword string
+	target  string
+	locale  string
+	success *bool
+	since   time.Time
+}
+
+// parseHistoryFilter reads the filter query parameters of /api/v1/history
+func parseHistoryFilter(query url.Values) (historyFilter, error) {
+	filter := historyFilter{
+		keyword: query.Get("keyword"),
+		target:  query.Get("target"),
+		locale:  query.Get("locale"),
+	}
+	if v := query.Get("success"); v != "" {
+		success, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, fmt.Errorf("success must be true or false")
+		}
+		filter.success = &success
+	}
+	if v := query.Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("since must be an RFC 3339 time")
+		}
+		filter.since = since
+	}
+	return filter, nil
+}
+
+// match reports whether a task passes the filter
+func (f historyFilter) match(ts stats.TaskStats) bool {
+	switch {
+	case f.keyword != "" && !strings.EqualFold(ts.Keyword, f.keyword):
+		return false
+	case f.target != "" && ts.TargetURL != f.target:
+		return false
+	case f.locale != "" && ts.Locale != f.locale:
+		return false
+	case f.success != nil && ts.Success != *f.success:
+		return false
+	case !f.since.IsZero() && ts.Timestamp.Before(f.since):
+		return false
+	}
+	return true
+}
+
+// handleHistory lists the recent rank checks, newest first.
+// Query parameters: keyword, target, locale, success, since, offset, limit.
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if s.config.Stats == nil {
+		writeError(w, http.StatusNotFound, "stats are disabled")
+		return
+	}
+
+	query := r.URL.Query()
+	offset, limit, err := parsePage(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter, err := parseHistoryFilter(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	history := s.config.Stats.GetStats().TaskHistory
+	tasks := make([]stats.TaskStats, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		if filter.match(history[i]) {
+			tasks = append(tasks, history[i])
+		}
+	}
+
+	writeJSON(w, http.StatusOK, paginate(tasks, offset, limit))
+}
+
+// handleKeywords lists the per keyword statistics, sorted by keyword, target and locale.
+// Query parameters: keyword, target, offset, limit.
+func (s *Server) handleKeywords(w http.ResponseWriter, r *http.Request) {
+	if s.config.Stats == nil {
+		writeError(w, http.StatusNotFound, "stats are disabled")
+		return
+	}
+
+	query := r.URL.Query()
+	offset, limit, err := parsePage(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter := historyFilter{keyword: query.Get("keyword"), target: query.Get("target")}
+
+	var keywords []stats.KeywordStats
+	for _, kwStats := range s.config.Stats.GetStats().KeywordStats {
+		if filter.match(stats.TaskStats{Keyword: kwStats.Keyword, TargetURL: kwStats.TargetURL}) {
+			keywords = append(keywords, kwStats)
+		}
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		a, b := keywords[i], keywords[j]
+		if a.Keyword != b.Keyword {
+			return a.Keyword < b.Keyword
+		}
+		if a.TargetURL != b.TargetURL {
+			return a.TargetURL < b.TargetURL
+		}
+		return a.Locale < b.Locale
+	})
+
+	writeJSON(w, http.StatusOK, paginate(keywords, offset, limit))
+}
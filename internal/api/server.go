@@ -0,0 +1,240 @@
+// Package api serves a read-only HTTP API with the status of the running bot:
+// worker pool, scheduler, proxy and health statistics, the recent rank
+// history from the stats collector, Prometheus metrics, and liveness/readiness
+// probes.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/omer/go-bot/internal/health"
+	"github.com/omer/go-bot/internal/logger"
+	"github.com/omer/go-bot/internal/metrics"
+	"github.com/omer/go-bot/internal/proxy"
+	"github.com/omer/go-bot/internal/stats"
+	"github.com/omer/go-bot/internal/task"
+)
+
+// Config holds configuration for the API server.
+// Every component is optional; endpoints of missing components return 404.
+type Config struct {
+	Addr          string                // Listen address (e.g. ":8080" or "127.0.0.1:8080")
+	WorkerPool    *task.WorkerPool      // Served at /api/v1/workers
+	Scheduler     *task.Scheduler       // Served at /api/v1/scheduler
+	ProxyPool     *proxy.ProxyPool      // Served at /api/v1/proxies
+	Health        *health.HealthChecker // Served at /api/v1/health
+	Stats         *stats.StatsCollector // Served at /api/v1/summary, /api/v1/keywords and /api/v1/history
+	Metrics       *metrics.Metrics      // Served at /metrics in the Prometheus text format
+	Logger        *logger.Logger        // Logger instance
+	HealthTimeout time.Duration         // Timeout of /api/v1/health (default: 10s)
+}
+
+// Server is the read-only HTTP API
+type Server struct {
+	config   Config
+	logger   *logger.Logger
+	server   *http.Server
+	listener net.Listener
+	mu       sync.RWMutex
+	ready    bool
+}
+
+// NewServer creates an API server
+//
+// Example:
+//
+//	server := api.NewServer(api.Config{
+//	    Addr:       ":8080",
+//	    WorkerPool: pool,
+//	    Stats:      collector,
+//	    Logger:     log,
+//	})
+//	if err := server.Start(); err != nil {
+//	    return err
+//	}
+//	defer server.Stop(context.Background())
+func NewServer(config Config) *Server {
+	// Set defaults
+	if config.HealthTimeout == 0 {
+		config.HealthTimeout = 10 * time.Second
+	}
+	if config.Logger == nil {
+		config.Logger = logger.NewDefault()
+	}
+
+	s := &Server{
+		config: config,
+		logger: config.Logger,
+	}
+	s.server = &http.Server{
+		Addr:              config.Addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+// Handler returns the HTTP handler with all routes.
+// Only GET (and HEAD) requests are accepted.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealthz)
+	mux.HandleFunc("GET /readyz", s.handleReadyz)
+	mux.HandleFunc("GET /api/v1/workers", s.handleWorkers)
+	mux.HandleFunc("GET /api/v1/scheduler", s.handleScheduler)
+	mux.HandleFunc("GET /api/v1/proxies", s.handleProxies)
+	mux.HandleFunc("GET /api/v1/health", s.handleHealth)
+	mux.HandleFunc("GET /api/v1/summary", s.handleSummary)
+	mux.HandleFunc("GET /api/v1/keywords", s.handleKeywords)
+	mux.HandleFunc("GET /api/v1/history", s.handleHistory)
+	if s.config.Metrics != nil {
+		mux.Handle("GET /metrics", s.config.Metrics.Handler())
+	}
+	return mux
+}
+
+// Start listens on the configured address and serves requests in the background
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.config.Addr, err)
+	}
+	s.listener = listener
+
+	s.logger.Info("API server listening", map[string]interface{}{
+		"addr": listener.Addr().String(),
+	})
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("API server failed", map[string]interface{}{
+				"error": err,
+			})
+		}
+	}()
+
+	return nil
+}
+
+// Addr returns the address the server listens on, or "" before Start
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// Stop marks the server as not ready and shuts it down gracefully
+func (s *Server) Stop(ctx context.Context) error {
+	s.SetReady(false)
+	if err := s.server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to stop API server: %w", err)
+	}
+	return nil
+}
+
+// SetReady sets whether /readyz reports the service as ready
+func (s *Server) SetReady(ready bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ready = ready
+}
+
+// IsReady returns whether the service is ready.
+// It is ready after SetReady(true) while the worker pool, if any, is running.
+func (s *Server) IsReady() bool {
+	s.mu.RLock()
+	ready := s.ready
+	s.mu.RUnlock()
+
+	if s.config.WorkerPool != nil && !s.config.WorkerPool.IsRunning() {
+		return false
+	}
+	return ready
+}
+
+// handleHealthz is the liveness probe: the process is up and serving
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleReadyz is the readiness probe
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if !s.IsReady() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "not ready"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+func (s *Server) handleWorkers(w http.ResponseWriter, r *http.Request) {
+	if s.config.WorkerPool == nil {
+		writeError(w, http.StatusNotFound, "worker pool is not running")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.config.WorkerPool.Stats())
+}
+
+func (s *Server) handleScheduler(w http.ResponseWriter, r *http.Request) {
+	if s.config.Scheduler == nil {
+		writeError(w, http.StatusNotFound, "scheduler is not running")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.config.Scheduler.Stats())
+}
+
+func (s *Server) handleProxies(w http.ResponseWriter, r *http.Request) {
+	if s.config.ProxyPool == nil {
+		writeError(w, http.StatusNotFound, "no proxies configured")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.config.ProxyPool.GetStats())
+}
+
+// handleHealth runs all health checks; it returns 503 if any check failed
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if s.config.Health == nil {
+		writeError(w, http.StatusNotFound, "health checks are not configured")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.HealthTimeout)
+	defer cancel()
+
+	results := s.config.Health.CheckAll(ctx)
+	status := http.StatusOK
+	if !health.AllPassed(results) {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, map[string]interface{}{
+		"healthy": status == http.StatusOK,
+		"checks":  results,
+	})
+}
+
+func (s *Server) handleSummary(w http.ResponseWriter, r *http.Request) {
+	if s.config.Stats == nil {
+		writeError(w, http.StatusNotFound, "stats are disabled")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.config.Stats.GetSummary())
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,262 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/omer/go-bot/internal/config"
+	"github.com/omer/go-bot/internal/health"
+	"github.com/omer/go-bot/internal/logger"
+	"github.com/omer/go-bot/internal/metrics"
+	"github.com/omer/go-bot/internal/proxy"
+	"github.com/omer/go-bot/internal/stats"
+	"github.com/omer/go-bot/internal/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ===== Helper functions =====
+
+// get performs a GET request against the handler and decodes the JSON body into v
+func get(t *testing.T, handler http.Handler, target string, v interface{}) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	if v != nil {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), v), rec.Body.String())
+	}
+	return rec.Code
+}
+
+// newTestCollector returns a collector with 12 rank checks:
+// golang/example.com at positions 1-10 and two failed rust/other.io checks
+func newTestCollector(t *testing.T) *stats.StatsCollector {
+	collector := stats.NewStatsCollector(filepath.Join(t.TempDir(), "stats.json"))
+	start := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	for i := 1; i <= 10; i++ {
+		collector.RecordTask(stats.TaskStats{
+			Keyword:   "golang",
+			TargetURL: "example.com",
+			Success:   true,
+			Position:  i,
+			Outcome:   stats.OutcomeFound,
+			Timestamp: start.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	for i := 0; i < 2; i++ {
+		collector.RecordTask(stats.TaskStats{
+			Keyword:   "rust",
+			TargetURL: "other.io",
+			Error:     "timeout",
+			Timestamp: start.Add(time.Duration(20+i) * time.Hour),
+		})
+	}
+	return collector
+}
+
+// ===== Probe tests =====
+
+func TestProbes(t *testing.T) {
+	pool := task.NewWorkerPool(task.WorkerPoolConfig{
+		Workers: 1,
+		Logger:  logger.NewDefault(),
+		Executor: func(_ context.Context, tk *task.Task) *task.TaskResult {
+			return &task.TaskResult{Task: tk, Success: true}
+		},
+	})
+	server := NewServer(Config{WorkerPool: pool})
+	handler := server.Handler()
+
+	var body map[string]string
+	assert.Equal(t, http.StatusOK, get(t, handler, "/healthz", &body))
+	assert.Equal(t, "ok", body["status"])
+
+	assert.Equal(t, http.StatusServiceUnavailable, get(t, handler, "/readyz", &body))
+
+	server.SetReady(true)
+	assert.Equal(t, http.StatusServiceUnavailable, get(t, handler, "/readyz", nil), "the worker pool is not running")
+
+	require.NoError(t, pool.Start())
+	assert.Equal(t, http.StatusOK, get(t, handler, "/readyz", &body))
+	assert.Equal(t, "ready", body["status"])
+
+	require.NoError(t, pool.Stop())
+	assert.Equal(t, http.StatusServiceUnavailable, get(t, handler, "/readyz", nil))
+}
+
+func TestReadOnly(t *testing.T) {
+	handler := NewServer(Config{}).Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/history", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+// ===== Component endpoint tests =====
+
+func TestComponentEndpoints(t *testing.T) {
+	pool := task.NewWorkerPool(task.WorkerPoolConfig{Workers: 3, QueueSize: 6, Logger: logger.NewDefault()})
+	proxyPool, err := proxy.NewProxyPool([]string{"http://proxy1:8080", "http://proxy2:8080"}, proxy.RotationStrategyRoundRobin)
+	require.NoError(t, err)
+	scheduler := task.NewScheduler(task.SchedulerConfig{Config: &config.Config{}, WorkerPool: pool, Logger: logger.NewDefault()})
+
+	handler := NewServer(Config{WorkerPool: pool, ProxyPool: proxyPool, Scheduler: scheduler}).Handler()
+
+	var workers map[string]interface{}
+	assert.Equal(t, http.StatusOK, get(t, handler, "/api/v1/workers", &workers))
+	assert.Equal(t, 3.0, workers["workers"])
+	assert.Equal(t, false, workers["running"])
+
+	var proxies map[string]interface{}
+	assert.Equal(t, http.StatusOK, get(t, handler, "/api/v1/proxies", &proxies))
+	assert.Equal(t, 2.0, proxies["total_proxies"])
+
+	var scheduled map[string]interface{}
+	assert.Equal(t, http.StatusOK, get(t, handler, "/api/v1/scheduler", &scheduled))
+	assert.Equal(t, 0.0, scheduled["cycles_run"])
+}
+
+func TestComponentEndpoints_NotConfigured(t *testing.T) {
+	handler := NewServer(Config{}).Handler()
+
+	for _, path := range []string{"/api/v1/workers", "/api/v1/scheduler", "/api/v1/proxies", "/api/v1/health", "/api/v1/summary", "/api/v1/keywords", "/api/v1/history"} {
+		var body map[string]string
+		assert.Equal(t, http.StatusNotFound, get(t, handler, path, &body), path)
+		assert.NotEmpty(t, body["error"], path)
+	}
+}
+
+func TestHealthEndpoint(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping health check test in short mode")
+	}
+
+	checker := health.NewHealthChecker(&config.Config{}, logger.NewDefault())
+	handler := NewServer(Config{Health: checker}).Handler()
+
+	var body struct {
+		Healthy bool                 `json:"healthy"`
+		Checks  []health.CheckResult `json:"checks"`
+	}
+	code := get(t, handler, "/api/v1/health", &body)
+	assert.Contains(t, []int{http.StatusOK, http.StatusServiceUnavailable}, code)
+	assert.Equal(t, code == http.StatusOK, body.Healthy)
+	assert.NotEmpty(t, body.Checks)
+}
+
+// ===== Stats endpoint tests =====
+
+func TestHistory_Pagination(t *testing.T) {
+	handler := NewServer(Config{Stats: newTestCollector(t)}).Handler()
+
+	var page Page[stats.TaskStats]
+	require.Equal(t, http.StatusOK, get(t, handler, "/api/v1/history?limit=5", &page))
+	assert.Equal(t, 12, page.Total)
+	assert.Equal(t, 5, page.Limit)
+	require.Len(t, page.Items, 5)
+	assert.Equal(t, "rust", page.Items[0].Keyword, "newest first")
+
+	require.Equal(t, http.StatusOK, get(t, handler, "/api/v1/history?offset=10&limit=5", &page))
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, 1, page.Items[1].Position, "the oldest check is last")
+
+	require.Equal(t, http.StatusOK, get(t, handler, "/api/v1/history?offset=50", &page))
+	assert.Empty(t, page.Items)
+	assert.Equal(t, 12, page.Total)
+}
+
+func TestHistory_Filters(t *testing.T) {
+	handler := NewServer(Config{Stats: newTestCollector(t)}).Handler()
+
+	tests := []struct {
+		query string
+		total int
+	}{
+		{"keyword=GOLANG", 10},
+		{"target=other.io", 2},
+		{"success=false", 2},
+		{"keyword=golang&since=2024-03-04T08:00:00Z", 3},
+		{"locale=hl%3Dtr", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var page Page[stats.TaskStats]
+			require.Equal(t, http.StatusOK, get(t, handler, "/api/v1/history?"+tt.query, &page))
+			assert.Equal(t, tt.total, page.Total)
+		})
+	}
+}
+
+func TestHistory_BadRequest(t *testing.T) {
+	handler := NewServer(Config{Stats: newTestCollector(t)}).Handler()
+
+	for _, query := range []string{"limit=0", fmt.Sprintf("limit=%d", maxPageLimit+1), "offset=-1", "limit=ten", "success=maybe", "since=yesterday"} {
+		var body map[string]string
+		assert.Equal(t, http.StatusBadRequest, get(t, handler, "/api/v1/history?"+query, &body), query)
+		assert.NotEmpty(t, body["error"], query)
+	}
+}
+
+func TestKeywordsAndSummary(t *testing.T) {
+	handler := NewServer(Config{Stats: newTestCollector(t)}).Handler()
+
+	var page Page[stats.KeywordStats]
+	require.Equal(t, http.StatusOK, get(t, handler, "/api/v1/keywords", &page))
+	require.Equal(t, 2, page.Total)
+	assert.Equal(t, "golang", page.Items[0].Keyword)
+	assert.Equal(t, 10, page.Items[0].LastPosition)
+	assert.Equal(t, 2, page.Items[1].FailureCount)
+
+	require.Equal(t, http.StatusOK, get(t, handler, "/api/v1/keywords?target=other.io", &page))
+	assert.Equal(t, 1, page.Total)
+
+	var summary map[string]interface{}
+	require.Equal(t, http.StatusOK, get(t, handler, "/api/v1/summary", &summary))
+	assert.Equal(t, 12.0, summary["total_tasks"])
+}
+
+// ===== Server lifecycle tests =====
+
+func TestServer_StartStop(t *testing.T) {
+	server := NewServer(Config{Addr: "127.0.0.1:0"})
+	assert.Empty(t, server.Addr())
+	require.NoError(t, server.Start())
+
+	resp, err := http.Get("http://" + server.Addr() + "/healthz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	server.SetReady(true)
+	assert.True(t, server.IsReady())
+
+	require.NoError(t, server.Stop(context.Background()))
+	assert.False(t, server.IsReady())
+
+	_, err = http.Get("http://" + server.Addr() + "/healthz")
+	assert.Error(t, err)
+}
+
+// ===== Metrics endpoint tests =====
+
+func TestMetricsEndpoint(t *testing.T) {
+	appMetrics := metrics.New()
+	appMetrics.ObserveTask("rank_check", nil, time.Second)
+	handler := NewServer(Config{Metrics: appMetrics}).Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `serp_bot_tasks_total{error_type="",status="success",type="rank_check"} 1`)
+
+	rec = httptest.NewRecorder()
+	NewServer(Config{}).Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
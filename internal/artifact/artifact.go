@@ -0,0 +1,240 @@
+// Package artifact saves debug artifacts of failed tasks: a full-page
+// screenshot, the DOM snapshot, the console log and the final URL of the
+// page a task failed on. Each task gets its own directory, and the DOM
+// snapshot is laid out as a serp fixture so the directory doubles as a
+// parser regression fixture.
+package artifact
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/omer/go-bot/internal/serp"
+)
+
+// File names inside a task directory
+const (
+	MetaFile       = "meta.json"
+	ScreenshotFile = "screenshot.jpg"
+	ConsoleFile    = "console.log"
+)
+
+// Artifacts is what was captured from the page a task failed on
+type Artifacts struct {
+	TaskID     string      `json:"task_id"`
+	Keyword    string      `json:"keyword"`
+	Locale     serp.Locale `json:"locale"`
+	Page       int         `json:"page"` // Result page number (default: 1)
+	URL        string      `json:"url"`  // Final URL
+	Title      string      `json:"title"`
+	Error      string      `json:"error"`
+	ErrorType  string      `json:"error_type"`
+	CapturedAt time.Time   `json:"captured_at"`
+	HTMLFile   string      `json:"html_file,omitempty"` // DOM snapshot, relative to the task directory
+
+	Screenshot []byte   `json:"-"` // Full-page JPEG screenshot
+	HTML       string   `json:"-"` // Outer HTML of the document
+	Console    []string `json:"-"` // Console messages and browser log entries
+}
+
+// Store keeps the artifacts of failed tasks below a directory and enforces
+// the retention limits after every save
+type Store struct {
+	dir      string
+	maxTasks int
+	maxAge   time.Duration
+	mu       sync.Mutex
+	now      func() time.Time
+}
+
+// StoreConfig holds configuration for an artifact store
+type StoreConfig struct {
+	Dir      string        // Directory with one subdirectory per task (default: data/artifacts)
+	MaxTasks int           // Task directories to keep; the oldest are removed first (default: 100)
+	MaxAge   time.Duration // Age after which task directories are removed (0 = no age limit)
+}
+
+// NewStore creates an artifact store
+//
+// Example:
+//
+//	store := artifact.NewStore(artifact.StoreConfig{
+//	    Dir:      "data/artifacts",
+//	    MaxTasks: 50,
+//	    MaxAge:   14 * 24 * time.Hour,
+//	})
+func NewStore(config StoreConfig) *Store {
+	// Set defaults
+	if config.Dir == "" {
+		config.Dir = "data/artifacts"
+	}
+	if config.MaxTasks <= 0 {
+		config.MaxTasks = 100
+	}
+	if config.MaxAge < 0 {
+		config.MaxAge = 0
+	}
+
+	return &Store{
+		dir:      config.Dir,
+		maxTasks: config.MaxTasks,
+		maxAge:   config.MaxAge,
+		now:      time.Now,
+	}
+}
+
+// Dir returns the directory of the store
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// TaskDir returns the directory holding the artifacts of a task
+func (s *Store) TaskDir(taskID string) string {
+	return filepath.Join(s.dir, taskID)
+}
+
+// Save writes the artifacts of a task, replacing earlier ones of the same
+// task, and returns the task directory:
+//
+//	<dir>/<task id>/meta.json                         URL, title, error and search context
+//	<dir>/<task id>/screenshot.jpg                    full-page screenshot
+//	<dir>/<task id>/console.log                       console messages
+//	<dir>/<task id>/[<locale>/]<keyword>/page-N.html  DOM snapshot
+//
+// The task directory can be replayed with
+// serp.NewFixtureProvider(dir).WithLocale(locale).
+func (s *Store) Save(a *Artifacts) (string, error) {
+	if a.TaskID == "" {
+		return "", fmt.Errorf("task ID cannot be empty")
+	}
+	if a.Page < 1 {
+		a.Page = 1
+	}
+	if a.CapturedAt.IsZero() {
+		a.CapturedAt = s.now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir := s.TaskDir(a.TaskID)
+	if err := os.RemoveAll(dir); err != nil {
+		return "", fmt.Errorf("failed to clear artifacts: %w", err)
+	}
+	if err := s.write(dir, a); err != nil {
+		return "", err
+	}
+
+	if err := s.prune(); err != nil {
+		return dir, err
+	}
+	return dir, nil
+}
+
+// write writes the artifact files into dir
+func (s *Store) write(dir string, a *Artifacts) error {
+	files := map[string][]byte{}
+	if len(a.Screenshot) > 0 {
+		files[ScreenshotFile] = a.Screenshot
+	}
+	if len(a.Console) > 0 {
+		files[ConsoleFile] = []byte(strings.Join(a.Console, "\n") + "\n")
+	}
+	if a.HTML != "" && a.Keyword != "" {
+		path := serp.FixtureFile(dir, a.Locale, a.Keyword, a.Page)
+		a.HTMLFile, _ = filepath.Rel(dir, path)
+		files[a.HTMLFile] = []byte(a.HTML)
+	}
+
+	meta, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal artifact metadata: %w", err)
+	}
+	files[MetaFile] = meta
+
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Load reads the metadata of the artifacts saved for a task
+func (s *Store) Load(taskID string) (*Artifacts, error) {
+	data, err := os.ReadFile(filepath.Join(s.TaskDir(taskID), MetaFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifacts of %s: %w", taskID, err)
+	}
+
+	var a Artifacts
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, fmt.Errorf("failed to decode artifacts of %s: %w", taskID, err)
+	}
+	return &a, nil
+}
+
+// Prune removes task directories older than the maximum age and the oldest
+// ones beyond the maximum number of tasks
+func (s *Store) Prune() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.prune()
+}
+
+// prune implements Prune; the caller holds the lock
+func (s *Store) prune() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to list artifacts: %w", err)
+	}
+
+	type taskDir struct {
+		name    string
+		modTime time.Time
+	}
+	var dirs []taskDir
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		dirs = append(dirs, taskDir{name: entry.Name(), modTime: info.ModTime()})
+	}
+
+	// Newest first
+	sort.Slice(dirs, func(i, j int) bool {
+		return dirs[i].modTime.After(dirs[j].modTime)
+	})
+
+	cutoff := time.Time{}
+	if s.maxAge > 0 {
+		cutoff = s.now().Add(-s.maxAge)
+	}
+	for i, dir := range dirs {
+		if i < s.maxTasks && !dir.modTime.Before(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(s.dir, dir.name)); err != nil {
+			return fmt.Errorf("failed to remove artifacts of %s: %w", dir.name, err)
+		}
+	}
+	return nil
+}
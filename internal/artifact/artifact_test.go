@@ -0,0 +1,176 @@
+package artifact
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/omer/go-bot/internal/serp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const serpHTML = `<html><body>
+	<div class="g"><a href="https://golang.org/"><h3>The Go Programming Language</h3></a></div>
+	<div class="g"><a href="https://example.com/tutorial"><h3>Go Tutorial</h3></a></div>
+</body></html>`
+
+// ===== Helper functions =====
+
+func newTestStore(t *testing.T, config StoreConfig) *Store {
+	t.Helper()
+	config.Dir = t.TempDir()
+	return NewStore(config)
+}
+
+// age sets the modification time of a task directory
+func age(t *testing.T, store *Store, taskID string, d time.Duration) {
+	t.Helper()
+	past := time.Now().Add(-d)
+	require.NoError(t, os.Chtimes(store.TaskDir(taskID), past, past))
+}
+
+// ===== Store tests =====
+
+func TestNewStore_Defaults(t *testing.T) {
+	store := NewStore(StoreConfig{})
+
+	assert.Equal(t, "data/artifacts", store.Dir())
+	assert.Equal(t, 100, store.maxTasks)
+	assert.Zero(t, store.maxAge)
+}
+
+func TestStore_Save(t *testing.T) {
+	store := newTestStore(t, StoreConfig{})
+	locale := serp.Locale{HL: "tr", GL: "TR"}
+
+	dir, err := store.Save(&Artifacts{
+		TaskID:     "task-1",
+		Keyword:    "golang tutorial",
+		Locale:     locale,
+		Page:       2,
+		URL:        "https://www.google.com/search?q=golang+tutorial&start=10",
+		Title:      "golang tutorial - Google Search",
+		Error:      "no results found",
+		ErrorType:  "selector",
+		Screenshot: []byte{0xff, 0xd8, 0xff},
+		HTML:       serpHTML,
+		Console:    []string{"console.error: boom", "network error: 404"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, store.TaskDir("task-1"), dir)
+
+	screenshot, err := os.ReadFile(filepath.Join(dir, ScreenshotFile))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xff, 0xd8, 0xff}, screenshot)
+
+	console, err := os.ReadFile(filepath.Join(dir, ConsoleFile))
+	require.NoError(t, err)
+	assert.Equal(t, "console.error: boom\nnetwork error: 404\n", string(console))
+
+	meta, err := store.Load("task-1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://www.google.com/search?q=golang+tutorial&start=10", meta.URL)
+	assert.Equal(t, "selector", meta.ErrorType)
+	assert.Equal(t, 2, meta.Page)
+	assert.Equal(t, filepath.Join("hl-tr-gl-tr", "golang-tutorial", "page-2.html"), meta.HTMLFile)
+	assert.False(t, meta.CapturedAt.IsZero())
+
+	// The DOM snapshot replays as a fixture
+	provider := serp.NewFixtureProvider(dir).WithLocale(locale)
+	results, err := provider.Search(context.Background(), "golang tutorial", 2)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "https://golang.org/", results[0].URL)
+}
+
+func TestStore_Save_Partial(t *testing.T) {
+	store := newTestStore(t, StoreConfig{})
+
+	// The browser was gone; only the URL could be read
+	dir, err := store.Save(&Artifacts{TaskID: "task-1", Keyword: "golang", URL: "https://www.google.com/sorry"})
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, MetaFile, entries[0].Name())
+
+	meta, err := store.Load("task-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, meta.Page)
+	assert.Empty(t, meta.HTMLFile)
+}
+
+func TestStore_Save_ReplacesPrevious(t *testing.T) {
+	store := newTestStore(t, StoreConfig{})
+
+	_, err := store.Save(&Artifacts{TaskID: "task-1", Keyword: "golang", Screenshot: []byte{1}})
+	require.NoError(t, err)
+	dir, err := store.Save(&Artifacts{TaskID: "task-1", Keyword: "golang", URL: "https://example.com/"})
+	require.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join(dir, ScreenshotFile))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestStore_Save_EmptyTaskID(t *testing.T) {
+	store := newTestStore(t, StoreConfig{})
+
+	_, err := store.Save(&Artifacts{Keyword: "golang"})
+	assert.Error(t, err)
+}
+
+func TestStore_Load_NotFound(t *testing.T) {
+	store := newTestStore(t, StoreConfig{})
+
+	_, err := store.Load("missing")
+	assert.Error(t, err)
+}
+
+// ===== Retention tests =====
+
+func TestStore_MaxTasks(t *testing.T) {
+	store := newTestStore(t, StoreConfig{MaxTasks: 2})
+
+	for i, id := range []string{"task-1", "task-2"} {
+		_, err := store.Save(&Artifacts{TaskID: id})
+		require.NoError(t, err)
+		age(t, store, id, time.Duration(2-i)*time.Hour)
+	}
+	_, err := store.Save(&Artifacts{TaskID: "task-3"})
+	require.NoError(t, err)
+
+	_, err = store.Load("task-1")
+	assert.Error(t, err, "oldest task should be pruned")
+	for _, id := range []string{"task-2", "task-3"} {
+		_, err := store.Load(id)
+		assert.NoError(t, err, id)
+	}
+}
+
+func TestStore_MaxAge(t *testing.T) {
+	store := newTestStore(t, StoreConfig{MaxAge: 24 * time.Hour})
+
+	_, err := store.Save(&Artifacts{TaskID: "old"})
+	require.NoError(t, err)
+	age(t, store, "old", 48*time.Hour)
+	_, err = store.Save(&Artifacts{TaskID: "recent"})
+	require.NoError(t, err)
+	age(t, store, "recent", time.Hour)
+
+	require.NoError(t, store.Prune())
+
+	_, err = store.Load("old")
+	assert.Error(t, err)
+	_, err = store.Load("recent")
+	assert.NoError(t, err)
+}
+
+func TestStore_Prune_MissingDir(t *testing.T) {
+	store := NewStore(StoreConfig{Dir: filepath.Join(t.TempDir(), "missing")})
+
+	assert.NoError(t, store.Prune())
+}
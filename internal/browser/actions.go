@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/chromedp/chromedp"
+	apperrors "github.com/omer/go-bot/internal/errors"
 	"github.com/omer/go-bot/pkg/utils"
 )
 
@@ -16,10 +17,10 @@ import (
 //	err := browser.Type("input[name='q']", "golang tutorial")
 func (b *Browser) Type(selector, text string) error {
 	if selector == "" {
-		return fmt.Errorf("selector cannot be empty")
+		return apperrors.NewValidationError("selector cannot be empty")
 	}
 
-	return chromedp.Run(b.ctx,
+	return b.run(fmt.Sprintf("failed to type into %s", selector),
 		chromedp.WaitVisible(selector),
 		chromedp.Clear(selector),
 		chromedp.SendKeys(selector, text),
@@ -34,10 +35,10 @@ func (b *Browser) Type(selector, text string) error {
 //	err := browser.Click("button[type='submit']")
 func (b *Browser) Click(selector string) error {
 	if selector == "" {
-		return fmt.Errorf("selector cannot be empty")
+		return apperrors.NewValidationError("selector cannot be empty")
 	}
 
-	return chromedp.Run(b.ctx,
+	return b.run(fmt.Sprintf("failed to click %s", selector),
 		chromedp.WaitVisible(selector),
 		chromedp.Click(selector),
 	)
@@ -51,10 +52,10 @@ func (b *Browser) Click(selector string) error {
 //	err := browser.WaitVisible("div.search-results")
 func (b *Browser) WaitVisible(selector string) error {
 	if selector == "" {
-		return fmt.Errorf("selector cannot be empty")
+		return apperrors.NewValidationError("selector cannot be empty")
 	}
 
-	return chromedp.Run(b.ctx,
+	return b.run(fmt.Sprintf("element %s did not become visible", selector),
 		chromedp.WaitVisible(selector),
 	)
 }
@@ -66,10 +67,10 @@ func (b *Browser) WaitVisible(selector string) error {
 //	err := browser.WaitNotVisible("div.loading")
 func (b *Browser) WaitNotVisible(selector string) error {
 	if selector == "" {
-		return fmt.Errorf("selector cannot be empty")
+		return apperrors.NewValidationError("selector cannot be empty")
 	}
 
-	return chromedp.Run(b.ctx,
+	return b.run(fmt.Sprintf("element %s did not disappear", selector),
 		chromedp.WaitNotVisible(selector),
 	)
 }
@@ -81,11 +82,11 @@ func (b *Browser) WaitNotVisible(selector string) error {
 //	text, err := browser.GetText("h1.title")
 func (b *Browser) GetText(selector string) (string, error) {
 	if selector == "" {
-		return "", fmt.Errorf("selector cannot be empty")
+		return "", apperrors.NewValidationError("selector cannot be empty")
 	}
 
 	var text string
-	err := chromedp.Run(b.ctx,
+	err := b.run(fmt.Sprintf("failed to read text of %s", selector),
 		chromedp.WaitVisible(selector),
 		chromedp.Text(selector, &text),
 	)
@@ -103,14 +104,14 @@ func (b *Browser) GetText(selector string) (string, error) {
 //	href, err := browser.GetAttribute("a.link", "href")
 func (b *Browser) GetAttribute(selector, attribute string) (string, error) {
 	if selector == "" {
-		return "", fmt.Errorf("selector cannot be empty")
+		return "", apperrors.NewValidationError("selector cannot be empty")
 	}
 	if attribute == "" {
-		return "", fmt.Errorf("attribute cannot be empty")
+		return "", apperrors.NewValidationError("attribute cannot be empty")
 	}
 
 	var value string
-	err := chromedp.Run(b.ctx,
+	err := b.run(fmt.Sprintf("failed to read %s of %s", attribute, selector),
 		chromedp.WaitVisible(selector),
 		chromedp.AttributeValue(selector, attribute, &value, nil),
 	)
@@ -131,8 +132,11 @@ func (b *Browser) ElementExists(selector string) bool {
 		return false
 	}
 
+	ctx, cancel := b.actionContext()
+	defer cancel()
+
 	var exists bool
-	err := chromedp.Run(b.ctx,
+	err := chromedp.Run(ctx,
 		chromedp.Evaluate(fmt.Sprintf("document.querySelector('%s') !== null", selector), &exists),
 	)
 
@@ -145,7 +149,7 @@ func (b *Browser) ElementExists(selector string) bool {
 //
 //	browser.Sleep(2 * time.Second)
 func (b *Browser) Sleep(duration time.Duration) error {
-	return chromedp.Run(b.ctx,
+	return b.run("sleep interrupted",
 		chromedp.Sleep(duration),
 	)
 }
@@ -156,7 +160,7 @@ func (b *Browser) Sleep(duration time.Duration) error {
 //
 //	err := browser.Scroll(0, 500) // Scroll down 500px
 func (b *Browser) Scroll(x, y int) error {
-	return chromedp.Run(b.ctx,
+	return b.run("failed to scroll",
 		chromedp.Evaluate(fmt.Sprintf("window.scrollBy(%d, %d)", x, y), nil),
 	)
 }
@@ -168,10 +172,10 @@ func (b *Browser) Scroll(x, y int) error {
 //	err := browser.ScrollToElement("div.footer")
 func (b *Browser) ScrollToElement(selector string) error {
 	if selector == "" {
-		return fmt.Errorf("selector cannot be empty")
+		return apperrors.NewValidationError("selector cannot be empty")
 	}
 
-	return chromedp.Run(b.ctx,
+	return b.run(fmt.Sprintf("failed to scroll to %s", selector),
 		chromedp.ScrollIntoView(selector),
 	)
 }
@@ -186,7 +190,7 @@ func (b *Browser) ScrollToElement(selector string) error {
 //	}
 func (b *Browser) Screenshot() ([]byte, error) {
 	var buf []byte
-	err := chromedp.Run(b.ctx,
+	err := b.run("failed to take screenshot",
 		chromedp.FullScreenshot(&buf, 90),
 	)
 	if err != nil {
@@ -203,7 +207,7 @@ func (b *Browser) Screenshot() ([]byte, error) {
 //	url, err := browser.GetCurrentURL()
 func (b *Browser) GetCurrentURL() (string, error) {
 	var url string
-	err := chromedp.Run(b.ctx,
+	err := b.run("failed to read current URL",
 		chromedp.Evaluate("window.location.href", &url),
 	)
 	if err != nil {
@@ -220,7 +224,7 @@ func (b *Browser) GetCurrentURL() (string, error) {
 //	title, err := browser.GetTitle()
 func (b *Browser) GetTitle() (string, error) {
 	var title string
-	err := chromedp.Run(b.ctx,
+	err := b.run("failed to read page title",
 		chromedp.Title(&title),
 	)
 	if err != nil {
@@ -236,7 +240,7 @@ func (b *Browser) GetTitle() (string, error) {
 //
 //	err := browser.Reload()
 func (b *Browser) Reload() error {
-	return chromedp.Run(b.ctx,
+	return b.run("failed to reload page",
 		chromedp.Reload(),
 	)
 }
@@ -247,7 +251,7 @@ func (b *Browser) Reload() error {
 //
 //	err := browser.GoBack()
 func (b *Browser) GoBack() error {
-	return chromedp.Run(b.ctx,
+	return b.run("failed to navigate back",
 		chromedp.NavigateBack(),
 	)
 }
@@ -258,7 +262,7 @@ func (b *Browser) GoBack() error {
 //
 //	err := browser.GoForward()
 func (b *Browser) GoForward() error {
-	return chromedp.Run(b.ctx,
+	return b.run("failed to navigate forward",
 		chromedp.NavigateForward(),
 	)
 }
@@ -271,11 +275,11 @@ func (b *Browser) GoForward() error {
 //	err := browser.TypeHumanLike("input[name='q']", "golang tutorial")
 func (b *Browser) TypeHumanLike(selector, text string) error {
 	if selector == "" {
-		return fmt.Errorf("selector cannot be empty")
+		return apperrors.NewValidationError("selector cannot be empty")
 	}
 
 	// Wait for element and clear it
-	if err := chromedp.Run(b.ctx,
+	if err := b.run(fmt.Sprintf("failed to type into %s", selector),
 		chromedp.WaitVisible(selector),
 		chromedp.Clear(selector),
 		chromedp.Click(selector),
@@ -287,7 +291,7 @@ func (b *Browser) TypeHumanLike(selector, text string) error {
 	for _, char := range text {
 		delay := utils.RandomDuration(50*time.Millisecond, 200*time.Millisecond)
 
-		if err := chromedp.Run(b.ctx,
+		if err := b.run(fmt.Sprintf("failed to type into %s", selector),
 			chromedp.SendKeys(selector, string(char)),
 			chromedp.Sleep(delay),
 		); err != nil {
@@ -306,12 +310,12 @@ func (b *Browser) TypeHumanLike(selector, text string) error {
 //	err := browser.ClickWithDelay("button[type='submit']", 1*time.Second, 3*time.Second)
 func (b *Browser) ClickWithDelay(selector string, minDelay, maxDelay time.Duration) error {
 	if selector == "" {
-		return fmt.Errorf("selector cannot be empty")
+		return apperrors.NewValidationError("selector cannot be empty")
 	}
 
 	delay := utils.RandomDuration(minDelay, maxDelay)
 
-	return chromedp.Run(b.ctx,
+	return b.run(fmt.Sprintf("failed to click %s", selector),
 		chromedp.WaitVisible(selector),
 		chromedp.Sleep(delay),
 		chromedp.Click(selector),
@@ -329,7 +333,7 @@ func (b *Browser) ScrollRandom(times int, minPixels, maxPixels int) error {
 		pixels := utils.RandomInt(minPixels, maxPixels)
 		delay := utils.RandomDuration(500*time.Millisecond, 2*time.Second)
 
-		if err := chromedp.Run(b.ctx,
+		if err := b.run("failed to scroll",
 			chromedp.Evaluate(fmt.Sprintf("window.scrollBy(0, %d)", pixels), nil),
 			chromedp.Sleep(delay),
 		); err != nil {
@@ -359,7 +363,7 @@ func (b *Browser) WaitRandom(min, max time.Duration) error {
 //	err := browser.MouseMoveToElement("button#submit")
 func (b *Browser) MouseMoveToElement(selector string) error {
 	if selector == "" {
-		return fmt.Errorf("selector cannot be empty")
+		return apperrors.NewValidationError("selector cannot be empty")
 	}
 
 	// Move mouse to element center
@@ -380,7 +384,7 @@ func (b *Browser) MouseMoveToElement(selector string) error {
 		}
 	`, selector)
 
-	return chromedp.Run(b.ctx,
+	return b.run(fmt.Sprintf("failed to move mouse to %s", selector),
 		chromedp.WaitVisible(selector),
 		chromedp.Evaluate(script, nil),
 	)
@@ -394,7 +398,7 @@ func (b *Browser) MouseMoveToElement(selector string) error {
 //	err := browser.ScrollToElementSmoothly("div.footer")
 func (b *Browser) ScrollToElementSmoothly(selector string) error {
 	if selector == "" {
-		return fmt.Errorf("selector cannot be empty")
+		return apperrors.NewValidationError("selector cannot be empty")
 	}
 
 	script := fmt.Sprintf(`
@@ -406,7 +410,7 @@ func (b *Browser) ScrollToElementSmoothly(selector string) error {
 
 	delay := utils.RandomDuration(500*time.Millisecond, 1500*time.Millisecond)
 
-	return chromedp.Run(b.ctx,
+	return b.run(fmt.Sprintf("failed to scroll to %s", selector),
 		chromedp.Evaluate(script, nil),
 		chromedp.Sleep(delay),
 	)
@@ -420,7 +424,7 @@ func (b *Browser) ScrollToElementSmoothly(selector string) error {
 //	err := browser.HoverElement("a.menu-item")
 func (b *Browser) HoverElement(selector string) error {
 	if selector == "" {
-		return fmt.Errorf("selector cannot be empty")
+		return apperrors.NewValidationError("selector cannot be empty")
 	}
 
 	script := fmt.Sprintf(`
@@ -435,9 +439,44 @@ func (b *Browser) HoverElement(selector string) error {
 		}
 	`, selector)
 
-	return chromedp.Run(b.ctx,
+	return b.run(fmt.Sprintf("failed to hover %s", selector),
 		chromedp.WaitVisible(selector),
 		chromedp.Evaluate(script, nil),
 		chromedp.Sleep(utils.RandomDuration(100*time.Millisecond, 500*time.Millisecond)),
 	)
 }
+
+// Evaluate runs a JavaScript expression in the page and unmarshals its
+// result into res (nil discards the result).
+//
+// Example:
+//
+//	var count int
+//	err := browser.Evaluate(`document.querySelectorAll("div.g").length`, &count)
+func (b *Browser) Evaluate(expression string, res interface{}) error {
+	if expression == "" {
+		return apperrors.NewValidationError("expression cannot be empty")
+	}
+
+	return b.run("failed to evaluate script",
+		chromedp.Evaluate(expression, res),
+	)
+}
+
+// GetHTML returns the outer HTML of the current document.
+// The snapshot reflects the live DOM, including script-rendered content.
+//
+// Example:
+//
+//	html, err := browser.GetHTML()
+func (b *Browser) GetHTML() (string, error) {
+	var html string
+	err := b.run("failed to read page HTML",
+		chromedp.OuterHTML("html", &html, chromedp.ByQuery),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return html, nil
+}
@@ -5,9 +5,11 @@ package browser
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/chromedp/chromedp"
+	apperrors "github.com/omer/go-bot/internal/errors"
 	"github.com/omer/go-bot/internal/proxy"
 )
 
@@ -21,14 +23,22 @@ type Browser struct {
 	proxy       *proxy.Proxy
 	userAgent   string
 	headless    bool
+	device      *Device // Emulated device (nil = browser defaults)
+	emulated    bool    // Device metrics were applied to the tab
+	console     *consoleLog
+	release     func() // Returns a pooled browser context to its Manager (nil for standalone browsers)
+	mu          sync.Mutex
+	bound       context.Context // Ends calls early when done (nil = calls run until the browser's timeout)
 }
 
 // BrowserOptions holds configuration options for creating a browser instance
 type BrowserOptions struct {
-	Headless  bool          // Run browser in headless mode
-	Proxy     *proxy.Proxy  // Proxy to use (optional)
-	UserAgent string        // Custom user agent (optional)
-	Timeout   time.Duration // Context timeout (default: 30s)
+	Headless  bool            // Run browser in headless mode
+	Proxy     *proxy.Proxy    // Proxy to use (optional)
+	UserAgent string          // Custom user agent (optional, overrides the device user agent)
+	Device    string          // Device profile to emulate: desktop, mobile or tablet (optional)
+	Timeout   time.Duration   // Context timeout (default: 30s)
+	Context   context.Context // Parent context; cancelling it closes the browser (default: context.Background())
 }
 
 // NewBrowser creates a new browser instance with the given options.
@@ -46,37 +56,34 @@ type BrowserOptions struct {
 //	}
 //	defer browser.Close()
 func NewBrowser(opts BrowserOptions) (*Browser, error) {
-	// Set default timeout
+	// Set defaults
 	if opts.Timeout == 0 {
 		opts.Timeout = 30 * time.Second
 	}
+	if opts.Context == nil {
+		opts.Context = context.Background()
+	}
 
-	// Set default user agent
-	if opts.UserAgent == "" {
-		opts.UserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+	device, err := resolveDevice(&opts)
+	if err != nil {
+		return nil, err
 	}
 
 	// Prepare chromedp options
-	allocOpts := []chromedp.ExecAllocatorOption{
-		chromedp.NoFirstRun,
-		chromedp.NoDefaultBrowserCheck,
-		chromedp.Flag("disable-blink-features", "AutomationControlled"),
-		chromedp.UserAgent(opts.UserAgent),
-	}
+	allocOpts := append(allocatorOptions(opts.Headless), chromedp.UserAgent(opts.UserAgent))
 
-	// Add headless option
-	if opts.Headless {
-		allocOpts = append(allocOpts, chromedp.Headless)
+	// Size the window to the device screen
+	if device != nil {
+		allocOpts = append(allocOpts, chromedp.WindowSize(int(device.Width), int(device.Height)))
 	}
 
 	// Add proxy if provided
 	if opts.Proxy != nil {
-		proxyURL := fmt.Sprintf("%s://%s:%d", opts.Proxy.Type, opts.Proxy.Host, opts.Proxy.Port)
-		allocOpts = append(allocOpts, chromedp.ProxyServer(proxyURL))
+		allocOpts = append(allocOpts, chromedp.ProxyServer(proxyServer(opts.Proxy)))
 	}
 
 	// Create allocator context
-	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), allocOpts...)
+	allocCtx, allocCancel := chromedp.NewExecAllocator(opts.Context, allocOpts...)
 
 	// Create browser context
 	ctx, ctxCancel := chromedp.NewContext(allocCtx)
@@ -93,11 +100,57 @@ func NewBrowser(opts BrowserOptions) (*Browser, error) {
 		proxy:       opts.Proxy,
 		userAgent:   opts.UserAgent,
 		headless:    opts.Headless,
+		device:      device,
+		console:     &consoleLog{},
 	}
+	browser.console.listen(ctx)
 
 	return browser, nil
 }
 
+// defaultUserAgent is the user agent of browsers without a device profile
+const defaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+// resolveDevice looks up the device profile of opts and fills in the user
+// agent it implies. It returns nil when no device is requested.
+func resolveDevice(opts *BrowserOptions) (*Device, error) {
+	var device *Device
+	if opts.Device != "" {
+		profile, err := DeviceProfile(opts.Device)
+		if err != nil {
+			return nil, err
+		}
+		device = &profile
+		if opts.UserAgent == "" {
+			opts.UserAgent = profile.UserAgent
+		}
+	}
+
+	// Set default user agent
+	if opts.UserAgent == "" {
+		opts.UserAgent = defaultUserAgent
+	}
+	return device, nil
+}
+
+// allocatorOptions returns the Chrome flags every browser process starts with
+func allocatorOptions(headless bool) []chromedp.ExecAllocatorOption {
+	allocOpts := []chromedp.ExecAllocatorOption{
+		chromedp.NoFirstRun,
+		chromedp.NoDefaultBrowserCheck,
+		chromedp.Flag("disable-blink-features", "AutomationControlled"),
+	}
+	if headless {
+		allocOpts = append(allocOpts, chromedp.Headless)
+	}
+	return allocOpts
+}
+
+// proxyServer returns the proxy server address Chrome connects through
+func proxyServer(p *proxy.Proxy) string {
+	return fmt.Sprintf("%s://%s:%d", p.Type, p.Host, p.Port)
+}
+
 // Navigate navigates to the specified URL.
 // It waits for the page to be ready before returning.
 //
@@ -109,22 +162,81 @@ func NewBrowser(opts BrowserOptions) (*Browser, error) {
 //	}
 func (b *Browser) Navigate(url string) error {
 	if url == "" {
-		return fmt.Errorf("URL cannot be empty")
+		return apperrors.NewValidationError("URL cannot be empty")
+	}
+
+	// Device metrics are applied once, before the first page is loaded
+	if b.device != nil && !b.emulated {
+		if err := b.run(fmt.Sprintf("failed to emulate device %s", b.device.Name), b.device.emulate()); err != nil {
+			return err
+		}
+		b.emulated = true
 	}
 
-	return chromedp.Run(b.ctx,
+	return b.run(fmt.Sprintf("failed to load %s", url),
 		chromedp.Navigate(url),
 		chromedp.WaitReady("body"),
 	)
 }
 
+// Bind makes the browser's calls also end when ctx is done, until release is
+// called. It bounds one step of a task, such as loading a result page, while
+// the browser stays open for the next step. Binds nest: release restores the
+// previous context.
+//
+// Example:
+//
+//	pageCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+//	defer cancel()
+//	release := browser.Bind(pageCtx)
+//	defer release()
+func (b *Browser) Bind(ctx context.Context) (release func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	previous := b.bound
+	b.bound = ctx
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.bound = previous
+	}
+}
+
+// actionContext returns the context chromedp actions run in: the browser's
+// context, ended early by the bound context
+func (b *Browser) actionContext() (context.Context, context.CancelFunc) {
+	b.mu.Lock()
+	bound := b.bound
+	b.mu.Unlock()
+
+	if bound == nil {
+		return b.ctx, func() {}
+	}
+
+	// Keep the deadline, so running past it is reported as a timeout
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if deadline, ok := bound.Deadline(); ok {
+		ctx, cancel = context.WithDeadline(b.ctx, deadline)
+	} else {
+		ctx, cancel = context.WithCancel(b.ctx)
+	}
+	stop := context.AfterFunc(bound, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
 // GetContext returns the browser's context.
 // This can be used for advanced chromedp operations.
 func (b *Browser) GetContext() context.Context {
 	return b.ctx
 }
 
-// Close closes the browser and cleans up all resources.
+// Close closes the browser and cleans up all resources. A browser from a
+// Manager closes its tab and browser context and leaves the process running.
 // It's safe to call Close multiple times.
 func (b *Browser) Close() error {
 	if b.cancel != nil {
@@ -139,6 +251,10 @@ func (b *Browser) Close() error {
 		b.allocCancel()
 		b.allocCancel = nil
 	}
+	if b.release != nil {
+		b.release()
+		b.release = nil
+	}
 	return nil
 }
 
@@ -147,6 +263,14 @@ func (b *Browser) IsHeadless() bool {
 	return b.headless
 }
 
+// GetDevice returns the name of the emulated device profile (empty if none)
+func (b *Browser) GetDevice() string {
+	if b.device == nil {
+		return ""
+	}
+	return b.device.Name
+}
+
 // GetUserAgent returns the browser's user agent string
 func (b *Browser) GetUserAgent() string {
 	return b.userAgent
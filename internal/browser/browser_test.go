@@ -2,9 +2,14 @@ package browser
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/go-json-experiment/json/jsontext"
+	apperrors "github.com/omer/go-bot/internal/errors"
 	"github.com/omer/go-bot/internal/proxy"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -717,3 +722,297 @@ func TestBrowser_HoverElement_EmptySelector(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "selector cannot be empty")
 }
+
+// ===== device.go tests =====
+
+func TestDeviceProfile(t *testing.T) {
+	desktop, err := DeviceProfile("")
+	require.NoError(t, err)
+	assert.Equal(t, DeviceDesktop, desktop.Name)
+	assert.False(t, desktop.Mobile)
+
+	mobile, err := DeviceProfile(" Mobile ")
+	require.NoError(t, err)
+	assert.Equal(t, DeviceMobile, mobile.Name)
+	assert.True(t, mobile.Mobile)
+	assert.True(t, mobile.Touch)
+	assert.Contains(t, mobile.UserAgent, "Mobile")
+
+	_, err = DeviceProfile("smartwatch")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown device profile")
+}
+
+func TestNewBrowser_UnknownDevice(t *testing.T) {
+	browser, err := NewBrowser(BrowserOptions{Device: "smartwatch"})
+	assert.Error(t, err)
+	assert.Nil(t, browser)
+}
+
+func TestNewBrowser_WithDevice(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping browser test in short mode")
+	}
+
+	browser, err := NewBrowser(BrowserOptions{
+		Headless: true,
+		Device:   DeviceMobile,
+	})
+	require.NoError(t, err)
+	defer browser.Close()
+
+	mobile, _ := DeviceProfile(DeviceMobile)
+	assert.Equal(t, DeviceMobile, browser.GetDevice())
+	assert.Equal(t, mobile.UserAgent, browser.GetUserAgent())
+}
+
+// ===== errors.go tests =====
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		errType apperrors.ErrorType
+	}{
+		{"deadline", fmt.Errorf("wait: %w", context.DeadlineExceeded), apperrors.ErrorTypeTimeout},
+		{"proxy", errors.New("page load error net::ERR_PROXY_CONNECTION_FAILED"), apperrors.ErrorTypeProxy},
+		{"tunnel", errors.New("page load error net::ERR_TUNNEL_CONNECTION_FAILED"), apperrors.ErrorTypeProxy},
+		{"network", errors.New("page load error net::ERR_NAME_NOT_RESOLVED"), apperrors.ErrorTypeNetwork},
+		{"browser", errors.New("exec: \"google-chrome\": executable file not found"), apperrors.ErrorTypeBrowser},
+		{"classified", apperrors.NewCaptchaError("captcha"), apperrors.ErrorTypeCaptcha},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classify(tt.err, "failed to load page")
+			assert.Equal(t, tt.errType, apperrors.GetType(err))
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	assert.NoError(t, classify(nil, "failed to load page"))
+}
+
+// ===== console.go tests =====
+
+func TestConsoleLog_DropsOldest(t *testing.T) {
+	console := &consoleLog{}
+	for i := 0; i < maxConsoleEntries+2; i++ {
+		console.add(fmt.Sprintf("entry %d", i))
+	}
+
+	entries := console.snapshot()
+	assert.Len(t, entries, maxConsoleEntries+1)
+	assert.Equal(t, "... 2 earlier entries dropped", entries[0])
+	assert.Equal(t, "entry 2", entries[1])
+	assert.Equal(t, fmt.Sprintf("entry %d", maxConsoleEntries+1), entries[maxConsoleEntries])
+}
+
+func TestFormatArgs(t *testing.T) {
+	args := []*runtime.RemoteObject{
+		{Type: runtime.TypeString, Value: jsontext.Value(`"failed to load"`)},
+		{Type: runtime.TypeNumber, Value: jsontext.Value(`404`)},
+		{Type: runtime.TypeNumber, UnserializableValue: "NaN"},
+		{Type: runtime.TypeObject, Description: "Error: boom"},
+	}
+
+	assert.Equal(t, "failed to load 404 NaN Error: boom", formatArgs(args))
+}
+
+func TestBrowser_ConsoleLogs_Empty(t *testing.T) {
+	browser, err := NewBrowser(BrowserOptions{Headless: true})
+	require.NoError(t, err)
+	defer browser.Close()
+
+	assert.Empty(t, browser.ConsoleLogs())
+}
+
+// ===== manager.go tests =====
+
+// fakeProcesses replaces the Chrome launch of a manager with processes whose
+// context is cancelled to simulate a crash
+type fakeProcesses struct {
+	launched []*process
+	stopped  []*process
+	fail     error
+}
+
+// newFakeManager returns a manager that launches fake processes
+func newFakeManager(config ManagerConfig) (*Manager, *fakeProcesses) {
+	fake := &fakeProcesses{}
+	m := NewManager(config)
+	m.launch = func() (*process, error) {
+		if fake.fail != nil {
+			return nil, fake.fail
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		p := &process{ctx: ctx}
+		p.cancel = func() {
+			cancel()
+			fake.stopped = append(fake.stopped, p)
+		}
+		fake.launched = append(fake.launched, p)
+		return p, nil
+	}
+	m.open = func(p *process, opts BrowserOptions, device *Device) (*Browser, error) {
+		if err := p.ctx.Err(); err != nil {
+			return nil, classify(err, "failed to open browser context")
+		}
+		return &Browser{ctx: p.ctx, userAgent: opts.UserAgent, headless: m.headless, device: device, console: &consoleLog{}}, nil
+	}
+	return m, fake
+}
+
+func TestNewManager_Defaults(t *testing.T) {
+	m := NewManager(ManagerConfig{})
+	assert.Equal(t, 2, m.size)
+	assert.Equal(t, 50, m.maxUses)
+	assert.NotNil(t, m.ctx)
+}
+
+func TestManager_SpreadsContexts(t *testing.T) {
+	m, fake := newFakeManager(ManagerConfig{Size: 2, Headless: true})
+	defer m.Close()
+
+	// Busy processes get company until the pool is full
+	var browsers []*Browser
+	for i := 0; i < 4; i++ {
+		b, err := m.NewBrowser(BrowserOptions{Device: DeviceMobile})
+		require.NoError(t, err)
+		browsers = append(browsers, b)
+	}
+	require.Len(t, fake.launched, 2)
+	assert.Equal(t, 2, fake.launched[0].active)
+	assert.Equal(t, 2, fake.launched[1].active)
+
+	mobile, _ := DeviceProfile(DeviceMobile)
+	assert.Equal(t, mobile.UserAgent, browsers[0].GetUserAgent())
+	assert.Equal(t, DeviceMobile, browsers[0].GetDevice())
+	assert.True(t, browsers[0].IsHeadless())
+
+	// Closing twice releases the context once
+	require.NoError(t, browsers[0].Close())
+	require.NoError(t, browsers[0].Close())
+	assert.Equal(t, ManagerStats{Processes: 2, Active: 3, Contexts: 4, Launches: 2}, m.Stats())
+
+	// An idle process is reused before a new one is launched
+	for _, b := range browsers[1:] {
+		require.NoError(t, b.Close())
+	}
+	b, err := m.NewBrowser(BrowserOptions{})
+	require.NoError(t, err)
+	defer b.Close()
+	assert.Len(t, fake.launched, 2)
+	assert.Empty(t, fake.stopped)
+}
+
+func TestManager_RecyclesAfterMaxUses(t *testing.T) {
+	m, fake := newFakeManager(ManagerConfig{Size: 1, MaxUses: 2})
+	defer m.Close()
+
+	first, err := m.NewBrowser(BrowserOptions{})
+	require.NoError(t, err)
+	require.NoError(t, first.Close())
+
+	// The second use retires the process, which stops when the context closes
+	second, err := m.NewBrowser(BrowserOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, m.Stats().Recycles)
+	assert.Empty(t, fake.stopped)
+
+	third, err := m.NewBrowser(BrowserOptions{})
+	require.NoError(t, err)
+	require.Len(t, fake.launched, 2)
+
+	require.NoError(t, second.Close())
+	assert.Equal(t, []*process{fake.launched[0]}, fake.stopped)
+	require.NoError(t, third.Close())
+
+	stats := m.Stats()
+	assert.Equal(t, 2, stats.Launches)
+	assert.Equal(t, 3, stats.Contexts)
+	assert.Equal(t, 0, stats.Active)
+}
+
+func TestManager_RecyclesCrashedProcess(t *testing.T) {
+	m, fake := newFakeManager(ManagerConfig{Size: 1})
+	defer m.Close()
+
+	b, err := m.NewBrowser(BrowserOptions{})
+	require.NoError(t, err)
+
+	// The process exits while a task runs in it
+	crashed := fake.launched[0]
+	crashed.cancel()
+	require.NoError(t, b.Close())
+	assert.Equal(t, 1, m.Stats().Crashes)
+	assert.Equal(t, 0, m.Stats().Processes)
+
+	b, err = m.NewBrowser(BrowserOptions{})
+	require.NoError(t, err)
+	defer b.Close()
+	assert.Len(t, fake.launched, 2)
+
+	// A process that exits while idle is replaced on the next use
+	idle, _ := newFakeManager(ManagerConfig{Size: 1})
+	defer idle.Close()
+	b, err = idle.NewBrowser(BrowserOptions{})
+	require.NoError(t, err)
+	require.NoError(t, b.Close())
+	idle.processes[0].cancel()
+
+	b, err = idle.NewBrowser(BrowserOptions{})
+	require.NoError(t, err)
+	defer b.Close()
+	assert.Equal(t, ManagerStats{Processes: 1, Active: 1, Contexts: 2, Launches: 2, Crashes: 1}, idle.Stats())
+}
+
+func TestManager_Errors(t *testing.T) {
+	m, fake := newFakeManager(ManagerConfig{})
+
+	_, err := m.NewBrowser(BrowserOptions{Device: "smartwatch"})
+	assert.True(t, apperrors.Is(err, apperrors.ErrorTypeValidation), "got %v", err)
+
+	fake.fail = apperrors.NewBrowserError("failed to launch browser", errors.New("exec: \"google-chrome\": executable file not found"))
+	driver, err := m.NewDriver(BrowserOptions{})
+	assert.Nil(t, driver)
+	assert.Equal(t, fake.fail, err)
+	assert.Equal(t, 1, m.Stats().LaunchFailures)
+
+	// Closing stops idle processes and refuses new contexts
+	fake.fail = nil
+	b, err := m.NewBrowser(BrowserOptions{})
+	require.NoError(t, err)
+	require.NoError(t, b.Close())
+	require.NoError(t, m.Close())
+	require.NoError(t, m.Close())
+	assert.Equal(t, fake.launched, fake.stopped)
+
+	_, err = m.NewBrowser(BrowserOptions{})
+	assert.True(t, apperrors.Is(err, apperrors.ErrorTypeBrowser), "got %v", err)
+}
+
+func TestManager_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping browser test in short mode")
+	}
+
+	m := NewManager(ManagerConfig{Size: 1, MaxUses: 2, Headless: true})
+	defer m.Close()
+
+	for i := 0; i < 3; i++ {
+		b, err := m.NewBrowser(BrowserOptions{Device: DeviceMobile, Timeout: 30 * time.Second})
+		require.NoError(t, err)
+
+		var userAgent string
+		require.NoError(t, b.Navigate("about:blank"))
+		require.NoError(t, b.Evaluate("navigator.userAgent", &userAgent))
+		assert.Equal(t, b.GetUserAgent(), userAgent)
+		require.NoError(t, b.Close())
+	}
+
+	stats := m.Stats()
+	assert.Equal(t, 2, stats.Launches)
+	assert.Equal(t, 1, stats.Recycles)
+	assert.Equal(t, 3, stats.Contexts)
+}
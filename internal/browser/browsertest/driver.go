@@ -0,0 +1,643 @@
+// Package browsertest provides a scriptable in-memory browser.Driver for
+// tests. The fake serves HTML pages registered by URL and evaluates CSS
+// selectors against them, so code written against browser.Driver can be
+// tested without Chrome: typing "\n" submits the enclosing form, clicking a
+// link opens its href, calls fail once the bound context is done, and every
+// call is recorded for assertions.
+package browsertest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/omer/go-bot/internal/browser"
+	apperrors "github.com/omer/go-bot/internal/errors"
+)
+
+// BlankURL is the URL of a driver before the first navigation
+const BlankURL = "about:blank"
+
+// Call is a driver method call recorded by the fake
+type Call struct {
+	Method string   // Method name, e.g. "Navigate"
+	Args   []string // Arguments formatted with %v
+}
+
+// String returns the call as "Method(arg, ...)"
+func (c Call) String() string {
+	return fmt.Sprintf("%s(%s)", c.Method, strings.Join(c.Args, ", "))
+}
+
+// EvaluateFunc answers Evaluate calls of a fake driver
+type EvaluateFunc func(expression string) (interface{}, error)
+
+// Driver is a scriptable in-memory browser.Driver
+type Driver struct {
+	mu         sync.Mutex
+	pages      map[string]string // HTML of the pages the driver can open, by URL
+	url        string            // URL of the current page
+	doc        *goquery.Document // DOM of the current page
+	calls      []Call            // Method calls, in order
+	failures   map[string]error  // Errors returned by a method, by method name
+	evaluate   EvaluateFunc      // Answers Evaluate calls (nil = error)
+	console    []string          // Console messages returned by ConsoleLogs
+	screenshot []byte            // Image returned by Screenshot
+	hangs      map[string]bool   // Methods that wait for the bound context, by method name
+	bound      context.Context   // Context set with Bind (nil = none)
+	life       context.Context   // Lifetime of the browser from the factory options (nil = unlimited)
+	endLife    func()            // Releases life
+	closed     bool              // Close was called
+	done       chan struct{}     // Closed by Close
+}
+
+// NewDriver creates a fake driver on a blank page
+//
+// Example:
+//
+//	driver := browsertest.NewDriver().
+//	    AddPage("https://www.google.com/", homeHTML).
+//	    AddPage("https://www.google.com/search?q=golang", resultsHTML)
+//	searcher := serp.NewSearcher(driver, log)
+func NewDriver() *Driver {
+	d := &Driver{
+		pages:      make(map[string]string),
+		failures:   make(map[string]error),
+		screenshot: []byte("\x89PNG\r\n\x1a\n"),
+		hangs:      make(map[string]bool),
+		done:       make(chan struct{}),
+	}
+	_ = d.load(BlankURL, "<html><head></head><body></body></html>")
+	return d
+}
+
+// Factory returns a browser.DriverFactory that hands out the given drivers
+// in order, one per call; it fails once they are used up. Like a browser,
+// a driver fails every call once the Context of the options is done or their
+// Timeout passed.
+//
+// Example:
+//
+//	pool := task.NewWorkerPool(task.WorkerPoolConfig{
+//	    DriverFactory: browsertest.Factory(driver),
+//	})
+func Factory(drivers ...*Driver) browser.DriverFactory {
+	var mu sync.Mutex
+	return func(opts browser.BrowserOptions) (browser.Driver, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if len(drivers) == 0 {
+			return nil, apperrors.NewBrowserError("failed to launch browser", errors.New("no fake driver left"))
+		}
+		d := drivers[0]
+		drivers = drivers[1:]
+		d.live(opts)
+		return d, nil
+	}
+}
+
+// live starts the lifetime of the browser the options describe
+func (d *Driver) live(opts browser.BrowserOptions) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if opts.Timeout > 0 {
+		d.life, d.endLife = context.WithTimeout(ctx, opts.Timeout)
+	} else {
+		d.life, d.endLife = context.WithCancel(ctx)
+	}
+}
+
+// AddPage registers the HTML served for a URL. URLs are matched exactly,
+// after parsing, so query parameters must be in url.Values.Encode order.
+func (d *Driver) AddPage(rawURL, html string) *Driver {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.pages[normalizeURL(rawURL)] = html
+	return d
+}
+
+// Fail makes every later call of a method return err (nil clears it)
+//
+// Example:
+//
+//	driver.Fail("Navigate", apperrors.NewNetworkError("failed to load", nil))
+func (d *Driver) Fail(method string, err error) *Driver {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err == nil {
+		delete(d.failures, method)
+	} else {
+		d.failures[method] = err
+	}
+	return d
+}
+
+// Hang makes every later call of a method wait until the bound context or
+// the browser lifetime is done, or the driver is closed, like Chrome waiting
+// for an element that never shows up
+//
+// Example:
+//
+//	driver.Hang("WaitVisible")
+func (d *Driver) Hang(method string) *Driver {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.hangs[method] = true
+	return d
+}
+
+// OnEvaluate sets the function answering Evaluate calls
+func (d *Driver) OnEvaluate(fn EvaluateFunc) *Driver {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.evaluate = fn
+	return d
+}
+
+// SetConsoleLogs sets the messages returned by ConsoleLogs
+func (d *Driver) SetConsoleLogs(messages ...string) *Driver {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.console = messages
+	return d
+}
+
+// SetScreenshot sets the image returned by Screenshot
+func (d *Driver) SetScreenshot(image []byte) *Driver {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.screenshot = image
+	return d
+}
+
+// Calls returns the method calls made so far, in order
+func (d *Driver) Calls() []Call {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]Call(nil), d.calls...)
+}
+
+// CallsTo returns the calls made to a method, in order
+func (d *Driver) CallsTo(method string) []Call {
+	var calls []Call
+	for _, call := range d.Calls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Closed reports whether Close was called
+func (d *Driver) Closed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.closed
+}
+
+// Navigate opens a registered page; unknown URLs fail like an unresolvable host
+func (d *Driver) Navigate(rawURL string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.begin("Navigate", rawURL); err != nil {
+		return err
+	}
+	if rawURL == "" {
+		return apperrors.NewValidationError("URL cannot be empty")
+	}
+	return d.open(rawURL)
+}
+
+// GetCurrentURL returns the URL of the current page
+func (d *Driver) GetCurrentURL() (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.begin("GetCurrentURL"); err != nil {
+		return "", err
+	}
+	return d.url, nil
+}
+
+// GetTitle returns the title of the current page
+func (d *Driver) GetTitle() (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.begin("GetTitle"); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(d.doc.Find("title").First().Text()), nil
+}
+
+// WaitVisible fails at once with a timeout error when no element matches,
+// where Chrome would wait for the deadline
+func (d *Driver) WaitVisible(selector string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.begin("WaitVisible", selector); err != nil {
+		return err
+	}
+	_, err := d.find(selector, fmt.Sprintf("element %s did not become visible", selector))
+	return err
+}
+
+// ElementExists reports whether an element matches the selector on the current page
+func (d *Driver) ElementExists(selector string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.begin("ElementExists", selector); err != nil {
+		return false
+	}
+	return d.matches(selector).Length() > 0
+}
+
+// Type replaces the value of a text field like Chrome's clear and send
+// keys; a "\n" presses Enter, which submits the enclosing form
+func (d *Driver) Type(selector, text string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.begin("Type", selector, text); err != nil {
+		return err
+	}
+	field, err := d.find(selector, fmt.Sprintf("failed to type into %s", selector))
+	if err != nil {
+		return err
+	}
+
+	value, _, enter := strings.Cut(text, "\n")
+	if goquery.NodeName(field) == "textarea" {
+		field.SetText(value)
+	} else {
+		field.SetAttr("value", value)
+	}
+
+	if !enter {
+		return nil
+	}
+	return d.submit(field.Closest("form"))
+}
+
+// Click follows links and submits forms through their submit buttons
+func (d *Driver) Click(selector string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.begin("Click", selector); err != nil {
+		return err
+	}
+	return d.click(selector)
+}
+
+// ClickWithDelay clicks without waiting
+func (d *Driver) ClickWithDelay(selector string, minDelay, maxDelay time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.begin("ClickWithDelay", selector, minDelay, maxDelay); err != nil {
+		return err
+	}
+	return d.click(selector)
+}
+
+// HoverElement checks that the element exists
+func (d *Driver) HoverElement(selector string) error {
+	return d.onElement("HoverElement", selector, "failed to hover %s")
+}
+
+// Scroll records the call
+func (d *Driver) Scroll(x, y int) error {
+	return d.record("Scroll", x, y)
+}
+
+// ScrollRandom records the call
+func (d *Driver) ScrollRandom(times int, minPixels, maxPixels int) error {
+	return d.record("ScrollRandom", times, minPixels, maxPixels)
+}
+
+// ScrollToElement checks that the element exists
+func (d *Driver) ScrollToElement(selector string) error {
+	return d.onElement("ScrollToElement", selector, "failed to scroll to %s")
+}
+
+// ScrollToElementSmoothly records the call; like the script Chrome runs,
+// it ignores missing elements
+func (d *Driver) ScrollToElementSmoothly(selector string) error {
+	return d.record("ScrollToElementSmoothly", selector)
+}
+
+// Sleep records the call without sleeping
+func (d *Driver) Sleep(duration time.Duration) error {
+	return d.record("Sleep", duration)
+}
+
+// WaitRandom records the call without waiting
+func (d *Driver) WaitRandom(min, max time.Duration) error {
+	return d.record("WaitRandom", min, max)
+}
+
+// GetHTML returns the outer HTML of the current document, including typed values
+func (d *Driver) GetHTML() (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.begin("GetHTML"); err != nil {
+		return "", err
+	}
+	html, err := goquery.OuterHtml(d.doc.Find("html"))
+	if err != nil {
+		return "", apperrors.NewBrowserError("failed to read page HTML", err)
+	}
+	return html, nil
+}
+
+// Evaluate answers with the OnEvaluate function, converting its result into
+// res through JSON like Chrome's remote object values
+func (d *Driver) Evaluate(expression string, res interface{}) error {
+	d.mu.Lock()
+	if err := d.begin("Evaluate", expression); err != nil {
+		d.mu.Unlock()
+		return err
+	}
+	evaluate := d.evaluate
+	d.mu.Unlock()
+
+	if evaluate == nil {
+		return apperrors.NewBrowserError("failed to evaluate script", errors.New("no evaluate function scripted"))
+	}
+	value, err := evaluate(expression)
+	if err != nil {
+		return apperrors.NewBrowserError("failed to evaluate script", err)
+	}
+	if res == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return apperrors.NewBrowserError("failed to evaluate script", err)
+	}
+	if err := json.Unmarshal(data, res); err != nil {
+		return apperrors.NewBrowserError("failed to evaluate script", err)
+	}
+	return nil
+}
+
+// Screenshot returns the image set with SetScreenshot (a PNG signature by default)
+func (d *Driver) Screenshot() ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.begin("Screenshot"); err != nil {
+		return nil, err
+	}
+	return d.screenshot, nil
+}
+
+// ConsoleLogs returns the messages set with SetConsoleLogs
+func (d *Driver) ConsoleLogs() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.calls = append(d.calls, Call{Method: "ConsoleLogs"})
+	return append([]string(nil), d.console...)
+}
+
+// Bind makes later calls fail once ctx is done, until release is called
+func (d *Driver) Bind(ctx context.Context) (release func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	previous := d.bound
+	d.bound = ctx
+	return func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.bound = previous
+	}
+}
+
+// Close marks the driver closed; later calls fail like on a closed browser
+func (d *Driver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.calls = append(d.calls, Call{Method: "Close"})
+	if !d.closed {
+		d.closed = true
+		close(d.done)
+	}
+	if d.endLife != nil {
+		d.endLife()
+	}
+	return nil
+}
+
+// record records a call that has no effect on the page
+func (d *Driver) record(method string, args ...interface{}) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.begin(method, args...)
+}
+
+// onElement records a call that only needs the element to exist
+func (d *Driver) onElement(method, selector, message string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.begin(method, selector); err != nil {
+		return err
+	}
+	_, err := d.find(selector, fmt.Sprintf(message, selector))
+	return err
+}
+
+// begin records a call and returns the error the call must fail with, if any
+func (d *Driver) begin(method string, args ...interface{}) error {
+	call := Call{Method: method}
+	for _, arg := range args {
+		call.Args = append(call.Args, fmt.Sprint(arg))
+	}
+	d.calls = append(d.calls, call)
+
+	if d.hangs[method] && !d.closed {
+		d.wait()
+	}
+	if d.closed {
+		return apperrors.NewBrowserError(fmt.Sprintf("%s failed", method), context.Canceled)
+	}
+	for _, ctx := range []context.Context{d.life, d.bound} {
+		if ctx == nil || ctx.Err() == nil {
+			continue
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return apperrors.NewTimeoutError(fmt.Sprintf("%s timed out", method), ctx.Err())
+		}
+		return apperrors.NewBrowserError(fmt.Sprintf("%s failed", method), ctx.Err())
+	}
+	return d.failures[method]
+}
+
+// wait blocks until the bound context or the browser lifetime is done, or
+// the driver is closed. It releases d.mu while waiting, so Close can be
+// called from another goroutine.
+func (d *Driver) wait() {
+	var bound, life <-chan struct{}
+	if d.bound != nil {
+		bound = d.bound.Done()
+	}
+	if d.life != nil {
+		life = d.life.Done()
+	}
+
+	d.mu.Unlock()
+	defer d.mu.Lock()
+	select {
+	case <-bound:
+	case <-life:
+	case <-d.done:
+	}
+}
+
+// matches returns the elements of the current page matching a selector
+// (none for an empty or invalid selector)
+func (d *Driver) matches(selector string) *goquery.Selection {
+	return d.doc.Find(selector)
+}
+
+// find returns the first element matching a selector, or the timeout error
+// Chrome reports after waiting for it in vain
+func (d *Driver) find(selector, message string) (*goquery.Selection, error) {
+	if selector == "" {
+		return nil, apperrors.NewValidationError("selector cannot be empty")
+	}
+	element := d.matches(selector).First()
+	if element.Length() == 0 {
+		return nil, apperrors.NewTimeoutError(message, context.DeadlineExceeded)
+	}
+	return element, nil
+}
+
+// click follows a link or submits the form of a submit button
+func (d *Driver) click(selector string) error {
+	element, err := d.find(selector, fmt.Sprintf("failed to click %s", selector))
+	if err != nil {
+		return err
+	}
+
+	if href, ok := element.Closest("a[href]").Attr("href"); ok {
+		target, err := d.resolve(href)
+		if err != nil {
+			return err
+		}
+		return d.open(target)
+	}
+
+	if element.Is("button, input[type='submit']") {
+		return d.submit(element.Closest("form"))
+	}
+	return nil
+}
+
+// submit sends a form with the GET method, like pressing Enter in one of its fields
+func (d *Driver) submit(form *goquery.Selection) error {
+	if form.Length() == 0 {
+		return nil
+	}
+
+	action, _ := form.Attr("action")
+	target, err := d.resolve(action)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return apperrors.NewBrowserError("failed to submit form", err)
+	}
+
+	params := url.Values{}
+	form.Find("input[name], textarea[name]").Each(func(_ int, field *goquery.Selection) {
+		name, _ := field.Attr("name")
+		switch {
+		case goquery.NodeName(field) == "textarea":
+			params.Add(name, field.Text())
+		case field.Is("input[type='submit'], input[type='button']"):
+		default:
+			value, _ := field.Attr("value")
+			params.Add(name, value)
+		}
+	})
+	u.RawQuery = params.Encode()
+	return d.open(u.String())
+}
+
+// resolve resolves a link against the current page URL
+func (d *Driver) resolve(href string) (string, error) {
+	base, err := url.Parse(d.url)
+	if err != nil {
+		return "", apperrors.NewBrowserError("invalid page URL", err)
+	}
+	target, err := base.Parse(href)
+	if err != nil {
+		return "", apperrors.NewBrowserError(fmt.Sprintf("invalid link %s", href), err)
+	}
+	return target.String(), nil
+}
+
+// open loads a registered page
+func (d *Driver) open(rawURL string) error {
+	html, ok := d.pages[normalizeURL(rawURL)]
+	if !ok {
+		return apperrors.NewNetworkError(fmt.Sprintf("failed to load %s", rawURL), errors.New("net::ERR_NAME_NOT_RESOLVED"))
+	}
+	return d.load(rawURL, html)
+}
+
+// load makes a page the current one
+func (d *Driver) load(rawURL, html string) error {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return apperrors.NewBrowserError(fmt.Sprintf("failed to load %s", rawURL), err)
+	}
+	d.url = rawURL
+	d.doc = doc
+	return nil
+}
+
+// normalizeURL returns the form URLs are registered and looked up in
+func normalizeURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.RawQuery = u.Query().Encode()
+	if u.Path == "" && u.Host != "" {
+		u.Path = "/"
+	}
+	return u.String()
+}
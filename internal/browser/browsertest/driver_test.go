@@ -0,0 +1,227 @@
+package browsertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/omer/go-bot/internal/browser"
+	apperrors "github.com/omer/go-bot/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const homeHTML = `<html><head><title>Search</title></head><body>
+<form action="/search" method="GET">
+  <textarea name="q"></textarea>
+  <input type="hidden" name="hl" value="tr">
+  <input type="submit" name="btnK" value="Search">
+</form>
+</body></html>`
+
+const resultsHTML = `<html><head><title>golang - Search</title></head><body>
+<div class="g"><a href="https://go.dev/"><h3>Go</h3></a></div>
+<a id="pnnext" href="/search?q=golang&amp;start=10">Next</a>
+</body></html>`
+
+// newSearchDriver returns a driver with a home page, two result pages and a target site
+func newSearchDriver() *Driver {
+	return NewDriver().
+		AddPage("https://search.example/", homeHTML).
+		AddPage("https://search.example/search?q=golang&hl=tr", resultsHTML).
+		AddPage("https://search.example/search?start=10&q=golang", `<html><head><title>Page 2</title></head></html>`).
+		AddPage("https://go.dev", `<html><head><title>The Go Programming Language</title></head></html>`)
+}
+
+// ===== Navigation tests =====
+
+func TestDriver_Navigate(t *testing.T) {
+	driver := newSearchDriver()
+
+	current, err := driver.GetCurrentURL()
+	require.NoError(t, err)
+	assert.Equal(t, BlankURL, current)
+
+	require.NoError(t, driver.Navigate("https://search.example/"))
+	current, err = driver.GetCurrentURL()
+	require.NoError(t, err)
+	assert.Equal(t, "https://search.example/", current)
+	title, err := driver.GetTitle()
+	require.NoError(t, err)
+	assert.Equal(t, "Search", title)
+
+	// Unknown pages fail like an unresolvable host
+	err = driver.Navigate("https://unknown.example/")
+	assert.True(t, apperrors.Is(err, apperrors.ErrorTypeNetwork), "got %v", err)
+	assert.True(t, apperrors.IsRetryable(err))
+
+	err = driver.Navigate("")
+	assert.True(t, apperrors.Is(err, apperrors.ErrorTypeValidation), "got %v", err)
+}
+
+func TestDriver_TypeSubmitsForm(t *testing.T) {
+	driver := newSearchDriver()
+	require.NoError(t, driver.Navigate("https://search.example/"))
+
+	// Typing replaces the value without submitting
+	require.NoError(t, driver.Type("textarea[name='q']", "rust"))
+	require.NoError(t, driver.Type("textarea[name='q']", "golang"))
+	html, err := driver.GetHTML()
+	require.NoError(t, err)
+	assert.Contains(t, html, `<textarea name="q">golang</textarea>`)
+
+	// Enter submits the form with its fields
+	require.NoError(t, driver.Type("textarea[name='q']", "golang\n"))
+	current, err := driver.GetCurrentURL()
+	require.NoError(t, err)
+	assert.Equal(t, "https://search.example/search?hl=tr&q=golang", current)
+	assert.True(t, driver.ElementExists("div.g"))
+	assert.False(t, driver.ElementExists("textarea[name='q']"))
+}
+
+func TestDriver_ClickFollowsLinks(t *testing.T) {
+	driver := newSearchDriver()
+	require.NoError(t, driver.Navigate("https://search.example/search?q=golang&hl=tr"))
+
+	require.NoError(t, driver.Click("a#pnnext"))
+	title, err := driver.GetTitle()
+	require.NoError(t, err)
+	assert.Equal(t, "Page 2", title)
+
+	require.NoError(t, driver.Navigate("https://search.example/search?q=golang&hl=tr"))
+	require.NoError(t, driver.ClickWithDelay("div.g h3", time.Second, 2*time.Second))
+	current, err := driver.GetCurrentURL()
+	require.NoError(t, err)
+	assert.Equal(t, "https://go.dev/", current)
+}
+
+func TestDriver_MissingElement(t *testing.T) {
+	driver := newSearchDriver()
+	require.NoError(t, driver.Navigate("https://search.example/"))
+
+	for name, action := range map[string]func() error{
+		"WaitVisible":     func() error { return driver.WaitVisible("div.g") },
+		"Type":            func() error { return driver.Type("input#missing", "golang") },
+		"Click":           func() error { return driver.Click("a#pnnext") },
+		"HoverElement":    func() error { return driver.HoverElement("a#pnnext") },
+		"ScrollToElement": func() error { return driver.ScrollToElement("a#pnnext") },
+	} {
+		err := action()
+		assert.True(t, apperrors.Is(err, apperrors.ErrorTypeTimeout), "%s: got %v", name, err)
+	}
+
+	assert.False(t, driver.ElementExists("div.g"))
+	assert.False(t, driver.ElementExists("div[invalid"))
+	assert.True(t, apperrors.Is(driver.WaitVisible(""), apperrors.ErrorTypeValidation))
+}
+
+// ===== Scripting tests =====
+
+func TestDriver_Fail(t *testing.T) {
+	driver := newSearchDriver()
+	failure := apperrors.NewNetworkError("failed to load", errors.New("net::ERR_CONNECTION_RESET"))
+
+	driver.Fail("Navigate", failure)
+	assert.Equal(t, failure, driver.Navigate("https://search.example/"))
+
+	driver.Fail("Navigate", nil)
+	assert.NoError(t, driver.Navigate("https://search.example/"))
+}
+
+func TestDriver_BindAndHang(t *testing.T) {
+	driver := newSearchDriver().Hang("WaitVisible")
+	require.NoError(t, driver.Navigate("https://search.example/"))
+
+	// A hanging call waits for the bound context
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	release := driver.Bind(ctx)
+	start := time.Now()
+	err := driver.WaitVisible("textarea")
+	assert.True(t, apperrors.Is(err, apperrors.ErrorTypeTimeout), "got %v", err)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Error(t, driver.Navigate("https://search.example/"), "calls fail once the bound context is done")
+
+	// The driver is usable again once released
+	release()
+	assert.NoError(t, driver.Navigate("https://search.example/"))
+
+	// Close ends a call waiting without a bound context
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		driver.Close()
+	}()
+	assert.Error(t, driver.WaitVisible("textarea"))
+}
+
+func TestDriver_Evaluate(t *testing.T) {
+	driver := NewDriver()
+
+	var count int
+	err := driver.Evaluate(`document.querySelectorAll("div.g").length`, &count)
+	assert.True(t, apperrors.Is(err, apperrors.ErrorTypeBrowser), "unscripted evaluate fails, got %v", err)
+
+	driver.OnEvaluate(func(expression string) (interface{}, error) {
+		if expression == "window.innerWidth" {
+			return map[string]int{"width": 1280}, nil
+		}
+		return 3, nil
+	})
+	require.NoError(t, driver.Evaluate(`document.querySelectorAll("div.g").length`, &count))
+	assert.Equal(t, 3, count)
+
+	var size struct{ Width int }
+	require.NoError(t, driver.Evaluate("window.innerWidth", &size))
+	assert.Equal(t, 1280, size.Width)
+	assert.NoError(t, driver.Evaluate("window.scrollBy(0, 100)", nil))
+}
+
+func TestDriver_CallsAndClose(t *testing.T) {
+	driver := newSearchDriver().SetConsoleLogs("[log] ready").SetScreenshot([]byte("png"))
+
+	require.NoError(t, driver.Navigate("https://search.example/"))
+	require.NoError(t, driver.Sleep(time.Minute))
+	require.NoError(t, driver.WaitRandom(time.Second, 2*time.Second))
+	require.NoError(t, driver.Scroll(0, 300))
+	require.NoError(t, driver.ScrollRandom(2, 100, 200))
+	require.NoError(t, driver.ScrollToElementSmoothly("div.missing"))
+	assert.Equal(t, []string{"[log] ready"}, driver.ConsoleLogs())
+	image, err := driver.Screenshot()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("png"), image)
+
+	assert.Equal(t, []Call{{Method: "Sleep", Args: []string{"1m0s"}}}, driver.CallsTo("Sleep"))
+	assert.Equal(t, "Scroll(0, 300)", driver.CallsTo("Scroll")[0].String())
+	assert.Len(t, driver.Calls(), 8)
+
+	require.NoError(t, driver.Close())
+	assert.True(t, driver.Closed())
+	assert.Error(t, driver.Navigate("https://search.example/"))
+	_, err = driver.GetHTML()
+	assert.Error(t, err)
+}
+
+func TestFactory(t *testing.T) {
+	first, second := NewDriver(), NewDriver()
+	factory := Factory(first, second)
+
+	for _, want := range []*Driver{first, second} {
+		driver, err := factory(browser.BrowserOptions{Headless: true})
+		require.NoError(t, err)
+		assert.Same(t, want, driver)
+	}
+
+	driver, err := factory(browser.BrowserOptions{})
+	assert.Nil(t, driver)
+	assert.True(t, apperrors.Is(err, apperrors.ErrorTypeBrowser), "got %v", err)
+
+	// Calls fail once the browser's context is done, like on a closed browser
+	ctx, cancel := context.WithCancel(context.Background())
+	closing := newSearchDriver()
+	driver, err = Factory(closing)(browser.BrowserOptions{Context: ctx, Timeout: time.Minute})
+	require.NoError(t, err)
+	require.NoError(t, driver.Navigate("https://search.example/"))
+	cancel()
+	assert.True(t, apperrors.Is(driver.Navigate("https://search.example/"), apperrors.ErrorTypeBrowser))
+}
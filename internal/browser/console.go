@@ -0,0 +1,92 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	cdplog "github.com/chromedp/cdproto/log"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// maxConsoleEntries bounds the console messages kept per browser; older
+// messages are dropped first
+const maxConsoleEntries = 500
+
+// consoleLog collects the console messages and browser log entries of a tab
+type consoleLog struct {
+	mu      sync.Mutex
+	entries []string
+	dropped int
+}
+
+// add records one entry, dropping the oldest one when the log is full
+func (c *consoleLog) add(entry string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) == maxConsoleEntries {
+		c.entries = c.entries[1:]
+		c.dropped++
+	}
+	c.entries = append(c.entries, entry)
+}
+
+// snapshot returns a copy of the recorded entries
+func (c *consoleLog) snapshot() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := make([]string, 0, len(c.entries)+1)
+	if c.dropped > 0 {
+		entries = append(entries, fmt.Sprintf("... %d earlier entries dropped", c.dropped))
+	}
+	return append(entries, c.entries...)
+}
+
+// listen records the console messages and log entries of the tab behind ctx
+func (c *consoleLog) listen(ctx context.Context) {
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		switch ev := ev.(type) {
+		case *runtime.EventConsoleAPICalled:
+			c.add(fmt.Sprintf("console.%s: %s", ev.Type, formatArgs(ev.Args)))
+		case *cdplog.EventEntryAdded:
+			entry := fmt.Sprintf("%s %s: %s", ev.Entry.Source, ev.Entry.Level, ev.Entry.Text)
+			if ev.Entry.URL != "" {
+				entry += " (" + ev.Entry.URL + ")"
+			}
+			c.add(entry)
+		}
+	})
+}
+
+// formatArgs renders console call arguments the way DevTools prints them
+func formatArgs(args []*runtime.RemoteObject) string {
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch {
+		case len(arg.Value) > 0:
+			parts = append(parts, strings.Trim(string(arg.Value), `"`))
+		case arg.UnserializableValue != "":
+			parts = append(parts, string(arg.UnserializableValue))
+		default:
+			parts = append(parts, arg.Description)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// ConsoleLogs returns the console messages and browser log entries (failed
+// requests, security warnings, ...) recorded since the browser was created,
+// oldest first. At most the last 500 entries are kept.
+//
+// Example:
+//
+//	for _, line := range browser.ConsoleLogs() {
+//	    fmt.Println(line)
+//	}
+func (b *Browser) ConsoleLogs() []string {
+	return b.console.snapshot()
+}
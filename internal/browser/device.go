@@ -0,0 +1,87 @@
+package browser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+	apperrors "github.com/omer/go-bot/internal/errors"
+)
+
+// Device describes the screen and user agent a browser presents to websites
+type Device struct {
+	Name      string  // Profile name (desktop, mobile, tablet)
+	UserAgent string  // User agent sent with every request
+	Width     int64   // Viewport width in CSS pixels
+	Height    int64   // Viewport height in CSS pixels
+	Scale     float64 // Device pixel ratio
+	Mobile    bool    // Emulate a mobile device (mobile layout, meta viewport)
+	Touch     bool    // Emulate a touch screen
+}
+
+// Device profile names
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+)
+
+// deviceProfiles holds the built-in device profiles
+var deviceProfiles = map[string]Device{
+	DeviceDesktop: {
+		Name:      DeviceDesktop,
+		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+		Width:     1366,
+		Height:    768,
+		Scale:     1,
+	},
+	DeviceMobile: {
+		Name:      DeviceMobile,
+		UserAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
+		Width:     412,
+		Height:    915,
+		Scale:     2.625,
+		Mobile:    true,
+		Touch:     true,
+	},
+	DeviceTablet: {
+		Name:      DeviceTablet,
+		UserAgent: "Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+		Width:     800,
+		Height:    1280,
+		Scale:     2,
+		Mobile:    true,
+		Touch:     true,
+	},
+}
+
+// DeviceProfile returns the built-in device profile with the given name.
+// An empty name selects the desktop profile.
+//
+// Example:
+//
+//	device, err := browser.DeviceProfile("mobile")
+func DeviceProfile(name string) (Device, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = DeviceDesktop
+	}
+
+	device, ok := deviceProfiles[name]
+	if !ok {
+		return Device{}, apperrors.NewValidationError(fmt.Sprintf("unknown device profile: %s", name))
+	}
+	return device, nil
+}
+
+// emulate returns the chromedp action that applies the device metrics to a tab
+func (d Device) emulate() chromedp.EmulateAction {
+	opts := []chromedp.EmulateViewportOption{chromedp.EmulateScale(d.Scale)}
+	if d.Mobile {
+		opts = append(opts, chromedp.EmulateMobile)
+	}
+	if d.Touch {
+		opts = append(opts, chromedp.EmulateTouch)
+	}
+	return chromedp.EmulateViewport(d.Width, d.Height, opts...)
+}
@@ -0,0 +1,79 @@
+package browser
+
+import (
+	"context"
+	"time"
+)
+
+// Driver is the browser surface the SERP automation and the task workers use.
+// *Browser implements it on Chrome; browsertest.Driver implements it in
+// memory so the search logic can be tested without a browser.
+type Driver interface {
+	// Navigate opens a URL and waits for the page to be ready
+	Navigate(url string) error
+	// GetCurrentURL returns the URL of the current page
+	GetCurrentURL() (string, error)
+	// GetTitle returns the title of the current page
+	GetTitle() (string, error)
+
+	// WaitVisible waits for an element to become visible
+	WaitVisible(selector string) error
+	// ElementExists reports whether an element exists on the current page
+	ElementExists(selector string) bool
+	// Type types text into an element; "\n" presses Enter
+	Type(selector, text string) error
+	// Click clicks an element
+	Click(selector string) error
+	// ClickWithDelay clicks an element after a random delay between minDelay and maxDelay
+	ClickWithDelay(selector string, minDelay, maxDelay time.Duration) error
+	// HoverElement moves the pointer over an element
+	HoverElement(selector string) error
+
+	// Scroll scrolls the page by the given pixel amounts
+	Scroll(x, y int) error
+	// ScrollRandom scrolls down a random amount the given number of times
+	ScrollRandom(times int, minPixels, maxPixels int) error
+	// ScrollToElement scrolls an element into view
+	ScrollToElement(selector string) error
+	// ScrollToElementSmoothly scrolls an element into view like a user would
+	ScrollToElementSmoothly(selector string) error
+	// Sleep pauses for the given duration
+	Sleep(duration time.Duration) error
+	// WaitRandom pauses for a random duration between min and max
+	WaitRandom(min, max time.Duration) error
+
+	// GetHTML returns the outer HTML of the current document
+	GetHTML() (string, error)
+	// Evaluate runs a JavaScript expression in the page and stores its result in res
+	Evaluate(expression string, res interface{}) error
+	// Screenshot returns a screenshot of the current page
+	Screenshot() ([]byte, error)
+	// ConsoleLogs returns the console messages of the session
+	ConsoleLogs() []string
+
+	// Bind makes the calls of the driver also end when ctx is done, until
+	// release is called; the driver stays usable afterwards
+	Bind(ctx context.Context) (release func())
+
+	// Close releases the driver
+	Close() error
+}
+
+// DriverFactory creates the driver a task runs in
+type DriverFactory func(opts BrowserOptions) (Driver, error)
+
+// NewDriver is the DriverFactory that launches Chrome with NewBrowser
+//
+// Example:
+//
+//	driver, err := browser.NewDriver(browser.BrowserOptions{Headless: true})
+func NewDriver(opts BrowserOptions) (Driver, error) {
+	b, err := NewBrowser(opts)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Browser implements Driver
+var _ Driver = (*Browser)(nil)
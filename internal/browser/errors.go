@@ -0,0 +1,61 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+	apperrors "github.com/omer/go-bot/internal/errors"
+)
+
+// proxyErrors are the Chrome net errors caused by the proxy rather than the site
+var proxyErrors = []string{
+	"net::ERR_PROXY_",
+	"net::ERR_TUNNEL_CONNECTION_FAILED",
+	"net::ERR_SOCKS_",
+	"net::ERR_NO_SUPPORTED_PROXIES",
+}
+
+// classify wraps a chromedp error in an AppError so callers can tell
+// transient failures (timeouts, network and proxy errors) from permanent ones.
+// It returns nil for a nil error.
+func classify(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		return err
+	}
+
+	text := err.Error()
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return apperrors.NewTimeoutError(message, err)
+	case containsAny(text, proxyErrors):
+		return apperrors.NewProxyError(message, err)
+	case strings.Contains(text, "net::ERR_"):
+		return apperrors.NewNetworkError(message, err)
+	default:
+		return apperrors.NewBrowserError(message, err)
+	}
+}
+
+// run runs chromedp actions in the browser context and classifies the error
+func (b *Browser) run(message string, actions ...chromedp.Action) error {
+	ctx, cancel := b.actionContext()
+	defer cancel()
+	return classify(chromedp.Run(ctx, actions...), message)
+}
+
+// containsAny reports whether s contains any of the substrings
+func containsAny(s string, substrings []string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(s, substring) {
+			return true
+		}
+	}
+	return false
+}
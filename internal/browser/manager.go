@@ -0,0 +1,330 @@
+package browser
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+	apperrors "github.com/omer/go-bot/internal/errors"
+)
+
+// Manager keeps a few long-lived browser processes and hands out a fresh
+// browser context (an incognito-like profile in its own tab) per task, so
+// tasks don't pay for a Chrome launch each. A process is recycled after it
+// served MaxUses contexts or when it crashes.
+type Manager struct {
+	size     int
+	maxUses  int
+	headless bool
+	ctx      context.Context
+
+	// launch starts a browser process and open creates a browser context on
+	// one; tests replace them to run without Chrome
+	launch func() (*process, error)
+	open   func(p *process, opts BrowserOptions, device *Device) (*Browser, error)
+
+	mu        sync.Mutex
+	processes []*process // Processes that take new contexts
+	stats     ManagerStats
+	closed    bool
+}
+
+// ManagerConfig holds configuration for the browser manager
+type ManagerConfig struct {
+	Size     int             // Browser processes kept running (default: 2)
+	MaxUses  int             // Contexts a process serves before it is recycled (default: 50)
+	Headless bool            // Run the processes in headless mode
+	Context  context.Context // Parent context; cancelling it stops every process (default: context.Background())
+}
+
+// ManagerStats holds the counters of a browser manager
+type ManagerStats struct {
+	Processes      int `json:"processes"`       // Running processes that take new contexts
+	Active         int `json:"active"`          // Browser contexts in use
+	Contexts       int `json:"contexts"`        // Browser contexts handed out
+	Launches       int `json:"launches"`        // Processes launched
+	LaunchFailures int `json:"launch_failures"` // Processes that failed to launch
+	Recycles       int `json:"recycles"`        // Processes retired after MaxUses contexts
+	Crashes        int `json:"crashes"`         // Processes retired because they exited
+}
+
+// process is a browser process of the manager
+type process struct {
+	ctx     context.Context // chromedp context of the first tab; done when the process exits
+	cancel  func()          // Stops the process
+	uses    int             // Contexts handed out
+	active  int             // Contexts not closed yet
+	retired bool            // Takes no new contexts; stopped once the last one closes
+	stopped bool
+}
+
+// NewManager creates a browser manager. Processes are launched on demand,
+// up to Size of them. Remember to call Close() when done.
+//
+// Example:
+//
+//	manager := browser.NewManager(browser.ManagerConfig{
+//	    Size:     2,
+//	    MaxUses:  50,
+//	    Headless: true,
+//	})
+//	defer manager.Close()
+//
+//	pool := task.NewWorkerPool(task.WorkerPoolConfig{
+//	    DriverFactory: manager.NewDriver,
+//	})
+func NewManager(config ManagerConfig) *Manager {
+	// Set defaults
+	if config.Size <= 0 {
+		config.Size = 2
+	}
+	if config.MaxUses <= 0 {
+		config.MaxUses = 50
+	}
+	if config.Context == nil {
+		config.Context = context.Background()
+	}
+
+	m := &Manager{
+		size:     config.Size,
+		maxUses:  config.MaxUses,
+		headless: config.Headless,
+		ctx:      config.Context,
+	}
+	m.launch = m.launchProcess
+	m.open = m.openContext
+	return m
+}
+
+// NewBrowser opens a browser context on one of the manager's processes.
+// The options are applied to the context: the proxy, user agent and device
+// are per context, Headless is decided by the manager. Closing the browser
+// closes the context and returns its process to the manager.
+//
+// Example:
+//
+//	b, err := manager.NewBrowser(browser.BrowserOptions{Timeout: time.Minute})
+//	if err != nil {
+//	    return err
+//	}
+//	defer b.Close()
+func (m *Manager) NewBrowser(opts BrowserOptions) (*Browser, error) {
+	// Set defaults
+	if opts.Timeout == 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	if opts.Context == nil {
+		opts.Context = context.Background()
+	}
+
+	device, err := resolveDevice(&opts)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := m.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := m.open(p, opts, device)
+	if err != nil {
+		m.release(p)
+		return nil, err
+	}
+	b.release = func() { m.release(p) }
+	return b, nil
+}
+
+// NewDriver is the DriverFactory of the manager
+func (m *Manager) NewDriver(opts BrowserOptions) (Driver, error) {
+	b, err := m.NewBrowser(opts)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Stats returns the manager's counters
+func (m *Manager) Stats() ManagerStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := m.stats
+	stats.Processes = len(m.processes)
+	return stats
+}
+
+// Close stops the idle processes and makes NewBrowser fail. Processes with
+// open contexts are stopped when their last context is closed.
+// It's safe to call Close multiple times.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	m.closed = true
+	var idle []*process
+	for _, p := range m.processes {
+		p.retired = true
+		if p.active == 0 {
+			idle = append(idle, p)
+		}
+	}
+	m.processes = nil
+	m.mu.Unlock()
+
+	for _, p := range idle {
+		m.stop(p)
+	}
+	return nil
+}
+
+// acquire picks the process for a new context: the one with the fewest
+// open contexts, or a newly launched one while the pool is not full and
+// every process is busy
+func (m *Manager) acquire() (*process, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return nil, apperrors.NewBrowserError("browser manager is closed", nil)
+	}
+
+	// Drop processes that exited since they were last used
+	for _, p := range m.processes {
+		m.checkCrashed(p)
+	}
+
+	var best *process
+	for _, p := range m.processes {
+		if best == nil || p.active < best.active {
+			best = p
+		}
+	}
+	if best == nil || (best.active > 0 && len(m.processes) < m.size) {
+		p, err := m.launch()
+		if err != nil {
+			m.stats.LaunchFailures++
+			return nil, err
+		}
+		m.stats.Launches++
+		m.processes = append(m.processes, p)
+		best = p
+	}
+
+	best.uses++
+	best.active++
+	m.stats.Contexts++
+	m.stats.Active++
+	if best.uses >= m.maxUses {
+		m.retire(best)
+		m.stats.Recycles++
+	}
+	return best, nil
+}
+
+// release returns a context of p and stops p when it is retired and idle
+func (m *Manager) release(p *process) {
+	m.mu.Lock()
+	p.active--
+	m.stats.Active--
+	m.checkCrashed(p)
+	stop := p.retired && p.active == 0
+	m.mu.Unlock()
+
+	if stop {
+		m.stop(p)
+	}
+}
+
+// checkCrashed retires p if its process exited; m.mu must be held
+func (m *Manager) checkCrashed(p *process) {
+	if p.retired || p.ctx.Err() == nil {
+		return
+	}
+	m.retire(p)
+	m.stats.Crashes++
+}
+
+// retire removes p from the processes that take new contexts; m.mu must be held
+func (m *Manager) retire(p *process) {
+	p.retired = true
+	for i, other := range m.processes {
+		if other == p {
+			m.processes = append(m.processes[:i], m.processes[i+1:]...)
+			break
+		}
+	}
+}
+
+// stop stops the process of p once
+func (m *Manager) stop(p *process) {
+	m.mu.Lock()
+	stopped := p.stopped
+	p.stopped = true
+	m.mu.Unlock()
+
+	if !stopped {
+		p.cancel()
+	}
+}
+
+// launchProcess starts a browser process and waits until it is ready
+func (m *Manager) launchProcess() (*process, error) {
+	allocCtx, allocCancel := chromedp.NewExecAllocator(m.ctx, allocatorOptions(m.headless)...)
+	ctx, ctxCancel := chromedp.NewContext(allocCtx)
+	cancel := func() {
+		ctxCancel()
+		allocCancel()
+	}
+
+	if err := chromedp.Run(ctx); err != nil {
+		cancel()
+		return nil, classify(err, "failed to launch browser")
+	}
+	return &process{ctx: ctx, cancel: cancel}, nil
+}
+
+// openContext creates a browser context with its own tab on p. The user
+// agent is overridden in the tab, as the process is shared by tasks with
+// different devices.
+func (m *Manager) openContext(p *process, opts BrowserOptions, device *Device) (*Browser, error) {
+	var contextOpts []chromedp.CreateBrowserContextOption
+	if opts.Proxy != nil {
+		server := proxyServer(opts.Proxy)
+		contextOpts = append(contextOpts, func(params *target.CreateBrowserContextParams) *target.CreateBrowserContextParams {
+			return params.WithProxyServer(server)
+		})
+	}
+
+	// The tab is closed when the task's context is cancelled
+	tabCtx, tabCancel := chromedp.NewContext(p.ctx, chromedp.WithNewBrowserContext(contextOpts...))
+	stopAfter := context.AfterFunc(opts.Context, tabCancel)
+	ctxCancel := func() {
+		stopAfter()
+		tabCancel()
+	}
+
+	// Add timeout
+	ctx, cancel := context.WithTimeout(tabCtx, opts.Timeout)
+
+	browser := &Browser{
+		ctx:       ctx,
+		cancel:    cancel,
+		ctxCancel: ctxCancel,
+		proxy:     opts.Proxy,
+		userAgent: opts.UserAgent,
+		headless:  m.headless,
+		device:    device,
+		console:   &consoleLog{},
+	}
+	browser.console.listen(ctx)
+
+	// Create the tab now, so a dead process fails here and not in the task
+	if err := browser.run("failed to open browser context", emulation.SetUserAgentOverride(opts.UserAgent)); err != nil {
+		browser.Close()
+		return nil, err
+	}
+	return browser, nil
+}
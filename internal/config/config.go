@@ -7,8 +7,12 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
+	"github.com/omer/go-bot/internal/schedule"
+	"github.com/omer/go-bot/internal/serp"
+	"github.com/omer/go-bot/internal/urlmatch"
 )
 
 // Config holds the application configuration
@@ -26,12 +30,38 @@ type Config struct {
 	PageTimeout   int `json:"page_timeout" env:"PAGE_TIMEOUT"`
 	SearchTimeout int `json:"search_timeout" env:"SEARCH_TIMEOUT"`
 
+	// Rank scan settings
+	MaxPages int `json:"max_pages" env:"MAX_PAGES"` // Result pages scanned per keyword
+
 	// Retry settings
 	MaxRetries int `json:"max_retries" env:"MAX_RETRIES"`
 	RetryDelay int `json:"retry_delay" env:"RETRY_DELAY"` // in seconds
 
 	// Selectors
-	Selectors SelectorConfig `json:"selectors"`
+	Selectors        SelectorConfig         `json:"selectors"`
+	SelectorProfiles []serp.SelectorProfile `json:"selector_profiles"` // Custom selector profiles; empty fields fall back to the default profile
+
+	// Search engine root the browser opens instead of Google, e.g. a local mock server
+	SearchURL string `json:"search_url" env:"SEARCH_URL"`
+
+	// Result provider for rank tracking
+	Provider ProviderConfig `json:"provider"`
+
+	// Persistent rank history
+	History HistoryConfig `json:"history"`
+
+	// Debug artifacts of failed tasks
+	Artifacts ArtifactsConfig `json:"artifacts"`
+
+	// Browser processes shared by tasks
+	BrowserPool BrowserPoolConfig `json:"browser_pool"`
+
+	// Rank change alerts
+	Alerts AlertConfig `json:"alerts"`
+
+	// Continuous mode schedules
+	Schedule       ScheduleConfig            `json:"schedule"`        // Default schedule of every keyword
+	ScheduleGroups map[string]ScheduleConfig `json:"schedule_groups"` // Named schedules keywords select with "group"
 
 	// Logging (from env only)
 	LogLevel string `env:"LOG_LEVEL"`
@@ -42,17 +72,149 @@ type Config struct {
 type Keyword struct {
 	Term      string `json:"term"`
 	TargetURL string `json:"target_url"`
+	Match     string `json:"match"` // domain, subdomain, exact, prefix or regex (default: domain, prefix for URLs with a path)
+
+	// Competitor domains ranked from the same results as TargetURL
+	Competitors []string `json:"competitors"`
+
+	// Search context; ranks are tracked separately for each combination
+	HL     string `json:"hl"`     // Interface language (e.g. "tr")
+	GL     string `json:"gl"`     // Result country (e.g. "TR")
+	Domain string `json:"domain"` // Google domain (e.g. "google.com.tr", default: google.com)
+	Device string `json:"device"` // Device profile: desktop (default), mobile or tablet
+
+	// Schedule in continuous mode
+	Group    string `json:"group"`    // Schedule group from schedule_groups
+	Schedule string `json:"schedule"` // Schedule spec; overrides the spec of the group
 }
 
-// SelectorConfig holds CSS selectors for web scraping
+// Locale returns the search context of the keyword
+func (k Keyword) Locale() serp.Locale {
+	return serp.Locale{HL: k.HL, GL: k.GL, Domain: k.Domain, Device: k.Device}
+}
+
+// SelectorConfig selects the selector profile and adds selectors in front
+// of its fallbacks. Every selector field takes a single selector or an
+// ordered list of selectors.
 type SelectorConfig struct {
-	SearchBox    string `json:"search_box"`
-	SearchButton string `json:"search_button"`
-	ResultItem   string `json:"result_item"`
-	ResultLink   string `json:"result_link"`
-	NextButton   string `json:"next_button"`
+	Profile         string            `json:"profile"` // Built-in profile or one from selector_profiles (default: google)
+	SearchBox       serp.SelectorList `json:"search_box"`
+	SearchButton    serp.SelectorList `json:"search_button"`
+	ResultItem      serp.SelectorList `json:"result_item"`
+	ResultLink      serp.SelectorList `json:"result_link"`
+	ResultTitle     serp.SelectorList `json:"result_title"`
+	ResultSnippet   serp.SelectorList `json:"result_snippet"`
+	NextButton      serp.SelectorList `json:"next_button"`
+	CaptchaFrame    serp.SelectorList `json:"captcha_frame"`
+	AdItem          serp.SelectorList `json:"ad_item"`
+	FeaturedSnippet serp.SelectorList `json:"featured_snippet"`
+	PeopleAlsoAsk   serp.SelectorList `json:"people_also_ask"`
+	LocalPackItem   serp.SelectorList `json:"local_pack_item"`
+	Sitelink        serp.SelectorList `json:"sitelink"`
+	BlockTitle      serp.SelectorList `json:"block_title"`
 }
 
+// ProviderConfig selects where rank-tracking results come from
+type ProviderConfig struct {
+	Type       string            `json:"type"`        // browser (default), fixture or http
+	FixtureDir string            `json:"fixture_dir"` // Directory with recorded SERP HTML (fixture)
+	Endpoint   string            `json:"endpoint"`    // JSON SERP API endpoint (http)
+	Headers    map[string]string `json:"headers"`     // Extra request headers, e.g. API keys (http)
+}
+
+// HistoryConfig controls the persistent rank history store
+type HistoryConfig struct {
+	Path          string `json:"path"`           // Database file (default: data/history.db)
+	RawDays       int    `json:"raw_days"`       // Days individual samples are kept before they are rolled up into daily buckets (0 = never roll up)
+	RetentionDays int    `json:"retention_days"` // Days of history to keep (0 = keep forever)
+}
+
+// ArtifactsConfig controls the debug artifacts saved for failed tasks
+type ArtifactsConfig struct {
+	Dir           string `json:"dir"`            // Directory with one subdirectory per failed task (default: data/artifacts)
+	MaxTasks      int    `json:"max_tasks"`      // Failed tasks to keep artifacts for (default: 100)
+	RetentionDays int    `json:"retention_days"` // Days artifacts are kept (0 = until max_tasks is reached)
+}
+
+// BrowserPoolConfig controls the long-lived browser processes tasks run in.
+// Each task gets a fresh browser context in one of them instead of its own
+// browser process.
+type BrowserPoolConfig struct {
+	Size    int `json:"size"`     // Browser processes kept running (0 = launch a browser per task)
+	MaxUses int `json:"max_uses"` // Tasks a process serves before it is restarted (default: 50)
+}
+
+// AlertConfig controls rank change detection and where alerts are sent.
+// Alerts are disabled unless at least one notifier is configured.
+type AlertConfig struct {
+	DropThreshold int   `json:"drop_threshold"` // Positions lost that raise an alert (default: 3)
+	GainThreshold int   `json:"gain_threshold"` // Positions won that raise an alert (default: 3)
+	TopThresholds []int `json:"top_thresholds"` // Alert when entering or leaving these top N (default: [3, 10])
+	Competitors   bool  `json:"competitors"`    // Also alert on competitor rank changes
+
+	Webhook WebhookAlertConfig `json:"webhook"`
+	Email   EmailAlertConfig   `json:"email"`
+	File    string             `json:"file"` // Append alerts as JSON lines to this file
+}
+
+// WebhookAlertConfig holds the webhook notifier settings
+type WebhookAlertConfig struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+}
+
+// EmailAlertConfig holds the SMTP email notifier settings
+type EmailAlertConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"` // default: 587
+	Username string   `json:"username"`
+	Password string   `json:"password" env:"ALERT_SMTP_PASSWORD"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// ScheduleConfig controls when keywords are checked in continuous mode
+type ScheduleConfig struct {
+	// "every 1h", "at 09:00,18:00", a cron expression such as "0 */6 * * *"
+	// or @hourly, @daily, @weekly (default: every interval seconds)
+	Spec       string `json:"spec"`
+	Jitter     int    `json:"jitter"`      // Random delay of up to this many seconds added to each run
+	QuietHours string `json:"quiet_hours"` // Local time window without checks, e.g. "22:00-07:00"
+}
+
+// KeywordSchedule returns the schedule of a keyword: the default schedule,
+// overridden by the settings of its group and then by its own spec
+func (c *Config) KeywordSchedule(k Keyword) ScheduleConfig {
+	sched := c.Schedule
+	if group, ok := c.ScheduleGroups[k.Group]; ok && k.Group != "" {
+		if group.Spec != "" {
+			sched.Spec = group.Spec
+		}
+		if group.Jitter != 0 {
+			sched.Jitter = group.Jitter
+		}
+		if group.QuietHours != "" {
+			sched.QuietHours = group.QuietHours
+		}
+	}
+	if k.Schedule != "" {
+		sched.Spec = k.Schedule
+	}
+	return sched
+}
+
+// Enabled reports whether any notifier is configured
+func (a AlertConfig) Enabled() bool {
+	return a.Webhook.URL != "" || a.Email.Host != "" || a.File != ""
+}
+
+// Provider types
+const (
+	ProviderBrowser = "browser"
+	ProviderFixture = "fixture"
+	ProviderHTTP    = "http"
+)
+
 // Load reads configuration from a JSON file and returns a Config instance.
 // It does NOT load environment variables - call LoadEnv() separately if needed.
 //
@@ -121,6 +283,12 @@ func (c *Config) LoadEnv() error {
 		}
 	}
 
+	if val := os.Getenv("MAX_PAGES"); val != "" {
+		if i, err := strconv.Atoi(val); err == nil {
+			c.MaxPages = i
+		}
+	}
+
 	if val := os.Getenv("MAX_RETRIES"); val != "" {
 		if i, err := strconv.Atoi(val); err == nil {
 			c.MaxRetries = i
@@ -133,6 +301,10 @@ func (c *Config) LoadEnv() error {
 		}
 	}
 
+	if val := os.Getenv("SEARCH_URL"); val != "" {
+		c.SearchURL = val
+	}
+
 	// Load env-only settings
 	if val := os.Getenv("LOG_LEVEL"); val != "" {
 		c.LogLevel = val
@@ -142,6 +314,10 @@ func (c *Config) LoadEnv() error {
 		c.LogFile = val
 	}
 
+	if val := os.Getenv("ALERT_SMTP_PASSWORD"); val != "" {
+		c.Alerts.Email.Password = val
+	}
+
 	// Validate after env override
 	if err := c.Validate(); err != nil {
 		return fmt.Errorf("invalid configuration after env override: %w", err)
@@ -150,6 +326,25 @@ func (c *Config) LoadEnv() error {
 	return nil
 }
 
+// validate checks a single keyword entry
+func (k Keyword) validate() error {
+	if k.Term == "" {
+		return fmt.Errorf("term cannot be empty")
+	}
+	if k.TargetURL == "" {
+		return fmt.Errorf("target_url cannot be empty")
+	}
+	if _, err := urlmatch.New(urlmatch.Mode(k.Match), k.TargetURL); err != nil {
+		return err
+	}
+	for j, competitor := range k.Competitors {
+		if _, err := urlmatch.New("", competitor); err != nil {
+			return fmt.Errorf("competitor[%d]: %w", j, err)
+		}
+	}
+	return k.Locale().Validate()
+}
+
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
 	// Validate Workers
@@ -171,11 +366,8 @@ func (c *Config) Validate() error {
 	}
 
 	for i, kw := range c.Keywords {
-		if kw.Term == "" {
-			return fmt.Errorf("keyword[%d]: term cannot be empty", i)
-		}
-		if kw.TargetURL == "" {
-			return fmt.Errorf("keyword[%d]: target_url cannot be empty", i)
+		if err := c.validateKeyword(kw); err != nil {
+			return fmt.Errorf("keyword[%d]: %w", i, err)
 		}
 	}
 
@@ -194,6 +386,11 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("search_timeout must be at least 1 second, got %d", c.SearchTimeout)
 	}
 
+	// Validate rank scan settings
+	if c.MaxPages < 0 {
+		return fmt.Errorf("max_pages must be non-negative, got %d", c.MaxPages)
+	}
+
 	// Validate Retry settings
 	if c.MaxRetries < 0 {
 		return fmt.Errorf("max_retries must be non-negative, got %d", c.MaxRetries)
@@ -203,13 +400,228 @@ func (c *Config) Validate() error {
 	}
 
 	// Validate Selectors
-	if c.Selectors.SearchBox == "" {
-		return fmt.Errorf("selectors.search_box cannot be empty")
+	if _, err := c.SelectorProfile(); err != nil {
+		return err
+	}
+
+	// Validate SearchURL
+	if c.SearchURL != "" {
+		if _, err := serp.ParseBaseURL(c.SearchURL); err != nil {
+			return fmt.Errorf("search_url: %w", err)
+		}
+	}
+
+	// Validate Provider
+	switch c.Provider.Type {
+	case "", ProviderBrowser:
+	case ProviderFixture:
+		if c.Provider.FixtureDir == "" {
+			return fmt.Errorf("provider.fixture_dir is required for the fixture provider")
+		}
+	case ProviderHTTP:
+		if c.Provider.Endpoint == "" {
+			return fmt.Errorf("provider.endpoint is required for the http provider")
+		}
+	default:
+		return fmt.Errorf("unknown provider type: %s", c.Provider.Type)
+	}
+
+	// Validate History
+	if err := c.History.validate(); err != nil {
+		return err
+	}
+
+	// Validate Artifacts
+	if err := c.Artifacts.validate(); err != nil {
+		return err
 	}
-	if c.Selectors.ResultItem == "" {
-		return fmt.Errorf("selectors.result_item cannot be empty")
+
+	// Validate BrowserPool
+	if err := c.BrowserPool.validate(); err != nil {
+		return err
+	}
+
+	// Validate Schedules
+	if err := c.validateSchedules(); err != nil {
+		return err
+	}
+
+	// Validate Alerts
+	return c.Alerts.validate()
+}
+
+// validateKeyword checks a keyword entry and its schedule settings
+func (c *Config) validateKeyword(kw Keyword) error {
+	if err := kw.validate(); err != nil {
+		return err
+	}
+	if _, ok := c.ScheduleGroups[kw.Group]; kw.Group != "" && !ok {
+		return fmt.Errorf("unknown schedule group: %s", kw.Group)
+	}
+	if kw.Schedule != "" {
+		if _, err := schedule.Parse(kw.Schedule); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
+// validateSchedules checks the default schedule and the schedule groups
+func (c *Config) validateSchedules() error {
+	if err := c.Schedule.validate("schedule"); err != nil {
+		return err
+	}
+	for name, group := range c.ScheduleGroups {
+		if err := group.validate("schedule_groups." + name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate checks the schedule spec, jitter and quiet hours; name is the
+// configuration path used in errors
+func (s ScheduleConfig) validate(name string) error {
+	if s.Spec != "" {
+		if _, err := schedule.Parse(s.Spec); err != nil {
+			return fmt.Errorf("%s.spec: %w", name, err)
+		}
+	}
+	if s.Jitter < 0 {
+		return fmt.Errorf("%s.jitter must be non-negative, got %d", name, s.Jitter)
+	}
+	if _, err := schedule.ParseQuietHours(s.QuietHours); err != nil {
+		return fmt.Errorf("%s.quiet_hours: %w", name, err)
+	}
+	return nil
+}
+
+// SelectorProfile returns the selector profile searches use: the profile
+// named by selectors.profile with the configured selectors in front of its own
+//
+// Example:
+//
+//	profile, err := cfg.SelectorProfile()
+//	searcher := serp.NewSearcherWithProfile(browser, log, profile)
+func (c *Config) SelectorProfile() (serp.SelectorProfile, error) {
+	name := c.Selectors.Profile
+	if name == "" {
+		name = serp.DefaultProfileName
+	}
+
+	profile, err := c.lookupSelectorProfile(name)
+	if err != nil {
+		return serp.SelectorProfile{}, err
+	}
+
+	sel := c.Selectors
+	profile = profile.Override(serp.SelectorProfile{
+		SearchBox:       sel.SearchBox,
+		SearchButton:    sel.SearchButton,
+		ResultItem:      sel.ResultItem,
+		ResultLink:      sel.ResultLink,
+		ResultTitle:     sel.ResultTitle,
+		ResultSnippet:   sel.ResultSnippet,
+		NextButton:      sel.NextButton,
+		CaptchaFrame:    sel.CaptchaFrame,
+		AdItem:          sel.AdItem,
+		FeaturedSnippet: sel.FeaturedSnippet,
+		PeopleAlsoAsk:   sel.PeopleAlsoAsk,
+		LocalPackItem:   sel.LocalPackItem,
+		Sitelink:        sel.Sitelink,
+		BlockTitle:      sel.BlockTitle,
+	})
+	if err := profile.Validate(); err != nil {
+		return serp.SelectorProfile{}, fmt.Errorf("selectors: %w", err)
+	}
+	return profile, nil
+}
+
+// lookupSelectorProfile finds a profile in selector_profiles or among the
+// built-in profiles. Profiles from the config take precedence; their empty
+// fields fall back to the default profile.
+func (c *Config) lookupSelectorProfile(name string) (serp.SelectorProfile, error) {
+	seen := make(map[string]bool)
+	var found *serp.SelectorProfile
+	for i := range c.SelectorProfiles {
+		profile := &c.SelectorProfiles[i]
+		if profile.Name == "" {
+			return serp.SelectorProfile{}, fmt.Errorf("selector_profiles[%d]: name cannot be empty", i)
+		}
+		if seen[profile.Name] {
+			return serp.SelectorProfile{}, fmt.Errorf("selector_profiles[%d]: duplicate profile %s", i, profile.Name)
+		}
+		seen[profile.Name] = true
+		if profile.Name == name {
+			found = profile
+		}
+	}
+	if found != nil {
+		return serp.DefaultProfile().Override(*found), nil
+	}
+
+	profile, ok := serp.LookupProfile(name)
+	if !ok {
+		return serp.SelectorProfile{}, fmt.Errorf("unknown selector profile: %s", name)
+	}
+	return profile, nil
+}
+
+// validate checks the history retention settings
+func (h HistoryConfig) validate() error {
+	if h.RawDays < 0 {
+		return fmt.Errorf("history.raw_days must be non-negative, got %d", h.RawDays)
+	}
+	if h.RetentionDays < 0 {
+		return fmt.Errorf("history.retention_days must be non-negative, got %d", h.RetentionDays)
+	}
+	if h.RetentionDays > 0 && h.RetentionDays < h.RawDays {
+		return fmt.Errorf("history.retention_days (%d) cannot be shorter than history.raw_days (%d)", h.RetentionDays, h.RawDays)
+	}
+	return nil
+}
+
+// validate checks the artifact retention settings
+func (a ArtifactsConfig) validate() error {
+	if a.MaxTasks < 0 {
+		return fmt.Errorf("artifacts.max_tasks must be non-negative, got %d", a.MaxTasks)
+	}
+	if a.RetentionDays < 0 {
+		return fmt.Errorf("artifacts.retention_days must be non-negative, got %d", a.RetentionDays)
+	}
+	return nil
+}
+
+// validate checks the browser pool limits
+func (b BrowserPoolConfig) validate() error {
+	if b.Size < 0 {
+		return fmt.Errorf("browser_pool.size must be non-negative, got %d", b.Size)
+	}
+	if b.MaxUses < 0 {
+		return fmt.Errorf("browser_pool.max_uses must be non-negative, got %d", b.MaxUses)
+	}
+	return nil
+}
+
+// validate checks the alert thresholds and notifier settings
+func (a AlertConfig) validate() error {
+	if a.DropThreshold < 0 {
+		return fmt.Errorf("alerts.drop_threshold must be non-negative, got %d", a.DropThreshold)
+	}
+	if a.GainThreshold < 0 {
+		return fmt.Errorf("alerts.gain_threshold must be non-negative, got %d", a.GainThreshold)
+	}
+	for i, n := range a.TopThresholds {
+		if n < 1 {
+			return fmt.Errorf("alerts.top_thresholds[%d] must be at least 1, got %d", i, n)
+		}
+	}
+	if a.Webhook.URL != "" && !strings.HasPrefix(a.Webhook.URL, "http://") && !strings.HasPrefix(a.Webhook.URL, "https://") {
+		return fmt.Errorf("alerts.webhook.url must start with http:// or https://")
+	}
+	if a.Email.Host != "" && (a.Email.From == "" || len(a.Email.To) == 0) {
+		return fmt.Errorf("alerts.email requires from and at least one to address")
+	}
 	return nil
 }
 
@@ -243,6 +655,9 @@ func (c *Config) SetDefaults() {
 	if c.SearchTimeout == 0 {
 		c.SearchTimeout = 15
 	}
+	if c.MaxPages == 0 {
+		c.MaxPages = 5
+	}
 	if c.MaxRetries == 0 {
 		c.MaxRetries = 3
 	}
@@ -258,4 +673,16 @@ func (c *Config) SetDefaults() {
 	if c.LogLevel == "" {
 		c.LogLevel = "info"
 	}
+	if c.History.Path == "" {
+		c.History.Path = "data/history.db"
+	}
+	if c.Artifacts.Dir == "" {
+		c.Artifacts.Dir = "data/artifacts"
+	}
+	if c.Artifacts.MaxTasks == 0 {
+		c.Artifacts.MaxTasks = 100
+	}
+	if c.BrowserPool.MaxUses == 0 {
+		c.BrowserPool.MaxUses = 50
+	}
 }
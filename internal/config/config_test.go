@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/omer/go-bot/internal/serp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -40,11 +41,11 @@ func createValidConfig() *Config {
 		MaxRetries:    3,
 		RetryDelay:    5,
 		Selectors: SelectorConfig{
-			SearchBox:    "input[name='q']",
-			SearchButton: "button[type='submit']",
-			ResultItem:   "div.result",
-			ResultLink:   "a",
-			NextButton:   "a.next",
+			SearchBox:    serp.SelectorList{"input[name='q']"},
+			SearchButton: serp.SelectorList{"button[type='submit']"},
+			ResultItem:   serp.SelectorList{"div.result"},
+			ResultLink:   serp.SelectorList{"a"},
+			NextButton:   serp.SelectorList{"a.next"},
 		},
 	}
 }
@@ -195,20 +196,359 @@ func TestValidate_NegativeRetryDelay(t *testing.T) {
 	assert.Contains(t, err.Error(), "retry_delay must be non-negative")
 }
 
-func TestValidate_EmptySearchBoxSelector(t *testing.T) {
+func TestValidate_KeywordMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		kw     Keyword
+		errMsg string
+	}{
+		{name: "default", kw: Keyword{Term: "go", TargetURL: "example.com"}},
+		{name: "subdomain", kw: Keyword{Term: "go", TargetURL: "*.example.com", Match: "subdomain"}},
+		{name: "prefix", kw: Keyword{Term: "go", TargetURL: "example.com/blog", Match: "prefix"}},
+		{name: "regex", kw: Keyword{Term: "go", TargetURL: `^https://example\.com/`, Match: "regex"}},
+		{name: "unknown_mode", kw: Keyword{Term: "go", TargetURL: "example.com", Match: "contains"}, errMsg: "unknown match mode"},
+		{name: "public_suffix", kw: Keyword{Term: "go", TargetURL: "co.uk"}, errMsg: "public suffix"},
+		{name: "bad_regex", kw: Keyword{Term: "go", TargetURL: "(", Match: "regex"}, errMsg: "invalid regex"},
+		{name: "competitors", kw: Keyword{Term: "go", TargetURL: "example.com", Competitors: []string{"rival.com", "*.other.io"}}},
+		{name: "bad_competitor", kw: Keyword{Term: "go", TargetURL: "example.com", Competitors: []string{"com"}}, errMsg: "competitor[0]"},
+		{name: "locale", kw: Keyword{Term: "go", TargetURL: "example.com", HL: "tr", GL: "TR", Domain: "google.com.tr", Device: "mobile"}},
+		{name: "bad_domain", kw: Keyword{Term: "go", TargetURL: "example.com", Domain: "bing.com"}, errMsg: "invalid domain"},
+		{name: "bad_device", kw: Keyword{Term: "go", TargetURL: "example.com", Device: "watch"}, errMsg: "unknown device profile"},
+		{name: "bad_country", kw: Keyword{Term: "go", TargetURL: "example.com", GL: "Turkey"}, errMsg: "invalid gl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := createValidConfig()
+			config.Keywords = []Keyword{tt.kw}
+			err := config.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "keyword[0]")
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestValidate_NegativeMaxPages(t *testing.T) {
 	config := createValidConfig()
-	config.Selectors.SearchBox = ""
+	config.MaxPages = -1
 	err := config.Validate()
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "selectors.search_box cannot be empty")
+	assert.Contains(t, err.Error(), "max_pages must be non-negative")
+}
+
+func TestValidate_Selectors(t *testing.T) {
+	tests := []struct {
+		name      string
+		selectors SelectorConfig
+		profiles  []serp.SelectorProfile
+		errMsg    string
+	}{
+		{name: "default_profile", selectors: SelectorConfig{}},
+		{name: "builtin_profile", selectors: SelectorConfig{Profile: "google", ResultItem: serp.SelectorList{"div.MjjYud"}}},
+		{name: "custom_profile", selectors: SelectorConfig{Profile: "google-eu"}, profiles: []serp.SelectorProfile{{Name: "google-eu", Version: 1}}},
+		{name: "unknown_profile", selectors: SelectorConfig{Profile: "bing"}, errMsg: "unknown selector profile: bing"},
+		{name: "unnamed_profile", profiles: []serp.SelectorProfile{{Version: 1}}, errMsg: "selector_profiles[0]: name cannot be empty"},
+		{name: "duplicate_profile", profiles: []serp.SelectorProfile{{Name: "a"}, {Name: "a"}}, errMsg: "duplicate profile a"},
+		{name: "negative_version", selectors: SelectorConfig{Profile: "a"}, profiles: []serp.SelectorProfile{{Name: "a", Version: -1}}, errMsg: "version must be non-negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := createValidConfig()
+			config.Selectors = tt.selectors
+			config.SelectorProfiles = tt.profiles
+			err := config.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
 }
 
-func TestValidate_EmptyResultItemSelector(t *testing.T) {
+func TestSelectorProfile(t *testing.T) {
 	config := createValidConfig()
-	config.Selectors.ResultItem = ""
-	err := config.Validate()
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "selectors.result_item cannot be empty")
+	config.Selectors = SelectorConfig{ResultItem: serp.SelectorList{"div.MjjYud"}}
+
+	profile, err := config.SelectorProfile()
+	require.NoError(t, err)
+	assert.Equal(t, serp.DefaultProfile().String(), profile.String())
+	assert.Equal(t, "div.MjjYud", profile.ResultItem.Primary())
+	assert.Contains(t, profile.ResultItem, "div.g", "built-in selectors stay as fallbacks")
+	assert.Equal(t, serp.DefaultProfile().SearchBox, profile.SearchBox)
+}
+
+func TestSelectorProfile_Custom(t *testing.T) {
+	config := createValidConfig()
+	config.Selectors = SelectorConfig{Profile: "google-eu"}
+	config.SelectorProfiles = []serp.SelectorProfile{{
+		Name:       "google-eu",
+		Version:    3,
+		NextButton: serp.SelectorList{"a#pnnext-eu"},
+	}}
+
+	profile, err := config.SelectorProfile()
+	require.NoError(t, err)
+	assert.Equal(t, "google-eu@3", profile.String())
+	assert.Equal(t, "a#pnnext-eu", profile.NextButton.Primary())
+	assert.Equal(t, serp.DefaultProfile().ResultItem, profile.ResultItem)
+}
+
+func TestLoad_SelectorLists(t *testing.T) {
+	data := `{
+		"workers": 1,
+		"page_timeout": 30,
+		"search_timeout": 15,
+		"keywords": [{"term": "golang", "target_url": "example.com"}],
+		"selectors": {
+			"search_box": "textarea[name='q']",
+			"result_item": ["div.g", "div.MjjYud"],
+			"captcha_frame": "iframe[src*='captcha']"
+		}
+	}`
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(data), 0644))
+
+	config, err := Load(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, serp.SelectorList{"textarea[name='q']"}, config.Selectors.SearchBox)
+	assert.Equal(t, serp.SelectorList{"div.g", "div.MjjYud"}, config.Selectors.ResultItem)
+	assert.Equal(t, serp.SelectorList{"iframe[src*='captcha']"}, config.Selectors.CaptchaFrame)
+}
+
+func TestValidate_History(t *testing.T) {
+	tests := []struct {
+		name    string
+		history HistoryConfig
+		errMsg  string
+	}{
+		{name: "default", history: HistoryConfig{}},
+		{name: "retention", history: HistoryConfig{RawDays: 30, RetentionDays: 365}},
+		{name: "raw_only", history: HistoryConfig{RawDays: 7}},
+		{name: "negative_raw", history: HistoryConfig{RawDays: -1}, errMsg: "history.raw_days must be non-negative"},
+		{name: "negative_retention", history: HistoryConfig{RetentionDays: -1}, errMsg: "history.retention_days must be non-negative"},
+		{name: "retention_shorter_than_raw", history: HistoryConfig{RawDays: 30, RetentionDays: 7}, errMsg: "cannot be shorter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := createValidConfig()
+			config.History = tt.history
+			err := config.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestValidate_Artifacts(t *testing.T) {
+	tests := []struct {
+		name      string
+		artifacts ArtifactsConfig
+		errMsg    string
+	}{
+		{name: "default", artifacts: ArtifactsConfig{}},
+		{name: "limits", artifacts: ArtifactsConfig{Dir: "data/artifacts", MaxTasks: 50, RetentionDays: 14}},
+		{name: "negative_max_tasks", artifacts: ArtifactsConfig{MaxTasks: -1}, errMsg: "artifacts.max_tasks must be non-negative"},
+		{name: "negative_retention", artifacts: ArtifactsConfig{RetentionDays: -1}, errMsg: "artifacts.retention_days must be non-negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := createValidConfig()
+			config.Artifacts = tt.artifacts
+			err := config.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestValidate_BrowserPool(t *testing.T) {
+	tests := []struct {
+		name   string
+		pool   BrowserPoolConfig
+		errMsg string
+	}{
+		{name: "disabled", pool: BrowserPoolConfig{}},
+		{name: "pooled", pool: BrowserPoolConfig{Size: 2, MaxUses: 50}},
+		{name: "negative_size", pool: BrowserPoolConfig{Size: -1}, errMsg: "browser_pool.size must be non-negative"},
+		{name: "negative_max_uses", pool: BrowserPoolConfig{Size: 2, MaxUses: -1}, errMsg: "browser_pool.max_uses must be non-negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := createValidConfig()
+			config.BrowserPool = tt.pool
+			err := config.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestValidate_SearchURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		searchURL string
+		errMsg    string
+	}{
+		{name: "google", searchURL: ""},
+		{name: "local", searchURL: "http://127.0.0.1:8080"},
+		{name: "with_path", searchURL: "https://serp.example.com/google"},
+		{name: "no_scheme", searchURL: "127.0.0.1:8080", errMsg: "search_url: invalid base URL"},
+		{name: "ftp", searchURL: "ftp://example.com", errMsg: "search_url: invalid base URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := createValidConfig()
+			config.SearchURL = tt.searchURL
+			err := config.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestValidate_Alerts(t *testing.T) {
+	tests := []struct {
+		name   string
+		alerts AlertConfig
+		errMsg string
+	}{
+		{name: "disabled", alerts: AlertConfig{}},
+		{name: "file", alerts: AlertConfig{File: "data/alerts.jsonl", TopThresholds: []int{1, 3, 10}}},
+		{name: "webhook", alerts: AlertConfig{Webhook: WebhookAlertConfig{URL: "https://hooks.example.com/serp"}}},
+		{name: "email", alerts: AlertConfig{Email: EmailAlertConfig{Host: "smtp.example.com", From: "bot@example.com", To: []string{"seo@example.com"}}}},
+		{name: "negative_drop", alerts: AlertConfig{DropThreshold: -1}, errMsg: "alerts.drop_threshold must be non-negative"},
+		{name: "negative_gain", alerts: AlertConfig{GainThreshold: -1}, errMsg: "alerts.gain_threshold must be non-negative"},
+		{name: "zero_top", alerts: AlertConfig{TopThresholds: []int{0}}, errMsg: "alerts.top_thresholds[0] must be at least 1"},
+		{name: "webhook_scheme", alerts: AlertConfig{Webhook: WebhookAlertConfig{URL: "hooks.example.com"}}, errMsg: "alerts.webhook.url must start with"},
+		{name: "email_without_to", alerts: AlertConfig{Email: EmailAlertConfig{Host: "smtp.example.com", From: "bot@example.com"}}, errMsg: "alerts.email requires"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := createValidConfig()
+			config.Alerts = tt.alerts
+			err := config.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestValidate_Schedules(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		errMsg string
+	}{
+		{name: "none", modify: func(c *Config) {}},
+		{name: "default", modify: func(c *Config) {
+			c.Schedule = ScheduleConfig{Spec: "@hourly", Jitter: 60, QuietHours: "22:00-07:00"}
+		}},
+		{name: "group", modify: func(c *Config) {
+			c.ScheduleGroups = map[string]ScheduleConfig{"weekly": {Spec: "0 6 * * 1"}}
+			c.Keywords[0].Group = "weekly"
+		}},
+		{name: "keyword", modify: func(c *Config) { c.Keywords[0].Schedule = "at 09:00,18:00" }},
+		{name: "invalid_spec", modify: func(c *Config) { c.Schedule.Spec = "sometimes" }, errMsg: "schedule.spec"},
+		{name: "negative_jitter", modify: func(c *Config) { c.Schedule.Jitter = -1 }, errMsg: "schedule.jitter must be non-negative"},
+		{name: "invalid_quiet_hours", modify: func(c *Config) { c.Schedule.QuietHours = "night" }, errMsg: "schedule.quiet_hours"},
+		{name: "invalid_group", modify: func(c *Config) {
+			c.ScheduleGroups = map[string]ScheduleConfig{"weekly": {Spec: "every week"}}
+		}, errMsg: "schedule_groups.weekly.spec"},
+		{name: "unknown_group", modify: func(c *Config) { c.Keywords[0].Group = "daily" }, errMsg: "unknown schedule group: daily"},
+		{name: "invalid_keyword_schedule", modify: func(c *Config) { c.Keywords[0].Schedule = "at noon" }, errMsg: "keyword[0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := createValidConfig()
+			tt.modify(config)
+			err := config.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestKeywordSchedule(t *testing.T) {
+	config := &Config{
+		Schedule: ScheduleConfig{Jitter: 60, QuietHours: "22:00-07:00"},
+		ScheduleGroups: map[string]ScheduleConfig{
+			"weekly": {Spec: "0 6 * * 1", Jitter: 1800},
+		},
+	}
+
+	assert.Equal(t, ScheduleConfig{Jitter: 60, QuietHours: "22:00-07:00"}, config.KeywordSchedule(Keyword{}))
+	assert.Equal(t, ScheduleConfig{Spec: "0 6 * * 1", Jitter: 1800, QuietHours: "22:00-07:00"},
+		config.KeywordSchedule(Keyword{Group: "weekly"}))
+	assert.Equal(t, ScheduleConfig{Spec: "every 2h", Jitter: 1800, QuietHours: "22:00-07:00"},
+		config.KeywordSchedule(Keyword{Group: "weekly", Schedule: "every 2h"}))
+}
+
+func TestValidate_Provider(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider ProviderConfig
+		errMsg   string
+	}{
+		{name: "default", provider: ProviderConfig{}},
+		{name: "browser", provider: ProviderConfig{Type: ProviderBrowser}},
+		{name: "fixture", provider: ProviderConfig{Type: ProviderFixture, FixtureDir: "testdata"}},
+		{name: "http", provider: ProviderConfig{Type: ProviderHTTP, Endpoint: "https://api.example.com"}},
+		{name: "fixture_without_dir", provider: ProviderConfig{Type: ProviderFixture}, errMsg: "provider.fixture_dir is required"},
+		{name: "http_without_endpoint", provider: ProviderConfig{Type: ProviderHTTP}, errMsg: "provider.endpoint is required"},
+		{name: "unknown", provider: ProviderConfig{Type: "carrier-pigeon"}, errMsg: "unknown provider type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := createValidConfig()
+			config.Provider = tt.provider
+			err := config.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
 }
 
 func TestLoadEnv_OverrideValues(t *testing.T) {
@@ -222,6 +562,7 @@ func TestLoadEnv_OverrideValues(t *testing.T) {
 	os.Setenv("SEARCH_TIMEOUT", "30")
 	os.Setenv("MAX_RETRIES", "5")
 	os.Setenv("RETRY_DELAY", "10")
+	os.Setenv("SEARCH_URL", "http://127.0.0.1:8080")
 	os.Setenv("LOG_LEVEL", "debug")
 	os.Setenv("LOG_FILE", "test.log")
 
@@ -233,6 +574,7 @@ func TestLoadEnv_OverrideValues(t *testing.T) {
 		os.Unsetenv("SEARCH_TIMEOUT")
 		os.Unsetenv("MAX_RETRIES")
 		os.Unsetenv("RETRY_DELAY")
+		os.Unsetenv("SEARCH_URL")
 		os.Unsetenv("LOG_LEVEL")
 		os.Unsetenv("LOG_FILE")
 	}()
@@ -247,6 +589,7 @@ func TestLoadEnv_OverrideValues(t *testing.T) {
 	assert.Equal(t, 30, config.SearchTimeout)
 	assert.Equal(t, 5, config.MaxRetries)
 	assert.Equal(t, 10, config.RetryDelay)
+	assert.Equal(t, "http://127.0.0.1:8080", config.SearchURL)
 	assert.Equal(t, "debug", config.LogLevel)
 	assert.Equal(t, "test.log", config.LogFile)
 }
@@ -326,8 +669,8 @@ func TestSetDefaults(t *testing.T) {
 			{Term: "test", TargetURL: "example.com"},
 		},
 		Selectors: SelectorConfig{
-			SearchBox:  "input",
-			ResultItem: "div",
+			SearchBox:  serp.SelectorList{"input"},
+			ResultItem: serp.SelectorList{"div"},
 		},
 	}
 
@@ -335,6 +678,12 @@ func TestSetDefaults(t *testing.T) {
 
 	assert.Equal(t, 30, config.PageTimeout)
 	assert.Equal(t, 15, config.SearchTimeout)
+	assert.Equal(t, 5, config.MaxPages)
+	assert.Equal(t, "data/history.db", config.History.Path)
+	assert.Equal(t, "data/artifacts", config.Artifacts.Dir)
+	assert.Equal(t, 100, config.Artifacts.MaxTasks)
+	assert.Equal(t, 50, config.BrowserPool.MaxUses)
+	assert.Equal(t, 0, config.BrowserPool.Size)
 	assert.Equal(t, 3, config.MaxRetries)
 	assert.Equal(t, 5, config.RetryDelay)
 	assert.Equal(t, 5, config.Workers)
@@ -355,8 +704,8 @@ func TestSetDefaults_DoesNotOverrideExisting(t *testing.T) {
 			{Term: "test", TargetURL: "example.com"},
 		},
 		Selectors: SelectorConfig{
-			SearchBox:  "input",
-			ResultItem: "div",
+			SearchBox:  serp.SelectorList{"input"},
+			ResultItem: serp.SelectorList{"div"},
 		},
 	}
 
@@ -2,6 +2,7 @@
 package errors
 
 import (
+	"context"
 	"errors"
 	"fmt"
 )
@@ -120,7 +121,9 @@ func Is(err error, errType ErrorType) bool {
 	return false
 }
 
-// IsRetryable determines if an error is retryable
+// IsRetryable determines if an error is retryable.
+// Unclassified errors are retryable only when they are context deadlines,
+// which count as timeouts.
 func IsRetryable(err error) bool {
 	var appErr *AppError
 	if errors.As(err, &appErr) {
@@ -133,7 +136,7 @@ func IsRetryable(err error) bool {
 			return false
 		}
 	}
-	return false
+	return errors.Is(err, context.DeadlineExceeded)
 }
 
 // GetType extracts the ErrorType from an error
@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
@@ -143,6 +144,16 @@ func TestIsRetryable(t *testing.T) {
 			err:       fmt.Errorf("standard error"),
 			retryable: false,
 		},
+		{
+			name:      "deadline exceeded",
+			err:       fmt.Errorf("search failed: %w", context.DeadlineExceeded),
+			retryable: true,
+		},
+		{
+			name:      "cancelled",
+			err:       context.Canceled,
+			retryable: false,
+		},
 	}
 
 	for _, tt := range tests {
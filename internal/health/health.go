@@ -16,10 +16,10 @@ import (
 
 // CheckResult represents the result of a health check
 type CheckResult struct {
-	Name    string                 // Name of the check
-	Passed  bool                   // Whether the check passed
-	Message string                 // Description/error message
-	Details map[string]interface{} // Additional details
+	Name    string                 `json:"name"`              // Name of the check
+	Passed  bool                   `json:"passed"`            // Whether the check passed
+	Message string                 `json:"message"`           // Description/error message
+	Details map[string]interface{} `json:"details,omitempty"` // Additional details
 }
 
 // HealthChecker performs system health checks
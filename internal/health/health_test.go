@@ -7,6 +7,7 @@ import (
 
 	"github.com/omer/go-bot/internal/config"
 	"github.com/omer/go-bot/internal/logger"
+	"github.com/omer/go-bot/internal/serp"
 )
 
 func TestNewHealthChecker(t *testing.T) {
@@ -106,11 +107,11 @@ func TestHealthChecker_CheckConfig(t *testing.T) {
 				MaxRetries:    3,
 				RetryDelay:    5,
 				Selectors: config.SelectorConfig{
-					SearchBox:    "input",
-					SearchButton: "button",
-					ResultItem:   "div",
-					ResultLink:   "a",
-					NextButton:   "next",
+					SearchBox:    serp.SelectorList{"input"},
+					SearchButton: serp.SelectorList{"button"},
+					ResultItem:   serp.SelectorList{"div"},
+					ResultLink:   serp.SelectorList{"a"},
+					NextButton:   serp.SelectorList{"next"},
 				},
 			},
 			shouldPass: true,
@@ -0,0 +1,281 @@
+// Package metrics collects Prometheus metrics about tasks, the worker queue,
+// scheduler cycles, browser launches, the browser pool, selector fallbacks
+// and tracked positions, and exposes them in the Prometheus text exposition format.
+//
+// A nil *Metrics is valid and records nothing, so components can hold an
+// optional *Metrics without checking it before every call.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	apperrors "github.com/omer/go-bot/internal/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/common/expfmt"
+)
+
+// namespace prefixes every metric name
+const namespace = "serp_bot"
+
+// Task statuses used as the status label
+const (
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+)
+
+// Metrics holds the application metrics and the registry they are exposed from
+type Metrics struct {
+	registry        *prometheus.Registry
+	tasks           *prometheus.CounterVec
+	taskDuration    *prometheus.HistogramVec
+	cycleDuration   *prometheus.HistogramVec
+	browserLaunches *prometheus.CounterVec
+	fallbacks       *prometheus.CounterVec
+	position        *prometheus.GaugeVec
+
+	mu          sync.RWMutex
+	queueDepth  func() int
+	browserPool func() BrowserPoolStats
+}
+
+// BrowserPoolStats holds the counters of the browser process pool
+type BrowserPoolStats struct {
+	Launches int // Processes launched
+	Recycles int // Processes retired after serving their maximum number of tasks
+	Crashes  int // Processes retired because they exited
+	Active   int // Browser contexts in use
+}
+
+// New creates the metrics and registers them, together with the Go runtime
+// and process collectors, in a new registry
+//
+// Example:
+//
+//	m := metrics.New()
+//	http.Handle("/metrics", m.Handler())
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		tasks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tasks_total",
+			Help:      "Finished tasks by type, status and error type.",
+		}, []string{"type", "status", "error_type"}),
+		taskDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "task_duration_seconds",
+			Help:      "Task execution time by type and status.",
+			Buckets:   []float64{0.5, 1, 2.5, 5, 10, 20, 30, 60, 120},
+		}, []string{"type", "status"}),
+		cycleDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "scheduler_cycle_duration_seconds",
+			Help:      "Duration of scheduler cycles by status.",
+			Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800},
+		}, []string{"status"}),
+		browserLaunches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "browser_launches_total",
+			Help:      "Task browser launches, new processes or pooled browser contexts, by status.",
+		}, []string{"status"}),
+		fallbacks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "selector_fallbacks_total",
+			Help:      "Tasks that found a page element only with a fallback selector, by selector profile and field.",
+		}, []string{"profile", "field"}),
+		position: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "keyword_position",
+			Help:      "Latest tracked organic position per keyword and domain (0 = not in the scanned results).",
+		}, []string{"keyword", "target", "locale", "competitor"}),
+	}
+
+	queueDepth := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "queue_depth",
+		Help:      "Tasks waiting in the worker pool queue.",
+	}, func() float64 {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		if m.queueDepth == nil {
+			return 0
+		}
+		return float64(m.queueDepth())
+	})
+
+	poolStat := func(value func(BrowserPoolStats) int) func() float64 {
+		return func() float64 {
+			m.mu.RLock()
+			defer m.mu.RUnlock()
+			if m.browserPool == nil {
+				return 0
+			}
+			return float64(value(m.browserPool()))
+		}
+	}
+	poolLaunches := prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "browser_pool_launches_total",
+		Help:      "Browser processes launched by the browser pool.",
+	}, poolStat(func(s BrowserPoolStats) int { return s.Launches }))
+	poolRecycles := prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace:   namespace,
+		Name:        "browser_pool_recycles_total",
+		Help:        "Browser processes retired by the browser pool, by reason.",
+		ConstLabels: prometheus.Labels{"reason": "max_uses"},
+	}, poolStat(func(s BrowserPoolStats) int { return s.Recycles }))
+	poolCrashes := prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace:   namespace,
+		Name:        "browser_pool_recycles_total",
+		Help:        "Browser processes retired by the browser pool, by reason.",
+		ConstLabels: prometheus.Labels{"reason": "crash"},
+	}, poolStat(func(s BrowserPoolStats) int { return s.Crashes }))
+	poolActive := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "browser_pool_active_contexts",
+		Help:      "Browser contexts of the browser pool in use by tasks.",
+	}, poolStat(func(s BrowserPoolStats) int { return s.Active }))
+
+	m.registry.MustRegister(
+		m.tasks,
+		m.taskDuration,
+		m.cycleDuration,
+		m.browserLaunches,
+		m.fallbacks,
+		m.position,
+		queueDepth,
+		poolLaunches,
+		poolRecycles,
+		poolCrashes,
+		poolActive,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m
+}
+
+// Registry returns the registry the metrics are registered in
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler returns an HTTP handler that serves the metrics
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// WriteText writes the current metrics in the text exposition format,
+// e.g. for the node exporter textfile collector
+func (m *Metrics) WriteText(w io.Writer) error {
+	families, err := m.registry.Gather()
+	if err != nil {
+		return fmt.Errorf("failed to gather metrics: %w", err)
+	}
+
+	encoder := expfmt.NewEncoder(w, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for _, family := range families {
+		if err := encoder.Encode(family); err != nil {
+			return fmt.Errorf("failed to encode metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+// ObserveTask records a finished task; err is nil for successful tasks
+func (m *Metrics) ObserveTask(taskType string, err error, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	status, errorType := StatusSuccess, ""
+	if err != nil {
+		status, errorType = StatusFailure, ErrorType(err).String()
+	}
+	m.tasks.WithLabelValues(taskType, status, errorType).Inc()
+	m.taskDuration.WithLabelValues(taskType, status).Observe(duration.Seconds())
+}
+
+// ObserveCycle records a finished scheduler cycle
+func (m *Metrics) ObserveCycle(err error, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	status := StatusSuccess
+	if err != nil {
+		status = StatusFailure
+	}
+	m.cycleDuration.WithLabelValues(status).Observe(duration.Seconds())
+}
+
+// BrowserLaunched records a browser launch; err is the launch error, if any
+func (m *Metrics) BrowserLaunched(err error) {
+	if m == nil {
+		return
+	}
+
+	status := StatusSuccess
+	if err != nil {
+		status = StatusFailure
+	}
+	m.browserLaunches.WithLabelValues(status).Inc()
+}
+
+// SelectorFallback records a task that found a page element only with a
+// fallback selector because the primary selector of field stopped matching
+func (m *Metrics) SelectorFallback(profile, field string) {
+	if m == nil {
+		return
+	}
+	m.fallbacks.WithLabelValues(profile, field).Inc()
+}
+
+// SetPosition records the latest position of a target (0 = not in the scanned results)
+func (m *Metrics) SetPosition(keyword, target, locale string, competitor bool, position int) {
+	if m == nil {
+		return
+	}
+	m.position.WithLabelValues(keyword, target, locale, strconv.FormatBool(competitor)).Set(float64(position))
+}
+
+// WatchQueue sets the function that reports the queue depth
+func (m *Metrics) WatchQueue(depth func() int) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queueDepth = depth
+}
+
+// WatchBrowserPool sets the function that reports the browser pool counters
+func (m *Metrics) WatchBrowserPool(stats func() BrowserPoolStats) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.browserPool = stats
+}
+
+// ErrorType classifies an error for the error_type label.
+// Errors without an AppError type that hit a deadline count as timeouts.
+func ErrorType(err error) apperrors.ErrorType {
+	errType := apperrors.GetType(err)
+	if errType == apperrors.ErrorTypeUnknown && errors.Is(err, context.DeadlineExceeded) {
+		return apperrors.ErrorTypeTimeout
+	}
+	return errType
+}
@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apperrors "github.com/omer/go-bot/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ===== Helper functions =====
+
+// text returns the metrics in the text exposition format
+func text(t *testing.T, m *Metrics) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, m.WriteText(&buf))
+	return buf.String()
+}
+
+// ===== Metrics tests =====
+
+func TestObserveTask(t *testing.T) {
+	m := New()
+
+	m.ObserveTask("rank_check", nil, 2*time.Second)
+	m.ObserveTask("rank_check", nil, 3*time.Second)
+	m.ObserveTask("rank_check", apperrors.NewProxyError("connection refused", nil), time.Second)
+	m.ObserveTask("search", fmt.Errorf("scan: %w", context.DeadlineExceeded), 30*time.Second)
+
+	out := text(t, m)
+	assert.Contains(t, out, `serp_bot_tasks_total{error_type="",status="success",type="rank_check"} 2`)
+	assert.Contains(t, out, `serp_bot_tasks_total{error_type="proxy",status="failure",type="rank_check"} 1`)
+	assert.Contains(t, out, `serp_bot_tasks_total{error_type="timeout",status="failure",type="search"} 1`)
+	assert.Contains(t, out, `serp_bot_task_duration_seconds_count{status="success",type="rank_check"} 2`)
+	assert.Contains(t, out, `serp_bot_task_duration_seconds_sum{status="success",type="rank_check"} 5`)
+	assert.Contains(t, out, `serp_bot_task_duration_seconds_bucket{status="success",type="rank_check",le="2.5"} 1`)
+}
+
+func TestObserveCycleAndBrowserLaunches(t *testing.T) {
+	m := New()
+
+	m.ObserveCycle(nil, 40*time.Second)
+	m.ObserveCycle(fmt.Errorf("no tasks created"), time.Second)
+	m.BrowserLaunched(nil)
+	m.BrowserLaunched(nil)
+	m.BrowserLaunched(fmt.Errorf("chrome not found"))
+
+	out := text(t, m)
+	assert.Contains(t, out, `serp_bot_scheduler_cycle_duration_seconds_count{status="success"} 1`)
+	assert.Contains(t, out, `serp_bot_scheduler_cycle_duration_seconds_count{status="failure"} 1`)
+	assert.Contains(t, out, `serp_bot_browser_launches_total{status="success"} 2`)
+	assert.Contains(t, out, `serp_bot_browser_launches_total{status="failure"} 1`)
+}
+
+func TestSelectorFallback(t *testing.T) {
+	m := New()
+
+	m.SelectorFallback("google@2", "result_item")
+	m.SelectorFallback("google@2", "result_item")
+	m.SelectorFallback("google@2", "next_button")
+
+	out := text(t, m)
+	assert.Contains(t, out, `serp_bot_selector_fallbacks_total{field="result_item",profile="google@2"} 2`)
+	assert.Contains(t, out, `serp_bot_selector_fallbacks_total{field="next_button",profile="google@2"} 1`)
+}
+
+func TestSetPosition(t *testing.T) {
+	m := New()
+
+	m.SetPosition("golang", "example.com", "", false, 7)
+	m.SetPosition("golang", "example.com", "", false, 4)
+	m.SetPosition("golang", "rival.com", "hl=tr", true, 0)
+
+	out := text(t, m)
+	assert.Contains(t, out, `serp_bot_keyword_position{competitor="false",keyword="golang",locale="",target="example.com"} 4`)
+	assert.Contains(t, out, `serp_bot_keyword_position{competitor="true",keyword="golang",locale="hl=tr",target="rival.com"} 0`)
+}
+
+func TestWatchQueue(t *testing.T) {
+	m := New()
+	assert.Contains(t, text(t, m), "serp_bot_queue_depth 0")
+
+	depth := 3
+	m.WatchQueue(func() int { return depth })
+	assert.Contains(t, text(t, m), "serp_bot_queue_depth 3")
+
+	depth = 1
+	assert.Contains(t, text(t, m), "serp_bot_queue_depth 1")
+}
+
+func TestWatchBrowserPool(t *testing.T) {
+	m := New()
+	assert.Contains(t, text(t, m), "serp_bot_browser_pool_launches_total 0")
+
+	m.WatchBrowserPool(func() BrowserPoolStats {
+		return BrowserPoolStats{Launches: 3, Recycles: 1, Crashes: 1, Active: 2}
+	})
+
+	out := text(t, m)
+	assert.Contains(t, out, "serp_bot_browser_pool_launches_total 3")
+	assert.Contains(t, out, `serp_bot_browser_pool_recycles_total{reason="max_uses"} 1`)
+	assert.Contains(t, out, `serp_bot_browser_pool_recycles_total{reason="crash"} 1`)
+	assert.Contains(t, out, "serp_bot_browser_pool_active_contexts 2")
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+
+	// None of these may panic
+	m.ObserveTask("search", nil, time.Second)
+	m.ObserveCycle(nil, time.Second)
+	m.BrowserLaunched(nil)
+	m.SelectorFallback("google@2", "result_item")
+	m.SetPosition("golang", "example.com", "", false, 1)
+	m.WatchQueue(func() int { return 0 })
+	m.WatchBrowserPool(func() BrowserPoolStats { return BrowserPoolStats{} })
+}
+
+func TestHandler(t *testing.T) {
+	m := New()
+	m.ObserveTask("search", nil, time.Second)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, rec.Body.String(), "# TYPE serp_bot_tasks_total counter")
+	assert.Contains(t, rec.Body.String(), "go_goroutines")
+}
+
+func TestErrorType(t *testing.T) {
+	assert.Equal(t, apperrors.ErrorTypeCaptcha, ErrorType(apperrors.NewCaptchaError("captcha")))
+	assert.Equal(t, apperrors.ErrorTypeTimeout, ErrorType(context.DeadlineExceeded))
+	assert.Equal(t, apperrors.ErrorTypeUnknown, ErrorType(fmt.Errorf("boom")))
+}
@@ -0,0 +1,74 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvHeader is the header row written by WriteCSV
+var csvHeader = []string{
+	"keyword", "target_url", "locale", "period_start",
+	"checks", "ranked", "not_in_top", "failed", "success_rate",
+	"avg_position", "best_position", "worst_position", "last_position",
+}
+
+// WriteCSV writes one row per series and time bucket, i.e. the position
+// table of every keyword
+func WriteCSV(w io.Writer, report *Report) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, series := range report.Series {
+		for _, bucket := range series.Buckets {
+			successRate := 0.0
+			if bucket.Samples > 0 {
+				successRate = float64(bucket.Samples-bucket.Failed) / float64(bucket.Samples) * 100
+			}
+			row := []string{
+				series.Keyword,
+				series.TargetURL,
+				series.Locale,
+				bucket.Start.Format(time.RFC3339),
+				strconv.Itoa(bucket.Samples),
+				strconv.Itoa(bucket.Ranked),
+				strconv.Itoa(bucket.NotInTop),
+				strconv.Itoa(bucket.Failed),
+				strconv.FormatFloat(successRate, 'f', 1, 64),
+				positionCell(bucket.Ranked, strconv.FormatFloat(bucket.AvgPosition, 'f', 2, 64)),
+				positionCell(bucket.Ranked, strconv.Itoa(bucket.BestPosition)),
+				positionCell(bucket.Ranked, strconv.Itoa(bucket.WorstPosition)),
+				positionCell(bucket.Ranked, strconv.Itoa(bucket.LastPosition)),
+			}
+			if err := writer.Write(row); err != nil {
+				return fmt.Errorf("failed to write CSV row: %w", err)
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// positionCell returns value, or an empty cell when nothing was ranked
+func positionCell(ranked int, value string) string {
+	if ranked == 0 {
+		return ""
+	}
+	return value
+}
+
+// WriteJSON writes the report as indented JSON
+func WriteJSON(w io.Writer, report *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	return nil
+}
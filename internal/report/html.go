@@ -0,0 +1,201 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/omer/go-bot/internal/stats"
+)
+
+// Chart dimensions in SVG user units
+const (
+	chartWidth   = 640
+	chartHeight  = 180
+	chartPadding = 32
+)
+
+// chart is the inline SVG position chart of one series.
+// Position 1 is drawn at the top; buckets without a rank leave a gap.
+type chart struct {
+	Width, Height int
+	Path          string       // SVG path of the average position
+	Points        []chartPoint // Markers for the ranked buckets
+	Ticks         []chartTick  // Horizontal grid lines
+	Left, Right   float64      // Plot area edges
+	AxisX, AxisY  float64      // Position of the y axis labels and the x axis labels
+	FirstLabel    string
+	LastLabel     string
+}
+
+type chartPoint struct {
+	X, Y  float64
+	Title string
+}
+
+type chartTick struct {
+	Y     float64
+	Label int
+}
+
+// newChart lays out the buckets of a series
+func newChart(series SeriesReport, granularity stats.Granularity) chart {
+	c := chart{
+		Width:  chartWidth,
+		Height: chartHeight,
+		Left:   chartPadding,
+		Right:  chartWidth - chartPadding/2,
+		AxisX:  chartPadding - 6,
+		AxisY:  chartHeight - 8,
+	}
+	buckets := series.Buckets
+	if len(buckets) == 0 {
+		return c
+	}
+	c.FirstLabel = bucketLabel(buckets[0].Start, granularity)
+	c.LastLabel = bucketLabel(buckets[len(buckets)-1].Start, granularity)
+
+	// Scale the y axis to the worst position, at least the top 10
+	maxPosition := math.Max(10, float64(series.WorstPosition))
+	top, bottom := float64(chartPadding/2), float64(chartHeight-chartPadding)
+	y := func(position float64) float64 {
+		return top + (position-1)/(maxPosition-1)*(bottom-top)
+	}
+	step := 0.0
+	if len(buckets) > 1 {
+		step = (c.Right - c.Left) / float64(len(buckets)-1)
+	}
+
+	for _, tick := range []int{1, int(math.Round(maxPosition / 2)), int(maxPosition)} {
+		c.Ticks = append(c.Ticks, chartTick{Y: y(float64(tick)), Label: tick})
+	}
+
+	var path strings.Builder
+	penDown := false
+	for i, bucket := range buckets {
+		if bucket.Ranked == 0 {
+			penDown = false
+			continue
+		}
+		point := chartPoint{
+			X:     c.Left + step*float64(i),
+			Y:     y(bucket.AvgPosition),
+			Title: fmt.Sprintf("%s: %.1f", bucketLabel(bucket.Start, granularity), bucket.AvgPosition),
+		}
+		command := "L"
+		if !penDown {
+			command = "M"
+		}
+		fmt.Fprintf(&path, "%s%.1f %.1f ", command, point.X, point.Y)
+		penDown = true
+		c.Points = append(c.Points, point)
+	}
+	c.Path = strings.TrimSpace(path.String())
+
+	return c
+}
+
+// bucketLabel formats a bucket start for display
+func bucketLabel(t time.Time, granularity stats.Granularity) string {
+	if granularity == stats.GranularityHour {
+		return t.Format("2006-01-02 15:04")
+	}
+	return t.Format("2006-01-02")
+}
+
+// htmlSeries is a series with its chart
+type htmlSeries struct {
+	SeriesReport
+	Chart chart
+}
+
+// htmlData is the data passed to htmlTemplate
+type htmlData struct {
+	Report *Report
+	Series []htmlSeries
+}
+
+// WriteHTML writes a self-contained HTML page with a summary table and a
+// position chart and table per series. It loads no external resources, so it
+// can be attached to an email as is.
+func WriteHTML(w io.Writer, report *Report) error {
+	data := htmlData{Report: report}
+	for _, series := range report.Series {
+		data.Series = append(data.Series, htmlSeries{
+			SeriesReport: series,
+			Chart:        newChart(series, report.Granularity),
+		})
+	}
+
+	if err := htmlTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
+
+// formatPosition formats a position, or "-" when there is none
+func formatPosition(position int) string {
+	if position == 0 {
+		return "-"
+	}
+	return fmt.Sprint(position)
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return "beginning"
+		}
+		return t.Format("2006-01-02")
+	},
+	"label":    bucketLabel,
+	"position": formatPosition,
+	"percent":  func(f float64) string { return fmt.Sprintf("%.1f%%", f) },
+	"signed":   func(f float64) string { return fmt.Sprintf("%+.1f", f) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Rank report {{date .Report.From}} to {{date .Report.To}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #222; margin: 2em auto; max-width: 720px; }
+h1 { font-size: 1.5em; margin-bottom: 0.2em; }
+h2 { font-size: 1.15em; margin: 2em 0 0.2em; }
+.meta { color: #666; margin-top: 0; }
+table { border-collapse: collapse; width: 100%; margin: 0.8em 0; font-size: 0.9em; }
+th, td { border-bottom: 1px solid #ddd; padding: 4px 8px; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+.up { color: #1a7f37; } .down, .lost { color: #cf222e; } .flat, .not_ranked { color: #666; }
+svg { display: block; width: 100%; height: auto; }
+</style>
+</head>
+<body>
+<h1>Rank report</h1>
+<p class="meta">{{date .Report.From}} to {{date .Report.To}} by {{.Report.Granularity}}{{if .Report.Keyword}} · keyword "{{.Report.Keyword}}"{{end}}{{if .Report.Domain}} · domain {{.Report.Domain}}{{end}} · generated {{.Report.Generated.Format "2006-01-02 15:04 MST"}}</p>
+{{if not .Series}}<p>No rank history matches this report.</p>{{else}}
+<table>
+<tr><th>Keyword</th><th>Target</th><th>Last</th><th>Avg</th><th>Best</th><th>Change</th><th>Success</th></tr>
+{{range .Series}}<tr><td>{{.Keyword}}{{if .Locale}} <small>({{.Locale}})</small>{{end}}</td><td>{{.TargetURL}}</td><td>{{position .LastPosition}}</td><td>{{if .Ranked}}{{printf "%.1f" .AvgPosition}}{{else}}-{{end}}</td><td>{{position .BestPosition}}</td><td class="{{.Trend}}">{{if .Ranked}}{{signed .Change}} {{end}}{{.Trend}}</td><td>{{percent .SuccessRate}}</td></tr>
+{{end}}</table>
+{{range .Series}}{{$c := .Chart}}
+<h2>{{.Keyword}} · {{.TargetURL}}{{if .Locale}} <small>({{.Locale}})</small>{{end}}</h2>
+<p class="meta">{{.Checks}} checks · ranked {{.Ranked}} · not in top {{.NotInTop}} · failed {{.Failed}} · {{percent .SuccessRate}} success</p>
+<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 {{$c.Width}} {{$c.Height}}" role="img" aria-label="Average position over time">
+{{range $c.Ticks}}<line x1="{{$c.Left}}" x2="{{$c.Right}}" y1="{{printf "%.1f" .Y}}" y2="{{printf "%.1f" .Y}}" stroke="#eee"/>
+<text x="{{$c.AxisX}}" y="{{printf "%.1f" .Y}}" font-size="11" fill="#666" text-anchor="end" dominant-baseline="middle">{{.Label}}</text>
+{{end}}{{if $c.Path}}<path d="{{$c.Path}}" fill="none" stroke="#0969da" stroke-width="2"/>
+{{end}}{{range $c.Points}}<circle cx="{{printf "%.1f" .X}}" cy="{{printf "%.1f" .Y}}" r="3" fill="#0969da"><title>{{.Title}}</title></circle>
+{{end}}<text x="{{$c.Left}}" y="{{$c.AxisY}}" font-size="11" fill="#666">{{$c.FirstLabel}}</text>
+<text x="{{$c.Right}}" y="{{$c.AxisY}}" font-size="11" fill="#666" text-anchor="end">{{$c.LastLabel}}</text>
+</svg>
+<table>
+<tr><th>Period</th><th>Checks</th><th>Avg</th><th>Best</th><th>Worst</th><th>Last</th><th>Not in top</th><th>Failed</th></tr>
+{{range .Buckets}}<tr><td>{{label .Start $.Report.Granularity}}</td><td>{{.Samples}}</td><td>{{if .Ranked}}{{printf "%.1f" .AvgPosition}}{{else}}-{{end}}</td><td>{{position .BestPosition}}</td><td>{{position .WorstPosition}}</td><td>{{position .LastPosition}}</td><td>{{.NotInTop}}</td><td>{{.Failed}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,248 @@
+// Package report builds rank reports from the rank history store and renders
+// them as CSV, JSON or a self-contained HTML page.
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/omer/go-bot/internal/stats"
+	"github.com/omer/go-bot/internal/urlmatch"
+)
+
+// Format is an output format of a report
+type Format string
+
+const (
+	// FormatCSV writes one row per keyword and time bucket
+	FormatCSV Format = "csv"
+	// FormatJSON writes the whole report as JSON
+	FormatJSON Format = "json"
+	// FormatHTML writes a static HTML page with inline SVG charts
+	FormatHTML Format = "html"
+)
+
+// ParseFormat converts a command line value into a Format
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case FormatCSV, FormatJSON, FormatHTML:
+		return f, nil
+	case "":
+		return FormatHTML, nil
+	default:
+		return "", fmt.Errorf("unknown report format: %s", s)
+	}
+}
+
+// Trend summarizes how a position moved over the report period
+type Trend string
+
+const (
+	// TrendUp means the position improved
+	TrendUp Trend = "up"
+	// TrendDown means the position got worse
+	TrendDown Trend = "down"
+	// TrendFlat means the position moved less than one place
+	TrendFlat Trend = "flat"
+	// TrendLost means the target was ranked in the period but not in the latest results
+	TrendLost Trend = "lost"
+	// TrendNotRanked means the target was never ranked in the period
+	TrendNotRanked Trend = "not_ranked"
+)
+
+// Filter selects the series and time range of a report
+type Filter struct {
+	Keyword     string            // Only this keyword (empty = all)
+	Domain      string            // Only targets on this domain or its subdomains, "www." is ignored (empty = all)
+	From        time.Time         // Inclusive start (zero = beginning of history)
+	To          time.Time         // Inclusive end (zero = now)
+	Granularity stats.Granularity // Bucket width (default: day)
+}
+
+// Report is the rank report of every series selected by a filter
+type Report struct {
+	Generated   time.Time         `json:"generated"`
+	From        time.Time         `json:"from"`
+	To          time.Time         `json:"to"`
+	Granularity stats.Granularity `json:"granularity"`
+	Keyword     string            `json:"keyword,omitempty"`
+	Domain      string            `json:"domain,omitempty"`
+	Series      []SeriesReport    `json:"series"`
+}
+
+// SeriesReport summarizes the ranks of one keyword/target/locale series
+type SeriesReport struct {
+	stats.SeriesKey
+	Checks        int                `json:"checks"`
+	Ranked        int                `json:"ranked"`
+	NotInTop      int                `json:"not_in_top"`
+	Failed        int                `json:"failed"`
+	SuccessRate   float64            `json:"success_rate"` // Percentage of checks that did not fail
+	AvgPosition   float64            `json:"avg_position"` // Average over ranked checks (0 if never ranked)
+	BestPosition  int                `json:"best_position"`
+	WorstPosition int                `json:"worst_position"`
+	LastPosition  int                `json:"last_position"` // Latest ranked position (0 if never ranked)
+	Change        float64            `json:"change"`        // Average position of the first minus the last ranked bucket (positive = improved)
+	Trend         Trend              `json:"trend"`
+	Buckets       []stats.RankBucket `json:"buckets"`
+}
+
+// Build queries the store for every series that matches the filter.
+// Series without checks in the time range are left out.
+//
+// Example:
+//
+//	rep, err := report.Build(store, report.Filter{
+//	    Domain: "example.com",
+//	    From:   time.Now().AddDate(0, 0, -30),
+//	}, time.Now())
+func Build(store stats.Store, filter Filter, now time.Time) (*Report, error) {
+	if filter.Granularity == "" {
+		filter.Granularity = stats.GranularityDay
+	}
+	if filter.To.IsZero() {
+		filter.To = now
+	}
+
+	var domain *urlmatch.Matcher
+	if filter.Domain != "" {
+		matcher, err := urlmatch.New(urlmatch.ModeSubdomain, strings.TrimPrefix(filter.Domain, "www."))
+		if err != nil {
+			return nil, fmt.Errorf("invalid domain filter: %w", err)
+		}
+		domain = matcher
+	}
+
+	keys, err := store.Series()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list series: %w", err)
+	}
+
+	report := &Report{
+		Generated:   now,
+		From:        filter.From,
+		To:          filter.To,
+		Granularity: filter.Granularity,
+		Keyword:     filter.Keyword,
+		Domain:      filter.Domain,
+		Series:      []SeriesReport{},
+	}
+	for _, key := range keys {
+		if filter.Keyword != "" && !strings.EqualFold(key.Keyword, filter.Keyword) {
+			continue
+		}
+		if domain != nil && !domain.Match(key.TargetURL) {
+			continue
+		}
+
+		buckets, err := store.Query(stats.RankQuery{
+			Keyword:     key.Keyword,
+			TargetURL:   key.TargetURL,
+			Locale:      key.Locale,
+			From:        filter.From,
+			To:          filter.To,
+			Granularity: filter.Granularity,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s: %w", key, err)
+		}
+		if len(buckets) == 0 {
+			continue
+		}
+		report.Series = append(report.Series, summarize(key, buckets))
+	}
+
+	sort.Slice(report.Series, func(i, j int) bool {
+		a, b := report.Series[i], report.Series[j]
+		if a.Keyword != b.Keyword {
+			return a.Keyword < b.Keyword
+		}
+		if a.TargetURL != b.TargetURL {
+			return a.TargetURL < b.TargetURL
+		}
+		return a.Locale < b.Locale
+	})
+
+	return report, nil
+}
+
+// summarize folds the buckets of one series into a SeriesReport
+func summarize(key stats.SeriesKey, buckets []stats.RankBucket) SeriesReport {
+	series := SeriesReport{SeriesKey: key, Buckets: buckets}
+
+	var positionSum float64
+	var first, last *stats.RankBucket
+	for i := range buckets {
+		bucket := &buckets[i]
+		series.Checks += bucket.Samples
+		series.NotInTop += bucket.NotInTop
+		series.Failed += bucket.Failed
+		if bucket.Ranked == 0 {
+			continue
+		}
+
+		series.Ranked += bucket.Ranked
+		positionSum += bucket.AvgPosition * float64(bucket.Ranked)
+		if series.BestPosition == 0 || bucket.BestPosition < series.BestPosition {
+			series.BestPosition = bucket.BestPosition
+		}
+		if bucket.WorstPosition > series.WorstPosition {
+			series.WorstPosition = bucket.WorstPosition
+		}
+		if first == nil {
+			first = bucket
+		}
+		last = bucket
+	}
+
+	if series.Checks > 0 {
+		series.SuccessRate = float64(series.Checks-series.Failed) / float64(series.Checks) * 100
+	}
+	if series.Ranked > 0 {
+		series.AvgPosition = positionSum / float64(series.Ranked)
+		series.LastPosition = last.LastPosition
+		series.Change = first.AvgPosition - last.AvgPosition
+	}
+	series.Trend = trend(series, buckets[len(buckets)-1])
+
+	return series
+}
+
+// trend classifies the movement of a series; latest is its last bucket
+func trend(series SeriesReport, latest stats.RankBucket) Trend {
+	switch {
+	case series.Ranked == 0:
+		return TrendNotRanked
+	case latest.Ranked == 0 && latest.NotInTop > 0:
+		return TrendLost
+	case series.Change >= 1:
+		return TrendUp
+	case series.Change <= -1:
+		return TrendDown
+	default:
+		return TrendFlat
+	}
+}
+
+// Write renders the report in the given format
+//
+// Example:
+//
+//	if err := report.Write(os.Stdout, rep, report.FormatCSV); err != nil {
+//	    return err
+//	}
+func Write(w io.Writer, report *Report, format Format) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, report)
+	case FormatJSON:
+		return WriteJSON(w, report)
+	case FormatHTML:
+		return WriteHTML(w, report)
+	default:
+		return fmt.Errorf("unknown report format: %s", format)
+	}
+}
@@ -0,0 +1,220 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/omer/go-bot/internal/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ===== Helper functions =====
+
+var day0 = time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+
+// rankCheck builds a rank check at position (0 = not in top, -1 = failed)
+func rankCheck(keyword, target string, ts time.Time, position int) stats.TaskStats {
+	taskStats := stats.TaskStats{Keyword: keyword, TargetURL: target, Timestamp: ts}
+	switch {
+	case position > 0:
+		taskStats.Success, taskStats.Position, taskStats.Outcome = true, position, stats.OutcomeFound
+	case position == 0:
+		taskStats.Success, taskStats.Outcome = true, stats.OutcomeNotInTop
+	default:
+		taskStats.Error = "timeout"
+	}
+	return taskStats
+}
+
+// openTestStore returns a store with three series:
+// golang/example.com improving from 9 to 3, golang/blog.example.com lost
+// on the last day, and rust/other.io with one failed check.
+func openTestStore(t *testing.T) stats.Store {
+	t.Helper()
+	store, err := stats.OpenBoltStore(filepath.Join(t.TempDir(), "history.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	for i, position := range []int{9, 7, -1, 4, 3} {
+		require.NoError(t, store.Append(rankCheck("golang", "example.com", day0.AddDate(0, 0, i).Add(9*time.Hour), position)))
+	}
+	for i, position := range []int{2, 2, 0} {
+		require.NoError(t, store.Append(rankCheck("golang", "blog.example.com", day0.AddDate(0, 0, i).Add(9*time.Hour), position)))
+	}
+	require.NoError(t, store.Append(rankCheck("rust", "other.io", day0.Add(9*time.Hour), 15)))
+	require.NoError(t, store.Append(rankCheck("rust", "other.io", day0.Add(10*time.Hour), -1)))
+	return store
+}
+
+func seriesTargets(report *Report) []string {
+	var targets []string
+	for _, series := range report.Series {
+		targets = append(targets, series.Keyword+"/"+series.TargetURL)
+	}
+	return targets
+}
+
+// ===== Build tests =====
+
+func TestBuild(t *testing.T) {
+	store := openTestStore(t)
+
+	report, err := Build(store, Filter{From: day0}, day0.AddDate(0, 0, 10))
+	require.NoError(t, err)
+	assert.Equal(t, stats.GranularityDay, report.Granularity)
+	require.Equal(t, []string{"golang/blog.example.com", "golang/example.com", "rust/other.io"}, seriesTargets(report))
+
+	lost := report.Series[0]
+	assert.Equal(t, TrendLost, lost.Trend)
+	assert.Equal(t, 1, lost.NotInTop)
+	assert.Equal(t, 2, lost.LastPosition)
+
+	improving := report.Series[1]
+	assert.Equal(t, 5, improving.Checks)
+	assert.Equal(t, 4, improving.Ranked)
+	assert.Equal(t, 1, improving.Failed)
+	assert.Equal(t, 80.0, improving.SuccessRate)
+	assert.Equal(t, 5.75, improving.AvgPosition)
+	assert.Equal(t, 3, improving.BestPosition)
+	assert.Equal(t, 9, improving.WorstPosition)
+	assert.Equal(t, 3, improving.LastPosition)
+	assert.Equal(t, 6.0, improving.Change)
+	assert.Equal(t, TrendUp, improving.Trend)
+	assert.Len(t, improving.Buckets, 5)
+
+	failing := report.Series[2]
+	assert.Equal(t, 50.0, failing.SuccessRate)
+	assert.Equal(t, TrendFlat, failing.Trend)
+}
+
+func TestBuild_Filters(t *testing.T) {
+	store := openTestStore(t)
+	now := day0.AddDate(0, 0, 10)
+
+	report, err := Build(store, Filter{Keyword: "GOLANG"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"golang/blog.example.com", "golang/example.com"}, seriesTargets(report))
+
+	report, err = Build(store, Filter{Domain: "other.io"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rust/other.io"}, seriesTargets(report))
+
+	report, err = Build(store, Filter{Domain: "www.example.com"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"golang/blog.example.com", "golang/example.com"}, seriesTargets(report), "www. is ignored")
+
+	// Only the last two days
+	report, err = Build(store, Filter{From: day0.AddDate(0, 0, 3), To: day0.AddDate(0, 0, 5)}, now)
+	require.NoError(t, err)
+	require.Equal(t, []string{"golang/example.com"}, seriesTargets(report))
+	assert.Equal(t, 2, report.Series[0].Checks)
+	assert.Equal(t, 1.0, report.Series[0].Change)
+
+	_, err = Build(store, Filter{Domain: "bad domain"}, now)
+	assert.Error(t, err)
+}
+
+func TestBuild_NotRanked(t *testing.T) {
+	store, err := stats.OpenBoltStore(filepath.Join(t.TempDir(), "history.db"))
+	require.NoError(t, err)
+	defer store.Close()
+	require.NoError(t, store.Append(rankCheck("golang", "example.com", day0, 0)))
+
+	report, err := Build(store, Filter{}, day0.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Len(t, report.Series, 1)
+	assert.Equal(t, TrendNotRanked, report.Series[0].Trend)
+	assert.Equal(t, 0.0, report.Series[0].AvgPosition)
+}
+
+// ===== Format tests =====
+
+func TestParseFormat(t *testing.T) {
+	for input, expected := range map[string]Format{"csv": FormatCSV, "JSON": FormatJSON, " html ": FormatHTML, "": FormatHTML} {
+		format, err := ParseFormat(input)
+		require.NoError(t, err)
+		assert.Equal(t, expected, format)
+	}
+
+	_, err := ParseFormat("pdf")
+	assert.Error(t, err)
+}
+
+func TestWriteCSV(t *testing.T) {
+	report, err := Build(openTestStore(t), Filter{Keyword: "golang", Domain: "example.com"}, day0.AddDate(0, 0, 10))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, report, FormatCSV))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 9, "header, 3 blog.example.com days and 5 example.com days")
+	assert.Equal(t, csvHeader, rows[0])
+	assert.Equal(t, []string{"golang", "example.com", "", "2024-03-04T00:00:00Z", "1", "1", "0", "0", "100.0", "9.00", "9", "9", "9"}, rows[4])
+	assert.Equal(t, []string{"golang", "example.com", "", "2024-03-06T00:00:00Z", "1", "0", "0", "1", "0.0", "", "", "", ""}, rows[6])
+}
+
+func TestWriteJSON(t *testing.T) {
+	report, err := Build(openTestStore(t), Filter{Domain: "other.io"}, day0.AddDate(0, 0, 10))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, report, FormatJSON))
+
+	var decoded Report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Len(t, decoded.Series, 1)
+	assert.Equal(t, "other.io", decoded.Series[0].TargetURL)
+	assert.Equal(t, 50.0, decoded.Series[0].SuccessRate)
+	assert.Equal(t, "other.io", decoded.Domain)
+}
+
+func TestWriteHTML(t *testing.T) {
+	report, err := Build(openTestStore(t), Filter{Keyword: "golang"}, day0.AddDate(0, 0, 10))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, report, FormatHTML))
+	html := buf.String()
+
+	assert.True(t, strings.HasPrefix(html, "<!DOCTYPE html>"))
+	assert.Equal(t, 2, strings.Count(html, "<svg"))
+	assert.Contains(t, html, `class="up"`)
+	assert.Contains(t, html, `class="lost"`)
+	assert.NotContains(t, html, "<script")
+	assert.NotContains(t, html, `src="http`, "the page is self-contained")
+}
+
+func TestWriteHTML_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteHTML(&buf, &Report{Generated: day0, Granularity: stats.GranularityDay}))
+	assert.Contains(t, buf.String(), "No rank history matches this report.")
+}
+
+func TestNewChart_Gaps(t *testing.T) {
+	series := SeriesReport{
+		WorstPosition: 20,
+		Buckets: []stats.RankBucket{
+			{Start: day0, Ranked: 1, AvgPosition: 1},
+			{Start: day0.AddDate(0, 0, 1), Ranked: 1, AvgPosition: 20},
+			{Start: day0.AddDate(0, 0, 2), NotInTop: 1},
+			{Start: day0.AddDate(0, 0, 3), Ranked: 1, AvgPosition: 10},
+		},
+	}
+
+	c := newChart(series, stats.GranularityDay)
+	require.Len(t, c.Points, 3)
+	assert.Equal(t, 2, strings.Count(c.Path, "M"), "the unranked bucket starts a new segment")
+	assert.Less(t, c.Points[0].Y, c.Points[1].Y, "position 1 is drawn above position 20")
+	assert.Equal(t, c.Left, c.Points[0].X)
+	assert.Equal(t, c.Right, c.Points[2].X)
+	assert.Equal(t, "2024-03-04", c.FirstLabel)
+	assert.Equal(t, "2024-03-07", c.LastLabel)
+}
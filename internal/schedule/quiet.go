@@ -0,0 +1,117 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QuietHours is a daily local time window without runs, e.g. 22:00-07:00.
+// The zero value has no quiet hours.
+type QuietHours struct {
+	start time.Duration // Offset from midnight
+	end   time.Duration // Offset from midnight; before start if the window spans midnight
+	set   bool
+}
+
+// ParseQuietHours parses a "HH:MM-HH:MM" window; an empty string means no quiet hours
+func ParseQuietHours(value string) (QuietHours, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return QuietHours{}, nil
+	}
+
+	from, to, ok := strings.Cut(value, "-")
+	if !ok {
+		return QuietHours{}, fmt.Errorf("quiet hours must look like 22:00-07:00, got %q", value)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours: %w", err)
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours: %w", err)
+	}
+	if start == end {
+		return QuietHours{}, fmt.Errorf("quiet hours cannot start and end at %s", strings.TrimSpace(from))
+	}
+	return QuietHours{start: start, end: end, set: true}, nil
+}
+
+// IsZero reports whether there are no quiet hours
+func (q QuietHours) IsZero() bool {
+	return !q.set
+}
+
+// Contains reports whether t is in the quiet hours
+func (q QuietHours) Contains(t time.Time) bool {
+	if !q.set {
+		return false
+	}
+	offset := sinceMidnight(t)
+	if q.start < q.end {
+		return offset >= q.start && offset < q.end
+	}
+	return offset >= q.start || offset < q.end
+}
+
+// End returns the end of the quiet hours that contain t, or t itself when
+// t is not in the quiet hours
+func (q QuietHours) End(t time.Time) time.Time {
+	if !q.Contains(t) {
+		return t
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if q.start > q.end && sinceMidnight(t) >= q.start {
+		// The window continues after midnight
+		midnight = midnight.AddDate(0, 0, 1)
+	}
+	return clock(midnight, q.end)
+}
+
+// String returns the window in the "HH:MM-HH:MM" form
+func (q QuietHours) String() string {
+	if !q.set {
+		return ""
+	}
+	return formatClock(q.start) + "-" + formatClock(q.end)
+}
+
+// parseClock parses "HH:MM" into an offset from midnight
+func parseClock(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	hours, minutes, ok := strings.Cut(value, ":")
+	if !ok {
+		return 0, fmt.Errorf("time must look like 09:30, got %q", value)
+	}
+	h, err := strconv.Atoi(hours)
+	if err != nil || h < 0 || h > 23 {
+		return 0, fmt.Errorf("invalid hour in %q", value)
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m < 0 || m > 59 {
+		return 0, fmt.Errorf("invalid minute in %q", value)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// formatClock formats an offset from midnight as "HH:MM"
+func formatClock(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset.Hours()), int(offset.Minutes())%60)
+}
+
+// sinceMidnight returns the local time of day of t
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+}
+
+// clock returns the time at an offset from midnight, keeping wall clock
+// times across daylight saving changes
+func clock(midnight time.Time, offset time.Duration) time.Time {
+	h := int(offset / time.Hour)
+	m := int(offset % time.Hour / time.Minute)
+	return time.Date(midnight.Year(), midnight.Month(), midnight.Day(), h, m, 0, 0, midnight.Location())
+}
@@ -0,0 +1,148 @@
+// Package schedule decides when keywords are checked. It parses cron
+// expressions and "every"/"at" specs, delays runs by a random jitter and
+// out of quiet hours, and persists the next run of every keyword so a
+// restart continues where the previous process stopped.
+package schedule
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule returns the next activation time after a given time
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// Parse parses a schedule spec:
+//
+//	every 1h             fixed delay after each run (also "@every 1h")
+//	at 09:00,18:00       daily at the given local times
+//	0 */6 * * *          standard 5-field cron expression
+//	@hourly, @daily, …   cron descriptors (@weekly, @monthly, @yearly)
+//
+// Cron expressions may start with "TZ=Europe/Istanbul" to use another time zone.
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	lower := strings.ToLower(spec)
+
+	switch {
+	case spec == "":
+		return nil, fmt.Errorf("schedule cannot be empty")
+	case strings.HasPrefix(lower, "every "), strings.HasPrefix(lower, "@every "):
+		return parseEvery(spec[strings.Index(spec, " ")+1:])
+	case strings.HasPrefix(lower, "at "):
+		return parseAt(spec[3:])
+	}
+
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+	}
+	return schedule, nil
+}
+
+// Every runs at a fixed delay after the previous run
+type Every time.Duration
+
+// Next returns t plus the delay
+func (e Every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// parseEvery parses the duration of an "every" spec
+func parseEvery(value string) (Every, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid every schedule: %w", err)
+	}
+	if d < time.Second {
+		return 0, fmt.Errorf("every schedule must be at least 1s, got %s", d)
+	}
+	return Every(d), nil
+}
+
+// At runs daily at fixed local times of day
+type At []time.Duration // Offsets from midnight, sorted
+
+// Next returns the first of the times of day after t
+func (a At) Next(t time.Time) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for day := 0; day < 2; day++ {
+		for _, offset := range a {
+			next := clock(midnight.AddDate(0, 0, day), offset)
+			if next.After(t) {
+				return next
+			}
+		}
+	}
+	return clock(midnight.AddDate(0, 0, 2), a[0])
+}
+
+// parseAt parses the comma separated times of an "at" spec
+func parseAt(value string) (At, error) {
+	var at At
+	for _, part := range strings.Split(value, ",") {
+		offset, err := parseClock(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid at schedule: %w", err)
+		}
+		at = append(at, offset)
+	}
+	sort.Slice(at, func(i, j int) bool { return at[i] < at[j] })
+	return at, nil
+}
+
+// Plan is the schedule of one keyword: when it runs, how much random delay
+// is added to each run and when it never runs
+type Plan struct {
+	Schedule Schedule
+	Jitter   time.Duration // Each run is delayed by a random duration in [0, Jitter)
+	Quiet    QuietHours    // Runs due in quiet hours wait for their end
+}
+
+// NewPlan parses a schedule spec and quiet hours into a plan
+//
+// Example:
+//
+//	plan, err := schedule.NewPlan("every 1h", 5*time.Minute, "22:00-07:00")
+//	if err != nil {
+//	    return err
+//	}
+//	next := plan.Next(time.Now())
+func NewPlan(spec string, jitter time.Duration, quietHours string) (*Plan, error) {
+	if jitter < 0 {
+		return nil, fmt.Errorf("jitter must be non-negative, got %s", jitter)
+	}
+	schedule, err := Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	quiet, err := ParseQuietHours(quietHours)
+	if err != nil {
+		return nil, err
+	}
+	return &Plan{Schedule: schedule, Jitter: jitter, Quiet: quiet}, nil
+}
+
+// Next returns the next run after t
+func (p *Plan) Next(t time.Time) time.Time {
+	next := p.Schedule.Next(t).Add(p.jitter())
+	if p.Quiet.Contains(next) {
+		next = p.Quiet.End(next).Add(p.jitter())
+	}
+	return next
+}
+
+// jitter returns a random delay in [0, Jitter)
+func (p *Plan) jitter() time.Duration {
+	if p.Jitter <= 0 {
+		return 0
+	}
+	return rand.N(p.Jitter)
+}
@@ -0,0 +1,163 @@
+package schedule
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// date returns a UTC time on 2024-03-04 (a Monday) plus days
+func date(days, hour, minute int) time.Time {
+	return time.Date(2024, 3, 4+days, hour, minute, 0, 0, time.UTC)
+}
+
+// ===== Parse tests =====
+
+func TestParse(t *testing.T) {
+	now := date(0, 10, 30)
+
+	tests := []struct {
+		spec string
+		next time.Time
+	}{
+		{"every 1h", date(0, 11, 30)},
+		{"@every 90m", date(0, 12, 0)},
+		{"Every 15m", date(0, 10, 45)},
+		{"at 09:00,18:00", date(0, 18, 0)},
+		{"at 18:00, 09:00", date(0, 18, 0)},
+		{"at 09:00", date(1, 9, 0)},
+		{"0 */6 * * *", date(0, 12, 0)},
+		{"@hourly", date(0, 11, 0)},
+		{"@daily", date(1, 0, 0)},
+		{"0 6 * * 1", date(7, 6, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.next, schedule.Next(now).UTC())
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "every", "every soon", "every 10ms", "at 25:00", "at 9", "at ", "* * *", "@fortnightly"} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+// ===== Quiet hours tests =====
+
+func TestQuietHours(t *testing.T) {
+	overnight, err := ParseQuietHours("22:00-07:00")
+	require.NoError(t, err)
+	assert.Equal(t, "22:00-07:00", overnight.String())
+
+	assert.True(t, overnight.Contains(date(0, 23, 0)))
+	assert.True(t, overnight.Contains(date(0, 3, 0)))
+	assert.True(t, overnight.Contains(date(0, 22, 0)))
+	assert.False(t, overnight.Contains(date(0, 7, 0)))
+	assert.False(t, overnight.Contains(date(0, 12, 0)))
+
+	assert.Equal(t, date(1, 7, 0), overnight.End(date(0, 23, 15)), "the window continues after midnight")
+	assert.Equal(t, date(0, 7, 0), overnight.End(date(0, 3, 0)))
+	assert.Equal(t, date(0, 12, 0), overnight.End(date(0, 12, 0)), "outside the window")
+
+	lunch, err := ParseQuietHours("12:00-13:30")
+	require.NoError(t, err)
+	assert.True(t, lunch.Contains(date(0, 13, 0)))
+	assert.False(t, lunch.Contains(date(0, 13, 30)))
+	assert.Equal(t, date(0, 13, 30), lunch.End(date(0, 12, 10)))
+}
+
+func TestParseQuietHours_Invalid(t *testing.T) {
+	none, err := ParseQuietHours("")
+	require.NoError(t, err)
+	assert.True(t, none.IsZero())
+	assert.False(t, none.Contains(date(0, 3, 0)))
+
+	for _, value := range []string{"22:00", "22-07", "22:00-24:00", "07:00-07:00", "ab:cd-07:00"} {
+		_, err := ParseQuietHours(value)
+		assert.Error(t, err, value)
+	}
+}
+
+// ===== Plan tests =====
+
+func TestPlan_Next(t *testing.T) {
+	plan, err := NewPlan("every 1h", 0, "22:00-07:00")
+	require.NoError(t, err)
+
+	assert.Equal(t, date(0, 11, 0), plan.Next(date(0, 10, 0)))
+	assert.Equal(t, date(1, 7, 0), plan.Next(date(0, 21, 30)), "runs due in quiet hours wait for their end")
+}
+
+func TestPlan_Jitter(t *testing.T) {
+	plan, err := NewPlan("at 09:00", 10*time.Minute, "")
+	require.NoError(t, err)
+
+	for i := 0; i < 50; i++ {
+		next := plan.Next(date(0, 8, 0))
+		assert.False(t, next.Before(date(0, 9, 0)), next)
+		assert.True(t, next.Before(date(0, 9, 10)), next)
+	}
+}
+
+func TestNewPlan_Invalid(t *testing.T) {
+	_, err := NewPlan("every 1h", -time.Second, "")
+	assert.Error(t, err)
+
+	_, err = NewPlan("every 1h", 0, "late")
+	assert.Error(t, err)
+
+	_, err = NewPlan("sometimes", 0, "")
+	assert.Error(t, err)
+}
+
+// ===== State tests =====
+
+func TestState_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "schedule.json")
+
+	state, err := LoadState(path)
+	require.NoError(t, err)
+	_, ok := state.Next("golang-example.com")
+	assert.False(t, ok, "a missing file gives an empty state")
+
+	state.Set("golang-example.com", date(0, 11, 0))
+	state.Set("rust-other.io", date(1, 9, 0))
+	require.NoError(t, state.Save())
+
+	loaded, err := LoadState(path)
+	require.NoError(t, err)
+	next, ok := loaded.Next("golang-example.com")
+	require.True(t, ok)
+	assert.True(t, next.Equal(date(0, 11, 0)))
+
+	loaded.Retain([]string{"rust-other.io"})
+	_, ok = loaded.Next("golang-example.com")
+	assert.False(t, ok)
+	_, ok = loaded.Next("rust-other.io")
+	assert.True(t, ok)
+}
+
+func TestState_InMemory(t *testing.T) {
+	state, err := LoadState("")
+	require.NoError(t, err)
+	state.Set("golang-example.com", date(0, 11, 0))
+	assert.NoError(t, state.Save())
+}
+
+func TestLoadState_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+
+	_, err := LoadState(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,107 @@
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// State holds the next run of every keyword and persists it as JSON.
+// A State without a path is kept in memory only.
+type State struct {
+	path string
+	mu   sync.RWMutex
+	next map[string]time.Time
+}
+
+// LoadState loads the state from path; a missing file gives an empty state
+//
+// Example:
+//
+//	state, err := schedule.LoadState("data/schedule.json")
+//	if err != nil {
+//	    return err
+//	}
+//	state.Set("golang-example.com", plan.Next(time.Now()))
+//	err = state.Save()
+func LoadState(path string) (*State, error) {
+	state := &State{path: path, next: make(map[string]time.Time)}
+	if path == "" {
+		return state, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedule state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state.next); err != nil {
+		return nil, fmt.Errorf("failed to parse schedule state: %w", err)
+	}
+	return state, nil
+}
+
+// Next returns the stored next run of a key
+func (s *State) Next(key string) (time.Time, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	next, ok := s.next[key]
+	return next, ok
+}
+
+// Set stores the next run of a key
+func (s *State) Set(key string, next time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next[key] = next
+}
+
+// Retain drops every key that is not in keys, e.g. removed keywords
+func (s *State) Retain(keys []string) {
+	keep := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		keep[key] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.next {
+		if !keep[key] {
+			delete(s.next, key)
+		}
+	}
+}
+
+// Save writes the state to its file
+func (s *State) Save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	s.mu.RLock()
+	data, err := json.MarshalIndent(s.next, "", "  ")
+	s.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal schedule state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Write a temporary file first so a crash never leaves a truncated state
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write schedule state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write schedule state: %w", err)
+	}
+	return nil
+}
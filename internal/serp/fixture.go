@@ -0,0 +1,118 @@
+package serp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// FixtureProvider serves result pages from recorded SERP HTML files.
+// Pages are looked up as <dir>/<query slug>/page-<n>.html, so the first page
+// recorded for "golang tutorial" lives in <dir>/golang-tutorial/page-1.html.
+// Pages recorded for a locale live in a subdirectory named after it, e.g.
+// <dir>/hl-tr-gl-tr/golang-tutorial/page-1.html.
+type FixtureProvider struct {
+	dir     string
+	profile SelectorProfile
+}
+
+// NewFixtureProvider creates a Provider that reads fixtures from dir
+//
+// Example:
+//
+//	provider := serp.NewFixtureProvider("testdata/serp")
+func NewFixtureProvider(dir string) *FixtureProvider {
+	return &FixtureProvider{
+		dir:     dir,
+		profile: DefaultProfile(),
+	}
+}
+
+// Search parses the recorded HTML of the given page
+func (p *FixtureProvider) Search(ctx context.Context, query string, page int) ([]SearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if page < 1 {
+		return nil, fmt.Errorf("page must be >= 1, got %d", page)
+	}
+
+	path := p.FixturePath(query, page)
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) && page > 1 {
+			return nil, ErrNoMorePages
+		}
+		return nil, fmt.Errorf("failed to open fixture: %w", err)
+	}
+	defer f.Close()
+
+	results, _, err := ParseResultsWithProfile(f, p.profile)
+	return results, err
+}
+
+// WithLocale returns a FixtureProvider that reads the fixtures recorded for locale
+//
+// Example:
+//
+//	provider := serp.NewFixtureProvider("testdata/serp").WithLocale(serp.Locale{HL: "tr", GL: "TR"})
+func (p *FixtureProvider) WithLocale(locale Locale) Provider {
+	localized := *p
+	if !locale.IsZero() {
+		localized.dir = filepath.Join(p.dir, FixtureSlug(locale.String()))
+	}
+	return &localized
+}
+
+// WithProfile returns a FixtureProvider that parses pages with a selector profile
+//
+// Example:
+//
+//	provider := serp.NewFixtureProvider("testdata/serp").WithProfile(profile)
+func (p *FixtureProvider) WithProfile(profile SelectorProfile) *FixtureProvider {
+	profiled := *p
+	profiled.profile = profile
+	return &profiled
+}
+
+// FixturePath returns the file that holds the given page of a query
+func (p *FixtureProvider) FixturePath(query string, page int) string {
+	return filepath.Join(p.dir, FixtureSlug(query), fmt.Sprintf("page-%d.html", page))
+}
+
+// FixtureFile returns the file that holds the given page of a query recorded
+// for locale below dir. Pages saved at this path can be replayed with
+// NewFixtureProvider(dir).WithLocale(locale).
+//
+// Example:
+//
+//	path := serp.FixtureFile("testdata/serp", serp.Locale{}, "golang tutorial", 1)
+//	// testdata/serp/golang-tutorial/page-1.html
+func FixtureFile(dir string, locale Locale, query string, page int) string {
+	provider := NewFixtureProvider(dir).WithLocale(locale).(*FixtureProvider)
+	return provider.FixturePath(query, page)
+}
+
+// FixtureSlug converts a query into the directory name used for its fixtures.
+// Letters and digits are kept (lowercased), everything else becomes a dash.
+func FixtureSlug(query string) string {
+	var b strings.Builder
+	dash := false
+
+	for _, r := range strings.ToLower(strings.TrimSpace(query)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
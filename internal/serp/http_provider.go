@@ -0,0 +1,175 @@
+package serp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	apperrors "github.com/omer/go-bot/internal/errors"
+)
+
+// HTTPProviderConfig holds configuration for a JSON SERP data API
+type HTTPProviderConfig struct {
+	Endpoint   string            // API endpoint; query and page parameters are appended
+	QueryParam string            // Name of the query parameter (default: "q")
+	PageParam  string            // Name of the page parameter (default: "page")
+	Headers    map[string]string // Extra request headers (e.g. API keys)
+	Timeout    time.Duration     // Request timeout (default: 30s)
+	Client     *http.Client      // Optional HTTP client
+}
+
+// HTTPProvider serves result pages from a JSON SERP data API.
+// The endpoint must answer with {"results": [{"title", "url", "description", "position"}]}.
+// Locale settings are sent as the hl, gl, google_domain and device parameters.
+type HTTPProvider struct {
+	endpoint   *url.URL
+	queryParam string
+	pageParam  string
+	headers    map[string]string
+	client     *http.Client
+	locale     Locale
+}
+
+// httpResponse is the response body expected from the API
+type httpResponse struct {
+	Results []SearchResult `json:"results"`
+}
+
+// NewHTTPProvider creates a Provider that calls a JSON SERP API
+//
+// Example:
+//
+//	provider, err := serp.NewHTTPProvider(serp.HTTPProviderConfig{
+//	    Endpoint: "https://serp.example.com/v1/search",
+//	    Headers:  map[string]string{"X-API-Key": key},
+//	})
+func NewHTTPProvider(config HTTPProviderConfig) (*HTTPProvider, error) {
+	if config.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint cannot be empty")
+	}
+
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported endpoint scheme: %s", endpoint.Scheme)
+	}
+
+	// Set defaults
+	if config.QueryParam == "" {
+		config.QueryParam = "q"
+	}
+	if config.PageParam == "" {
+		config.PageParam = "page"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: config.Timeout}
+	}
+
+	return &HTTPProvider{
+		endpoint:   endpoint,
+		queryParam: config.QueryParam,
+		pageParam:  config.PageParam,
+		headers:    config.Headers,
+		client:     config.Client,
+	}, nil
+}
+
+// Search requests the given page from the API
+func (p *HTTPProvider) Search(ctx context.Context, query string, page int) ([]SearchResult, error) {
+	if query == "" {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+	if page < 1 {
+		return nil, fmt.Errorf("page must be >= 1, got %d", page)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.requestURL(query, page), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	for key, value := range p.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, apperrors.NewTimeoutError("request timed out", err)
+		}
+		return nil, apperrors.NewNetworkError("request failed", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, statusError(resp.StatusCode, body)
+	}
+
+	var body httpResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(body.Results) == 0 && page > 1 {
+		return nil, ErrNoMorePages
+	}
+
+	// Fill in positions the API left out
+	for i := range body.Results {
+		if body.Results[i].Position == 0 {
+			body.Results[i].Position = i + 1
+		}
+	}
+
+	return body.Results, nil
+}
+
+// WithLocale returns an HTTPProvider that sends the given locale with every request
+func (p *HTTPProvider) WithLocale(locale Locale) Provider {
+	localized := *p
+	localized.locale = locale
+	return &localized
+}
+
+// requestURL builds the request URL for a query and page
+func (p *HTTPProvider) requestURL(query string, page int) string {
+	u := *p.endpoint
+	params := u.Query()
+	params.Set(p.queryParam, query)
+	params.Set(p.pageParam, strconv.Itoa(page))
+	for name, value := range map[string]string{
+		"hl":            p.locale.HL,
+		"gl":            p.locale.GL,
+		"google_domain": p.locale.Domain,
+		"device":        p.locale.Device,
+	} {
+		if value != "" {
+			params.Set(name, value)
+		}
+	}
+	u.RawQuery = params.Encode()
+	return u.String()
+}
+
+// statusError classifies a failed API response. Rate limits and server
+// errors are network errors and can be retried; other statuses mean the
+// request itself is wrong, e.g. a bad endpoint or API key.
+func statusError(status int, body []byte) error {
+	message := fmt.Sprintf("unexpected status %d: %s", status, body)
+	if status == http.StatusTooManyRequests || status >= http.StatusInternalServerError {
+		return apperrors.NewNetworkError(message, nil)
+	}
+	return apperrors.NewConfigError(message, nil)
+}
@@ -0,0 +1,135 @@
+package serp
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/omer/go-bot/internal/browser"
+)
+
+// DefaultDomain is the Google domain used when a Locale does not set one
+const DefaultDomain = "google.com"
+
+// Locale holds the language, country and device context of a search.
+// Results for the same keyword differ between google.com.tr and google.de,
+// and between desktop and mobile, so ranks are tracked per Locale.
+type Locale struct {
+	HL     string // Interface language (hl parameter, e.g. "tr")
+	GL     string // Country the results are localized for (gl parameter, e.g. "TR")
+	Domain string // Google domain (e.g. "google.com.tr", default: google.com)
+	Device string // Device profile: desktop or mobile (see browser.DeviceProfile)
+}
+
+// IsZero returns true if no locale setting is present
+func (l Locale) IsZero() bool {
+	return l == Locale{}
+}
+
+// String returns the set parts of the locale in a stable form, e.g.
+// "hl=tr,gl=TR,domain=google.com.tr,device=mobile". It is empty for the zero Locale.
+func (l Locale) String() string {
+	var parts []string
+	for _, part := range [][2]string{
+		{"hl", l.HL},
+		{"gl", l.GL},
+		{"domain", l.Domain},
+		{"device", l.Device},
+	} {
+		if part[1] != "" {
+			parts = append(parts, part[0]+"="+part[1])
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Validate checks the locale settings
+func (l Locale) Validate() error {
+	if l.HL != "" && !isLanguageCode(l.HL) {
+		return fmt.Errorf("invalid hl %q: expected a language code such as \"tr\" or \"pt-BR\"", l.HL)
+	}
+	if l.GL != "" && (len(l.GL) != 2 || !isLetters(l.GL)) {
+		return fmt.Errorf("invalid gl %q: expected a two-letter country code such as \"TR\"", l.GL)
+	}
+	if l.Domain != "" && !isGoogleDomain(l.Domain) {
+		return fmt.Errorf("invalid domain %q: expected a Google domain such as \"google.com.tr\"", l.Domain)
+	}
+	if l.Device != "" {
+		if _, err := browser.DeviceProfile(l.Device); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HomeURL returns the Google home page for the locale
+//
+// Example:
+//
+//	locale := serp.Locale{HL: "tr", GL: "TR", Domain: "google.com.tr"}
+//	locale.HomeURL() // https://www.google.com.tr/?gl=TR&hl=tr
+func (l Locale) HomeURL() string {
+	return l.pageURL("/", url.Values{})
+}
+
+// SearchURL returns the URL of the given result page (1-based) for a query
+func (l Locale) SearchURL(query string, page int) string {
+	params := url.Values{}
+	params.Set("q", query)
+	if page > 1 {
+		params.Set("start", strconv.Itoa((page-1)*10))
+	}
+	return l.pageURL("/search", params)
+}
+
+// pageURL builds a URL on the locale domain with the hl and gl parameters added
+func (l Locale) pageURL(path string, params url.Values) string {
+	domain := strings.TrimPrefix(strings.ToLower(l.Domain), "www.")
+	if domain == "" {
+		domain = DefaultDomain
+	}
+	if l.HL != "" {
+		params.Set("hl", l.HL)
+	}
+	if l.GL != "" {
+		params.Set("gl", l.GL)
+	}
+
+	u := url.URL{Scheme: "https", Host: "www." + domain, Path: path, RawQuery: params.Encode()}
+	return u.String()
+}
+
+// isGoogleDomain reports whether domain is a Google search domain such as "google.de"
+func isGoogleDomain(domain string) bool {
+	domain = strings.TrimPrefix(strings.ToLower(domain), "www.")
+	suffix, ok := strings.CutPrefix(domain, "google.")
+	if !ok || suffix == "" {
+		return false
+	}
+	for _, label := range strings.Split(suffix, ".") {
+		if label == "" || !isLetters(label) {
+			return false
+		}
+	}
+	return true
+}
+
+// isLanguageCode reports whether s looks like a language code ("tr", "pt-BR", "zh-CN")
+func isLanguageCode(s string) bool {
+	lang, region, hasRegion := strings.Cut(s, "-")
+	if len(lang) < 2 || len(lang) > 3 || !isLetters(lang) {
+		return false
+	}
+	return !hasRegion || (len(region) >= 2 && len(region) <= 4 && isLetters(region))
+}
+
+// isLetters reports whether s consists of ASCII letters only
+func isLetters(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return s != ""
+}
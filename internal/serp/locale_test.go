@@ -0,0 +1,53 @@
+package serp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ===== Locale tests =====
+
+func TestLocale_String(t *testing.T) {
+	assert.Equal(t, "", Locale{}.String())
+	assert.True(t, Locale{}.IsZero())
+
+	locale := Locale{HL: "tr", GL: "TR", Domain: "google.com.tr", Device: "mobile"}
+	assert.Equal(t, "hl=tr,gl=TR,domain=google.com.tr,device=mobile", locale.String())
+	assert.Equal(t, "gl=DE", Locale{GL: "DE"}.String())
+}
+
+func TestLocale_URLs(t *testing.T) {
+	assert.Equal(t, "https://www.google.com/", Locale{}.HomeURL())
+	assert.Equal(t, "https://www.google.com/search?q=golang", Locale{}.SearchURL("golang", 1))
+
+	locale := Locale{HL: "tr", GL: "TR", Domain: "www.google.com.tr"}
+	assert.Equal(t, "https://www.google.com.tr/?gl=TR&hl=tr", locale.HomeURL())
+	assert.Equal(t, "https://www.google.com.tr/search?gl=TR&hl=tr&q=go+dili&start=20", locale.SearchURL("go dili", 3))
+}
+
+func TestLocale_Validate(t *testing.T) {
+	valid := []Locale{
+		{},
+		{HL: "tr", GL: "TR", Domain: "google.com.tr", Device: "mobile"},
+		{HL: "pt-BR", Domain: "www.google.de", Device: "desktop"},
+	}
+	for _, locale := range valid {
+		assert.NoError(t, locale.Validate(), locale.String())
+	}
+
+	invalid := map[string]Locale{
+		"invalid hl":             {HL: "turkish"},
+		"invalid gl":             {GL: "TUR"},
+		"invalid domain":         {Domain: "bing.com"},
+		"unknown device profile": {Device: "watch"},
+	}
+	for msg, locale := range invalid {
+		err := locale.Validate()
+		if assert.Error(t, err, locale.String()) {
+			assert.Contains(t, err.Error(), msg)
+		}
+	}
+	assert.Error(t, Locale{Domain: "google."}.Validate())
+	assert.Error(t, Locale{Domain: "google.com/search"}.Validate())
+}
@@ -2,7 +2,11 @@ package serp
 
 import (
 	"fmt"
+	"net/url"
+	"strconv"
 	"time"
+
+	apperrors "github.com/omer/go-bot/internal/errors"
 )
 
 // NextPage navigates to the next page of search results
@@ -21,39 +25,81 @@ func (s *Searcher) NextPage() (bool, error) {
 	s.logger.Debug("Attempting to navigate to next page", nil)
 
 	// Check if next button exists
-	if !s.browser.ElementExists(s.selectors.NextButton) {
+	if !s.browser.ElementExists(s.profile.NextButton.Any()) {
 		s.logger.Info("No next page available", nil)
 		return false, nil
 	}
+	nextButton := s.pick("next_button", s.profile.NextButton)
 
 	// Scroll to next button to make it visible
-	err := s.browser.ScrollToElement(s.selectors.NextButton)
+	err := s.browser.ScrollToElement(nextButton)
 	if err != nil {
 		return false, fmt.Errorf("failed to scroll to next button: %w", err)
 	}
 
 	// Small delay before clicking
-	time.Sleep(500 * time.Millisecond)
+	if err := s.browser.Sleep(500 * time.Millisecond); err != nil {
+		return false, err
+	}
 
 	// Click next button
-	err = s.browser.Click(s.selectors.NextButton)
+	err = s.browser.Click(nextButton)
 	if err != nil {
 		return false, fmt.Errorf("failed to click next button: %w", err)
 	}
 
 	// Wait for page to load
-	time.Sleep(2 * time.Second)
+	if err := s.browser.Sleep(2 * time.Second); err != nil {
+		return false, err
+	}
 
 	// Check for CAPTCHA after navigation
 	if s.HasCaptcha() {
 		s.logger.Warn("CAPTCHA detected after page navigation", nil)
-		return false, fmt.Errorf("CAPTCHA detected")
+		return false, apperrors.NewCaptchaError("CAPTCHA detected")
 	}
 
-	s.logger.Info("Successfully navigated to next page", nil)
+	s.page++
+
+	s.logger.Info("Successfully navigated to next page", map[string]interface{}{
+		"page": s.page,
+	})
 	return true, nil
 }
 
+// GoToPage opens the given result page (1-based) for a keyword directly,
+// without going through the previous pages
+//
+// Example:
+//
+//	err := searcher.GoToPage("golang tutorial", 3)
+func (s *Searcher) GoToPage(keyword string, page int) error {
+	if keyword == "" {
+		return apperrors.NewValidationError("keyword cannot be empty")
+	}
+	if page < 1 {
+		return fmt.Errorf("page must be >= 1, got %d", page)
+	}
+
+	s.logger.Debug("Opening result page", map[string]interface{}{
+		"keyword": keyword,
+		"page":    page,
+	})
+
+	err := s.browser.Navigate(s.pageURL(s.locale.SearchURL(keyword, page)))
+	if err != nil {
+		return fmt.Errorf("failed to open page %d: %w", page, err)
+	}
+
+	if s.HasCaptcha() {
+		s.logger.Warn("CAPTCHA detected after page navigation", nil)
+		return apperrors.NewCaptchaError("CAPTCHA detected")
+	}
+
+	s.page = page
+	return nil
+}
+
 // ClickResult clicks on a search result at the given position (1-based)
 //
 // Example:
@@ -116,7 +162,9 @@ func (s *Searcher) ClickTargetResult(targetURL string) error {
 	}
 
 	// Wait for page to load
-	time.Sleep(2 * time.Second)
+	if err := s.browser.Sleep(2 * time.Second); err != nil {
+		return err
+	}
 
 	// Get current URL to verify navigation
 	currentURL, err := s.browser.GetCurrentURL()
@@ -133,19 +181,47 @@ func (s *Searcher) ClickTargetResult(targetURL string) error {
 	return nil
 }
 
-// GetCurrentPage attempts to determine the current page number
-// Returns 1 for the first page, or the page number if it can be determined
+// GetCurrentPage returns the number of the result page currently open (1-based).
+// The page is read from the "start" offset of the current URL; when the URL
+// does not carry one, the page reached through Search, NextPage and GoToPage is used.
 func (s *Searcher) GetCurrentPage() (int, error) {
-	// This is a placeholder implementation
-	// In a real implementation, we would:
-	// 1. Look for pagination elements
-	// 2. Find the active/current page indicator
-	// 3. Parse and return the page number
+	currentURL, err := s.browser.GetCurrentURL()
+	if err == nil {
+		if page, ok := pageFromURL(currentURL); ok {
+			return page, nil
+		}
+	}
 
-	// For now, return 1 as default
+	if s.page > 0 {
+		return s.page, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read current URL: %w", err)
+	}
 	return 1, nil
 }
 
+// pageFromURL derives the result page from the start (and num) parameters of a SERP URL
+func pageFromURL(rawURL string) (int, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0, false
+	}
+
+	params := u.Query()
+	start, err := strconv.Atoi(params.Get("start"))
+	if err != nil || start < 0 {
+		return 0, false
+	}
+
+	perPage := 10
+	if num, err := strconv.Atoi(params.Get("num")); err == nil && num > 0 {
+		perPage = num
+	}
+
+	return start/perPage + 1, true
+}
+
 // ScrollToResult scrolls to make a specific search result visible
 func (s *Searcher) ScrollToResult(position int) error {
 	if position < 1 {
@@ -0,0 +1,342 @@
+package serp
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ParseResults extracts the ordered search results from a SERP HTML document.
+// It works on live DOM snapshots as well as on saved HTML files.
+//
+// Example:
+//
+//	f, _ := os.Open("testdata/serp.html")
+//	defer f.Close()
+//	results, err := serp.ParseResults(f, serp.DefaultSelectors())
+func ParseResults(r io.Reader, selectors Selectors) ([]SearchResult, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	return extractResults(doc, selectors), nil
+}
+
+// ParseResultsWithProfile extracts the ordered search results from a SERP
+// HTML document with the selectors of a profile. Every field uses the first
+// of its selectors that matches the document; the returned fallbacks list
+// the fields whose primary selector did not match.
+//
+// Example:
+//
+//	results, fallbacks, err := serp.ParseResultsWithProfile(f, serp.DefaultProfile())
+//	for _, fallback := range fallbacks {
+//	    log.Printf("selector fallback: %s", fallback)
+//	}
+func ParseResultsWithProfile(r io.Reader, profile SelectorProfile) ([]SearchResult, []Fallback, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	selectors, fallbacks := profile.Resolve(func(selector string) bool {
+		return doc.Find(selector).Length() > 0
+	})
+	return extractResults(doc, selectors), fallbacks, nil
+}
+
+// ParsedPage is a parsed SERP HTML document
+type ParsedPage struct {
+	Profile   string         `json:"profile"`             // Selector profile, e.g. "google@2"
+	Fallbacks []Fallback     `json:"fallbacks,omitempty"` // Fields whose primary selector did not match
+	Results   []SearchResult `json:"results"`
+}
+
+// ParsePage parses a SERP HTML document with a selector profile
+//
+// Example:
+//
+//	f, _ := os.Open("data/artifacts/task-1/golang/page-1.html")
+//	defer f.Close()
+//	page, err := serp.ParsePage(f, serp.DefaultProfile())
+func ParsePage(r io.Reader, profile SelectorProfile) (*ParsedPage, error) {
+	results, fallbacks, err := ParseResultsWithProfile(r, profile)
+	if err != nil {
+		return nil, err
+	}
+	return &ParsedPage{
+		Profile:   profile.String(),
+		Fallbacks: fallbacks,
+		Results:   results,
+	}, nil
+}
+
+// extractResults walks all result blocks in document order and classifies them.
+// Google nests result containers inside each other, so the same link can be
+// reached from several containers; results are deduplicated by type and URL.
+// Containers inside a SERP feature (featured snippets and "People also ask"
+// answers embed regular result markup) belong to that feature.
+func extractResults(doc *goquery.Document, selectors Selectors) []SearchResult {
+	p := &resultParser{
+		selectors: selectors,
+		results:   []SearchResult{},
+		seen:      make(map[string]bool),
+	}
+
+	features := joinSelectors(selectors.AdItem, selectors.FeaturedSnippet, selectors.PeopleAlsoAsk, selectors.LocalPackItem)
+	blocks := joinSelectors(features, selectors.ResultItem)
+
+	doc.Find(blocks).Each(func(_ int, item *goquery.Selection) {
+		if features != "" && item.ParentsFiltered(features).Length() > 0 {
+			return
+		}
+
+		switch {
+		case matches(item, selectors.AdItem):
+			p.addBlock(item, ResultTypeAd)
+		case matches(item, selectors.FeaturedSnippet):
+			p.addBlock(item, ResultTypeFeaturedSnippet)
+		case matches(item, selectors.PeopleAlsoAsk):
+			p.addBlock(item, ResultTypePeopleAlsoAsk)
+		case matches(item, selectors.LocalPackItem):
+			p.addBlock(item, ResultTypeLocalPack)
+		default:
+			// A wrapper around a feature is not an organic listing itself
+			if features != "" && item.Find(features).Length() > 0 {
+				return
+			}
+			p.addOrganic(item)
+		}
+	})
+
+	return p.results
+}
+
+// resultParser accumulates classified results and their organic and visual ranks
+type resultParser struct {
+	selectors Selectors
+	results   []SearchResult
+	seen      map[string]bool // key: type + URL
+	organic   int             // Organic results added so far
+	visual    int             // Blocks added so far (sitelinks share their parent's rank)
+}
+
+// add appends a result unless the same URL was already recorded for its type
+func (p *resultParser) add(result SearchResult) bool {
+	key := string(result.Type) + " " + result.URL
+	if p.seen[key] {
+		return false
+	}
+	p.seen[key] = true
+
+	if result.Type != ResultTypeSitelink {
+		p.visual++
+	}
+	result.VisualPosition = p.visual
+	if result.Type == ResultTypeOrganic {
+		p.organic++
+		result.Position = p.organic
+	}
+
+	p.results = append(p.results, result)
+	return true
+}
+
+// addOrganic records an organic listing and its sitelinks
+func (p *resultParser) addOrganic(item *goquery.Selection) {
+	title, link := resultHeading(item, p.selectors)
+	if link == nil {
+		return
+	}
+
+	resultURL := resolveResultURL(link.AttrOr("href", ""))
+	if resultURL == "" {
+		return
+	}
+
+	added := p.add(SearchResult{
+		Title:       title,
+		URL:         resultURL,
+		Description: resultSnippet(item, p.selectors),
+		Type:        ResultTypeOrganic,
+	})
+	if !added || p.selectors.Sitelink == "" {
+		return
+	}
+
+	item.Find(p.selectors.Sitelink).Each(func(_ int, a *goquery.Selection) {
+		sitelinkURL := resolveResultURL(a.AttrOr("href", ""))
+		title := cleanText(a.Text())
+		if sitelinkURL == "" || sitelinkURL == resultURL || title == "" {
+			return
+		}
+		p.add(SearchResult{
+			Title: title,
+			URL:   sitelinkURL,
+			Type:  ResultTypeSitelink,
+		})
+	})
+}
+
+// addBlock records a SERP feature block (ad, featured snippet, PAA answer, local entry).
+// Blocks without an outgoing link, such as a local entry without a website, are skipped.
+func (p *resultParser) addBlock(item *goquery.Selection, resultType ResultType) {
+	title, link := resultHeading(item, p.selectors)
+	if link == nil {
+		title, link = blockLink(item, p.selectors)
+	}
+	if link == nil {
+		return
+	}
+
+	resultURL := resolveResultURL(link.AttrOr("href", ""))
+	if resultURL == "" {
+		return
+	}
+
+	p.add(SearchResult{
+		Title:       title,
+		URL:         resultURL,
+		Description: resultSnippet(item, p.selectors),
+		Type:        resultType,
+	})
+}
+
+// blockLink returns the first outgoing link of a block without an h3 heading.
+// The title comes from the block heading, or from the link text.
+func blockLink(item *goquery.Selection, selectors Selectors) (string, *goquery.Selection) {
+	var link *goquery.Selection
+
+	item.Find(selectors.ResultLink).EachWithBreak(func(_ int, a *goquery.Selection) bool {
+		resultURL := resolveResultURL(a.AttrOr("href", ""))
+		if resultURL == "" {
+			return true
+		}
+		if u, err := url.Parse(resultURL); err != nil || isGoogleHost(u.Hostname()) {
+			return true
+		}
+		link = a
+		return false
+	})
+	if link == nil {
+		return "", nil
+	}
+
+	title := ""
+	if selectors.BlockTitle != "" {
+		title = cleanText(item.Find(selectors.BlockTitle).First().Text())
+	}
+	if title == "" {
+		title = cleanText(link.Text())
+	}
+
+	return title, link
+}
+
+// matches reports whether the selection matches a (possibly empty) selector
+func matches(item *goquery.Selection, selector string) bool {
+	return selector != "" && item.Is(selector)
+}
+
+// joinSelectors combines the non-empty selectors into one selector group
+func joinSelectors(selectors ...string) string {
+	parts := make([]string, 0, len(selectors))
+	for _, selector := range selectors {
+		if selector != "" {
+			parts = append(parts, selector)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// resultHeading returns the title text and the link element of a result container.
+// Current layouts put the title inside the link (<a><h3/></a>), older ones
+// put the link inside the title (<h3><a/></h3>).
+func resultHeading(item *goquery.Selection, selectors Selectors) (string, *goquery.Selection) {
+	var title string
+	var link *goquery.Selection
+
+	item.Find(selectors.ResultLink).EachWithBreak(func(_ int, a *goquery.Selection) bool {
+		heading := a.Find(selectors.ResultTitle).First()
+		if heading.Length() == 0 {
+			return true
+		}
+		title = cleanText(heading.Text())
+		link = a
+		return false
+	})
+
+	if link == nil {
+		item.Find(selectors.ResultTitle).EachWithBreak(func(_ int, heading *goquery.Selection) bool {
+			a := heading.Find(selectors.ResultLink).First()
+			if a.Length() == 0 {
+				return true
+			}
+			title = cleanText(heading.Text())
+			link = a
+			return false
+		})
+	}
+
+	if title == "" {
+		return "", nil
+	}
+
+	return title, link
+}
+
+// resultSnippet returns the description text of a result container
+func resultSnippet(item *goquery.Selection, selectors Selectors) string {
+	if selectors.ResultSnippet == "" {
+		return ""
+	}
+	return cleanText(item.Find(selectors.ResultSnippet).First().Text())
+}
+
+// resolveResultURL converts a result href into the destination URL.
+// Redirect-wrapped links (/url?q=...) are unwrapped, and anything that is not
+// an absolute http(s) URL (internal search links, anchors, javascript:) is dropped.
+func resolveResultURL(href string) string {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return ""
+	}
+
+	if u.Path == "/url" && (u.Host == "" || isGoogleHost(u.Hostname())) {
+		query := u.Query()
+		target := query.Get("q")
+		if target == "" {
+			target = query.Get("url")
+		}
+		if target == "" {
+			return ""
+		}
+		if u, err = url.Parse(target); err != nil {
+			return ""
+		}
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	if u.Host == "" {
+		return ""
+	}
+
+	return u.String()
+}
+
+// isGoogleHost reports whether host belongs to a Google search domain
+func isGoogleHost(host string) bool {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	return strings.HasPrefix(host, "google.")
+}
+
+// cleanText collapses runs of whitespace into single spaces
+func cleanText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
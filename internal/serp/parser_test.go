@@ -0,0 +1,302 @@
+package serp
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/omer/go-bot/internal/urlmatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// update rewrites the golden files from the current parser output:
+//
+//	go test ./internal/serp -run TestParsePage_Golden -update
+var update = flag.Bool("update", false, "update golden files")
+
+// ===== ParseResults tests =====
+
+func TestParseResults_Basic(t *testing.T) {
+	html := `<html><body><div id="search">
+		<div class="g">
+			<a href="https://example.com/"><h3>Example Domain</h3></a>
+			<div class="VwiC3b">This domain is for use in   illustrative examples.</div>
+		</div>
+		<div class="g">
+			<a href="https://golang.org/doc/"><h3>Documentation - The Go Programming Language</h3></a>
+			<div class="VwiC3b">Official docs.</div>
+		</div>
+	</div></body></html>`
+
+	results, err := ParseResults(strings.NewReader(html), DefaultSelectors())
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "Example Domain", results[0].Title)
+	assert.Equal(t, "https://example.com/", results[0].URL)
+	assert.Equal(t, "This domain is for use in illustrative examples.", results[0].Description)
+	assert.Equal(t, 1, results[0].Position)
+
+	assert.Equal(t, "https://golang.org/doc/", results[1].URL)
+	assert.Equal(t, 2, results[1].Position)
+}
+
+func TestParseResults_RedirectLinks(t *testing.T) {
+	html := `<html><body>
+		<div class="g"><a href="/url?q=https://example.com/page%3Fa%3D1&amp;sa=U&amp;ved=abc"><h3>Wrapped</h3></a></div>
+		<div class="g"><a href="https://www.google.com/url?url=https://example.org/&amp;rct=j"><h3>Absolute wrapped</h3></a></div>
+	</body></html>`
+
+	results, err := ParseResults(strings.NewReader(html), DefaultSelectors())
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "https://example.com/page?a=1", results[0].URL)
+	assert.Equal(t, "https://example.org/", results[1].URL)
+}
+
+func TestParseResults_NestedContainers(t *testing.T) {
+	html := `<html><body>
+		<div class="g">
+			<div class="g">
+				<a href="https://first.example.com/"><h3>First</h3></a>
+			</div>
+			<div class="g">
+				<a href="https://second.example.com/"><h3>Second</h3></a>
+			</div>
+		</div>
+		<div class="g">
+			<a href="https://third.example.com/"><h3>Third</h3></a>
+		</div>
+	</body></html>`
+
+	results, err := ParseResults(strings.NewReader(html), DefaultSelectors())
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, "First", results[0].Title)
+	assert.Equal(t, "Second", results[1].Title)
+	assert.Equal(t, "Third", results[2].Title)
+	assert.Equal(t, 3, results[2].Position)
+}
+
+func TestParseResults_LinkInsideTitle(t *testing.T) {
+	html := `<html><body>
+		<div class="g"><h3><a href="https://legacy.example.com/">Legacy layout</a></h3></div>
+	</body></html>`
+
+	results, err := ParseResults(strings.NewReader(html), DefaultSelectors())
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Legacy layout", results[0].Title)
+	assert.Equal(t, "https://legacy.example.com/", results[0].URL)
+}
+
+func TestParseResults_SkipsInvalidEntries(t *testing.T) {
+	html := `<html><body>
+		<div class="g"><a href="/search?q=related"><h3>Related search</h3></a></div>
+		<div class="g"><a href="https://notitle.example.com/">No title</a></div>
+		<div class="g"><a href="javascript:void(0)"><h3>Script</h3></a></div>
+		<div class="g"><a href="https://valid.example.com/"><h3>Valid</h3></a></div>
+	</body></html>`
+
+	results, err := ParseResults(strings.NewReader(html), DefaultSelectors())
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "https://valid.example.com/", results[0].URL)
+	assert.Equal(t, 1, results[0].Position)
+}
+
+func TestParseResults_CustomSelectors(t *testing.T) {
+	html := `<html><body>
+		<li class="result"><a class="link" href="https://custom.example.com/"><span class="title">Custom</span></a><p>Snippet</p></li>
+	</body></html>`
+
+	selectors := Selectors{
+		ResultItem:    "li.result",
+		ResultLink:    "a.link",
+		ResultTitle:   "span.title",
+		ResultSnippet: "p",
+	}
+
+	results, err := ParseResults(strings.NewReader(html), selectors)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Custom", results[0].Title)
+	assert.Equal(t, "Snippet", results[0].Description)
+}
+
+func TestParseResults_NoResults(t *testing.T) {
+	results, err := ParseResults(strings.NewReader(`<html><body><p>Nothing here</p></body></html>`), DefaultSelectors())
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+// ===== SERP feature tests =====
+
+const featureSERP = `<html><body>
+	<div id="tads">
+		<div data-text-ad="1">
+			<a href="https://www.google.com/aclk?sa=l&amp;ai=abc"></a>
+			<a href="https://shop.example.com/"><div role="heading">Example Shop - Buy Now</div></a>
+		</div>
+	</div>
+	<block-component>
+		<div class="g">
+			<div class="VwiC3b">Go is an open source programming language.</div>
+			<a href="https://go.dev/"><h3>The Go Programming Language</h3></a>
+		</div>
+	</block-component>
+	<div class="g">
+		<a href="https://example.com/"><h3>Example Domain</h3></a>
+		<table class="jmjoTe"><tr>
+			<td><a href="https://example.com/docs">Docs</a></td>
+			<td><a href="https://example.com/blog">Blog</a></td>
+		</tr></table>
+	</div>
+	<div class="related-question-pair" data-q="What is Go used for?">
+		<div class="g"><a href="https://blog.example.org/go-uses"><h3>What is Go used for</h3></a></div>
+	</div>
+	<div class="VkpGBb">
+		<span role="heading">Example Coffee</span>
+		<a href="/maps/place/example">Directions</a>
+		<a href="https://coffee.example.net/">Website</a>
+	</div>
+	<div class="VkpGBb"><span role="heading">No Website Bakery</span></div>
+	<div class="g">
+		<a href="https://go.dev/"><h3>The Go Programming Language</h3></a>
+	</div>
+</body></html>`
+
+func TestParseResults_Features(t *testing.T) {
+	results, err := ParseResults(strings.NewReader(featureSERP), DefaultSelectors())
+	require.NoError(t, err)
+	require.Len(t, results, 8)
+
+	expected := []struct {
+		resultType     ResultType
+		url            string
+		position       int
+		visualPosition int
+	}{
+		{ResultTypeAd, "https://shop.example.com/", 0, 1},
+		{ResultTypeFeaturedSnippet, "https://go.dev/", 0, 2},
+		{ResultTypeOrganic, "https://example.com/", 1, 3},
+		{ResultTypeSitelink, "https://example.com/docs", 0, 3},
+		{ResultTypeSitelink, "https://example.com/blog", 0, 3},
+		{ResultTypePeopleAlsoAsk, "https://blog.example.org/go-uses", 0, 4},
+		{ResultTypeLocalPack, "https://coffee.example.net/", 0, 5},
+		{ResultTypeOrganic, "https://go.dev/", 2, 6},
+	}
+	for i, want := range expected {
+		assert.Equal(t, want.resultType, results[i].Type, "result %d type", i)
+		assert.Equal(t, want.url, results[i].URL, "result %d url", i)
+		assert.Equal(t, want.position, results[i].Position, "result %d position", i)
+		assert.Equal(t, want.visualPosition, results[i].VisualPosition, "result %d visual position", i)
+	}
+
+	assert.Equal(t, "Example Shop - Buy Now", results[0].Title)
+	assert.Equal(t, "Go is an open source programming language.", results[1].Description)
+	assert.Equal(t, "Docs", results[3].Title)
+	assert.Equal(t, "Example Coffee", results[6].Title)
+}
+
+func TestParseResults_FeatureSelectorsDisabled(t *testing.T) {
+	selectors := DefaultSelectors()
+	selectors.AdItem = ""
+	selectors.FeaturedSnippet = ""
+	selectors.PeopleAlsoAsk = ""
+	selectors.LocalPackItem = ""
+	selectors.Sitelink = ""
+
+	results, err := ParseResults(strings.NewReader(featureSERP), selectors)
+	require.NoError(t, err)
+	for _, result := range results {
+		assert.Equal(t, ResultTypeOrganic, result.Type)
+	}
+	assert.Len(t, results, 3) // go.dev (deduplicated), example.com, blog.example.org
+}
+
+func TestMatchFeatures(t *testing.T) {
+	results, err := ParseResults(strings.NewReader(featureSERP), DefaultSelectors())
+	require.NoError(t, err)
+
+	assert.Equal(t, []ResultType{ResultTypeSitelink}, MatchFeatures(results, mustMatcher(t, "example.com")))
+
+	anySubdomain, err := urlmatch.New(urlmatch.ModeSubdomain, "*.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []ResultType{ResultTypeAd, ResultTypeSitelink}, MatchFeatures(results, anySubdomain))
+
+	assert.Equal(t, []ResultType{ResultTypeFeaturedSnippet}, MatchFeatures(results, mustMatcher(t, "go.dev")))
+	assert.Empty(t, MatchFeatures(results, mustMatcher(t, "missing.com")))
+
+	target := MatchTarget(results, mustMatcher(t, "go.dev"))
+	require.NotNil(t, target)
+	assert.Equal(t, ResultTypeOrganic, target.Type)
+	assert.Equal(t, 2, target.Position)
+}
+
+// ===== resolveResultURL tests =====
+
+func TestResolveResultURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		href     string
+		expected string
+	}{
+		{"direct", "https://example.com/a", "https://example.com/a"},
+		{"relative_redirect", "/url?q=https://example.com/&sa=U", "https://example.com/"},
+		{"redirect_url_param", "/url?url=http://example.com/b", "http://example.com/b"},
+		{"google_redirect", "https://www.google.com.tr/url?q=https://example.com/", "https://example.com/"},
+		{"foreign_url_path", "https://example.com/url?q=https://other.com/", "https://example.com/url?q=https://other.com/"},
+		{"redirect_without_target", "/url?sa=U", ""},
+		{"internal_link", "/search?q=golang", ""},
+		{"anchor", "#", ""},
+		{"empty", "", ""},
+		{"mailto", "mailto:info@example.com", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resolveResultURL(tt.href))
+		})
+	}
+}
+
+// ===== Golden file tests =====
+
+// TestParsePage_Golden parses every saved SERP in testdata/golden and
+// compares the result with the .json file next to it. Add a layout by
+// saving the page (or a failed task's DOM snapshot from data/artifacts)
+// as testdata/golden/<name>.html and running the test with -update;
+// review the generated <name>.json before committing it.
+func TestParsePage_Golden(t *testing.T) {
+	pages, err := filepath.Glob(filepath.Join("testdata", "golden", "*.html"))
+	require.NoError(t, err)
+	require.NotEmpty(t, pages)
+
+	for _, page := range pages {
+		name := strings.TrimSuffix(filepath.Base(page), ".html")
+		t.Run(name, func(t *testing.T) {
+			f, err := os.Open(page)
+			require.NoError(t, err)
+			defer f.Close()
+
+			parsed, err := ParsePage(f, DefaultProfile())
+			require.NoError(t, err)
+			got, err := json.MarshalIndent(parsed, "", "  ")
+			require.NoError(t, err)
+			got = append(got, '\n')
+
+			golden := strings.TrimSuffix(page, ".html") + ".json"
+			if *update {
+				require.NoError(t, os.WriteFile(golden, got, 0644))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err, "missing golden file; run with -update to create it")
+			assert.JSONEq(t, string(want), string(got))
+		})
+	}
+}
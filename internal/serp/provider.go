@@ -0,0 +1,94 @@
+package serp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNoMorePages is returned by a Provider when the requested result page does not exist
+var ErrNoMorePages = errors.New("no more result pages")
+
+// Provider is a source of search result pages.
+// Implementations include the live browser, recorded HTML fixtures and JSON SERP APIs.
+type Provider interface {
+	// Search returns the results shown on the given 1-based result page
+	Search(ctx context.Context, query string, page int) ([]SearchResult, error)
+}
+
+// LocaleProvider is a Provider that can serve results for a language,
+// country and device context
+type LocaleProvider interface {
+	Provider
+
+	// WithLocale returns a Provider that searches with the given locale
+	WithLocale(locale Locale) Provider
+}
+
+// BrowserProvider serves result pages by driving a browser through a Searcher
+type BrowserProvider struct {
+	searcher *Searcher
+	query    string // Query of the page currently open in the browser
+	page     int    // Number of the page currently open in the browser
+}
+
+// NewBrowserProvider creates a Provider backed by the given Searcher
+//
+// Example:
+//
+//	provider := serp.NewBrowserProvider(serp.NewSearcher(browser, logger))
+//	results, err := provider.Search(ctx, "golang tutorial", 1)
+func NewBrowserProvider(searcher *Searcher) *BrowserProvider {
+	return &BrowserProvider{
+		searcher: searcher,
+	}
+}
+
+// Search returns the results of the given page.
+// Consecutive pages are reached through the "next" button like a user would;
+// other pages are opened directly. The browser calls end when ctx is done.
+func (p *BrowserProvider) Search(ctx context.Context, query string, page int) ([]SearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if page < 1 {
+		return nil, fmt.Errorf("page must be >= 1, got %d", page)
+	}
+
+	release := p.searcher.browser.Bind(ctx)
+	defer release()
+
+	switch {
+	case page == 1:
+		if err := p.searcher.Search(query); err != nil {
+			return nil, err
+		}
+	case query == p.query && page == p.page+1:
+		hasNext, err := p.searcher.NextPage()
+		if err != nil {
+			return nil, err
+		}
+		if !hasNext {
+			return nil, ErrNoMorePages
+		}
+	default:
+		if err := p.searcher.GoToPage(query, page); err != nil {
+			return nil, err
+		}
+	}
+
+	p.query = query
+	p.page = page
+
+	return p.searcher.GetResults()
+}
+
+// WithLocale searches with the given locale from now on.
+// The device profile is fixed when the browser is created (see
+// browser.BrowserOptions.Device), so Locale.Device is not applied here.
+func (p *BrowserProvider) WithLocale(locale Locale) Provider {
+	p.searcher.SetLocale(locale)
+	p.query = ""
+	p.page = 0
+	return p
+}
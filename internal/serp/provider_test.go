@@ -0,0 +1,257 @@
+package serp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	apperrors "github.com/omer/go-bot/internal/errors"
+	"github.com/omer/go-bot/internal/urlmatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ===== FixtureProvider tests =====
+
+func writeFixture(t *testing.T, dir, query string, page int, html string) {
+	t.Helper()
+	path := NewFixtureProvider(dir).FixturePath(query, page)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(html), 0644))
+}
+
+func TestFixtureProvider_Search(t *testing.T) {
+	dir := t.TempDir()
+	writeFixture(t, dir, "golang tutorial", 1, `<html><body>
+		<div class="g"><a href="https://go.dev/doc/tutorial/"><h3>Tutorials</h3></a></div>
+		<div class="g"><a href="https://example.com/"><h3>Example</h3></a></div>
+	</body></html>`)
+
+	provider := NewFixtureProvider(dir)
+	results, err := provider.Search(context.Background(), "golang tutorial", 1)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "https://example.com/", results[1].URL)
+	assert.Equal(t, 2, results[1].Position)
+}
+
+func TestFixtureProvider_NoMorePages(t *testing.T) {
+	dir := t.TempDir()
+	writeFixture(t, dir, "golang", 1, `<html><body></body></html>`)
+
+	provider := NewFixtureProvider(dir)
+	_, err := provider.Search(context.Background(), "golang", 2)
+	assert.ErrorIs(t, err, ErrNoMorePages)
+}
+
+func TestFixtureProvider_MissingFirstPage(t *testing.T) {
+	provider := NewFixtureProvider(t.TempDir())
+	_, err := provider.Search(context.Background(), "golang", 1)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNoMorePages)
+}
+
+func TestFixtureProvider_CancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewFixtureProvider(t.TempDir()).Search(ctx, "golang", 1)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFixtureSlug(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"golang tutorial", "golang-tutorial"},
+		{"  Go  Programming! ", "go-programming"},
+		{"c++ vs go", "c-vs-go"},
+		{"yazılım kursu", "yazılım-kursu"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FixtureSlug(tt.query))
+		})
+	}
+}
+
+func TestFixtureProvider_WithLocale(t *testing.T) {
+	dir := t.TempDir()
+	locale := Locale{HL: "tr", GL: "TR"}
+	writeFixture(t, filepath.Join(dir, "hl-tr-gl-tr"), "golang", 1, `<html><body>
+		<div class="g"><a href="https://example.com.tr/"><h3>Örnek</h3></a></div>
+	</body></html>`)
+
+	provider := NewFixtureProvider(dir)
+	localized := provider.WithLocale(locale)
+
+	results, err := localized.Search(context.Background(), "golang", 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "https://example.com.tr/", results[0].URL)
+
+	// The original provider still reads the default fixtures
+	_, err = provider.Search(context.Background(), "golang", 1)
+	assert.Error(t, err)
+}
+
+func TestFixtureProvider_WithProfile(t *testing.T) {
+	dir := t.TempDir()
+	writeFixture(t, dir, "golang", 1, `<html><body>
+		<li class="result"><a href="https://golang.org/"><h3>Go</h3></a></li>
+	</body></html>`)
+
+	provider := NewFixtureProvider(dir)
+	results, err := provider.Search(context.Background(), "golang", 1)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	profile := DefaultProfile().Override(SelectorProfile{ResultItem: SelectorList{"li.result"}})
+	results, err = provider.WithProfile(profile).Search(context.Background(), "golang", 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "https://golang.org/", results[0].URL)
+}
+
+func TestFixtureFile(t *testing.T) {
+	assert.Equal(t, filepath.Join("testdata", "golang-tutorial", "page-2.html"),
+		FixtureFile("testdata", Locale{}, "Golang Tutorial", 2))
+	assert.Equal(t, filepath.Join("testdata", "hl-tr-gl-tr", "golang", "page-1.html"),
+		FixtureFile("testdata", Locale{HL: "tr", GL: "TR"}, "golang", 1))
+}
+
+// ===== HTTPProvider tests =====
+
+func TestHTTPProvider_Search(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "golang", r.URL.Query().Get("q"))
+		assert.Equal(t, "2", r.URL.Query().Get("page"))
+		assert.Equal(t, "secret", r.Header.Get("X-API-Key"))
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"results": []map[string]interface{}{
+				{"title": "Go", "url": "https://go.dev/", "position": 11},
+				{"title": "Example", "url": "https://example.com/"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	provider, err := NewHTTPProvider(HTTPProviderConfig{
+		Endpoint: server.URL,
+		Headers:  map[string]string{"X-API-Key": "secret"},
+	})
+	require.NoError(t, err)
+
+	results, err := provider.Search(context.Background(), "golang", 2)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, 11, results[0].Position)
+	assert.Equal(t, "https://example.com/", results[1].URL)
+	assert.Equal(t, 2, results[1].Position)
+}
+
+func TestHTTPProvider_EmptyPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"results": []}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewHTTPProvider(HTTPProviderConfig{Endpoint: server.URL})
+	require.NoError(t, err)
+
+	results, err := provider.Search(context.Background(), "golang", 1)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	_, err = provider.Search(context.Background(), "golang", 3)
+	assert.ErrorIs(t, err, ErrNoMorePages)
+}
+
+func TestHTTPProvider_ClientErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid api key", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	provider, err := NewHTTPProvider(HTTPProviderConfig{Endpoint: server.URL})
+	require.NoError(t, err)
+
+	_, err = provider.Search(context.Background(), "golang", 1)
+	assert.True(t, apperrors.Is(err, apperrors.ErrorTypeConfig))
+	assert.False(t, apperrors.IsRetryable(err))
+}
+
+func TestHTTPProvider_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	provider, err := NewHTTPProvider(HTTPProviderConfig{Endpoint: server.URL})
+	require.NoError(t, err)
+
+	_, err = provider.Search(context.Background(), "golang", 1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status 429")
+	assert.True(t, apperrors.IsRetryable(err), "rate limits are retried")
+}
+
+func TestHTTPProvider_WithLocale(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		assert.Equal(t, "tr", params.Get("hl"))
+		assert.Equal(t, "TR", params.Get("gl"))
+		assert.Equal(t, "google.com.tr", params.Get("google_domain"))
+		assert.Equal(t, "mobile", params.Get("device"))
+		_, _ = w.Write([]byte(`{"results": [{"title": "Go", "url": "https://go.dev/"}]}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewHTTPProvider(HTTPProviderConfig{Endpoint: server.URL})
+	require.NoError(t, err)
+
+	localized := provider.WithLocale(Locale{HL: "tr", GL: "TR", Domain: "google.com.tr", Device: "mobile"})
+	results, err := localized.Search(context.Background(), "golang", 1)
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.NotContains(t, provider.requestURL("golang", 1), "hl=")
+}
+
+func TestNewHTTPProvider_InvalidEndpoint(t *testing.T) {
+	_, err := NewHTTPProvider(HTTPProviderConfig{})
+	assert.Error(t, err)
+
+	_, err = NewHTTPProvider(HTTPProviderConfig{Endpoint: "ftp://example.com"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported endpoint scheme")
+}
+
+// ===== MatchTarget tests =====
+
+func mustMatcher(t *testing.T, pattern string) *urlmatch.Matcher {
+	t.Helper()
+	m, err := urlmatch.New("", pattern)
+	require.NoError(t, err)
+	return m
+}
+
+func TestMatchTarget(t *testing.T) {
+	results := []SearchResult{
+		{URL: "https://go.dev/", Position: 1},
+		{URL: "https://notexample.com/", Position: 2},
+		{URL: "https://www.example.com/page", Position: 3},
+	}
+
+	result := MatchTarget(results, mustMatcher(t, "example.com"))
+	require.NotNil(t, result)
+	assert.Equal(t, 3, result.Position)
+
+	assert.Nil(t, MatchTarget(results, mustMatcher(t, "missing.com")))
+}
@@ -0,0 +1,265 @@
+package serp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/omer/go-bot/internal/urlmatch"
+)
+
+// ScanOutcome describes how a rank scan ended
+type ScanOutcome string
+
+const (
+	// OutcomeFound means the target was found within the scanned pages
+	OutcomeFound ScanOutcome = "found"
+	// OutcomeNotInTop means the target was not among the scanned results.
+	// This is a valid ranking answer ("not in top N"), not an error.
+	OutcomeNotInTop ScanOutcome = "not_in_top"
+)
+
+// ScanResult holds the outcome of a multi-page rank scan
+type ScanResult struct {
+	Outcome        ScanOutcome   // found or not_in_top
+	Result         *SearchResult // Matching result with its absolute positions (nil if not found)
+	Page           int           // Page the target was found on (0 if not found)
+	Slot           int           // 1-based organic position of the target on its page (0 if not found)
+	Pages          int           // Number of pages that were scanned
+	Depth          int           // Number of organic results scanned (the N in "not in top N")
+	Position       int           // Absolute organic position across all scanned pages (0 if not found)
+	VisualPosition int           // Absolute position counting ads and SERP features (0 if not found)
+	Features       []ResultType  // Non-organic blocks (ads, snippets, ...) that point at the target
+	Competitors    []TargetRank  // Ranks of the competitor domains, in SearchOptions.Competitors order
+}
+
+// TargetRank holds the rank of one competitor domain found by a scan
+type TargetRank struct {
+	Target         string // Competitor domain as configured
+	URL            string // Matching result URL (empty if not found)
+	Page           int    // Page the domain was found on (0 if not found)
+	Position       int    // Absolute organic position (0 if not found)
+	VisualPosition int    // Absolute position counting ads and SERP features (0 if not found)
+}
+
+// Found returns true if the competitor was found
+func (r TargetRank) Found() bool {
+	return r.Position > 0
+}
+
+// Found returns true if the target was found
+func (r *ScanResult) Found() bool {
+	return r.Outcome == OutcomeFound
+}
+
+// Scan walks the result pages of opts.Keyword until opts.TargetURL is found
+// or opts.MaxPages pages have been scanned. Positions count organic results
+// only and are absolute across pages: the 4th result on page 2 after 10
+// results on page 1 is position 14. A target that is not found yields
+// OutcomeNotInTop rather than an error.
+//
+// Competitor domains in opts.Competitors are ranked from the same result
+// pages. The scan then continues past the target until every domain was
+// found or the page limit is reached.
+//
+// A non-zero opts.Locale requires a LocaleProvider.
+//
+// Example:
+//
+//	scan, err := serp.Scan(ctx, provider, serp.SearchOptions{
+//	    Keyword:   "golang tutorial",
+//	    TargetURL: "example.com",
+//	    MaxPages:  5,
+//	})
+//	if err == nil && !scan.Found() {
+//	    fmt.Printf("not in top %d\n", scan.Depth)
+//	}
+func Scan(ctx context.Context, provider Provider, opts SearchOptions) (*ScanResult, error) {
+	if opts.Keyword == "" {
+		return nil, fmt.Errorf("keyword cannot be empty")
+	}
+	if opts.TargetURL == "" {
+		return nil, fmt.Errorf("target URL cannot be empty")
+	}
+	target, err := urlmatch.New(opts.Match, opts.TargetURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target URL: %w", err)
+	}
+	competitors := make([]*urlmatch.Matcher, len(opts.Competitors))
+	for i, competitor := range opts.Competitors {
+		if competitors[i], err = urlmatch.New("", competitor); err != nil {
+			return nil, fmt.Errorf("invalid competitor %q: %w", competitor, err)
+		}
+	}
+	if provider, err = localize(provider, opts.Locale); err != nil {
+		return nil, err
+	}
+	if opts.MaxPages <= 0 {
+		opts.MaxPages = 5
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	scan := &ScanResult{
+		Outcome:     OutcomeNotInTop,
+		Competitors: make([]TargetRank, len(opts.Competitors)),
+	}
+	for i, competitor := range opts.Competitors {
+		scan.Competitors[i].Target = competitor
+	}
+	visual := 0 // Blocks on the pages before the current one
+
+	for page := 1; page <= opts.MaxPages; page++ {
+		results, err := searchPage(ctx, provider, opts, page)
+		if errors.Is(err, ErrNoMorePages) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("search failed on page %d: %w", page, err)
+		}
+
+		ranker := pageRanker{results: results, offset: scan.Depth, visualOffset: visual}
+		scan.Pages = page
+		scan.Depth += countOrganic(results)
+		visual += countVisual(results)
+		scan.addFeatures(MatchFeatures(results, target))
+
+		if !scan.Found() {
+			if result, slot, ok := ranker.rank(target); ok {
+				scan.Outcome = OutcomeFound
+				scan.Result = &result
+				scan.Page = page
+				scan.Slot = slot
+				scan.Position = result.Position
+				scan.VisualPosition = result.VisualPosition
+			}
+		}
+
+		for i := range scan.Competitors {
+			if scan.Competitors[i].Found() {
+				continue
+			}
+			if result, _, ok := ranker.rank(competitors[i]); ok {
+				scan.Competitors[i].URL = result.URL
+				scan.Competitors[i].Page = page
+				scan.Competitors[i].Position = result.Position
+				scan.Competitors[i].VisualPosition = result.VisualPosition
+			}
+		}
+
+		if scan.complete() {
+			return scan, nil
+		}
+
+		// An empty page means the engine has nothing more to show
+		if len(results) == 0 {
+			break
+		}
+	}
+
+	return scan, nil
+}
+
+// localize applies a locale to the provider.
+// The zero Locale leaves the provider unchanged.
+func localize(provider Provider, locale Locale) (Provider, error) {
+	if locale.IsZero() {
+		return provider, nil
+	}
+	if err := locale.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid locale: %w", err)
+	}
+	localized, ok := provider.(LocaleProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider %T does not support locale settings", provider)
+	}
+	return localized.WithLocale(locale), nil
+}
+
+// complete returns true once the target and every competitor were found
+func (r *ScanResult) complete() bool {
+	if !r.Found() {
+		return false
+	}
+	for _, competitor := range r.Competitors {
+		if !competitor.Found() {
+			return false
+		}
+	}
+	return true
+}
+
+// searchPage fetches one result page within the page timeout
+func searchPage(ctx context.Context, provider Provider, opts SearchOptions, page int) ([]SearchResult, error) {
+	if opts.PageTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.PageTimeout)
+		defer cancel()
+	}
+	return provider.Search(ctx, opts.Keyword, page)
+}
+
+// pageRanker converts page-local ranks into absolute ranks
+type pageRanker struct {
+	results      []SearchResult
+	offset       int // Organic results on the previous pages
+	visualOffset int // Blocks on the previous pages
+}
+
+// rank returns the first organic result matched by target with absolute
+// positions, and its organic slot on the page
+func (p pageRanker) rank(target *urlmatch.Matcher) (SearchResult, int, bool) {
+	i := matchIndex(p.results, target)
+	if i < 0 {
+		return SearchResult{}, 0, false
+	}
+
+	result := p.results[i]
+	slot := countOrganic(p.results[:i+1])
+	result.Position = p.offset + slot
+	if result.VisualPosition > 0 {
+		result.VisualPosition += p.visualOffset
+	} else {
+		result.VisualPosition = p.visualOffset + i + 1
+	}
+
+	return result, slot, true
+}
+
+// addFeatures records feature types that were not seen on an earlier page
+func (r *ScanResult) addFeatures(features []ResultType) {
+	for _, feature := range features {
+		if !slices.Contains(r.Features, feature) {
+			r.Features = append(r.Features, feature)
+		}
+	}
+}
+
+// countOrganic returns the number of organic results
+func countOrganic(results []SearchResult) int {
+	n := 0
+	for _, result := range results {
+		if result.IsOrganic() {
+			n++
+		}
+	}
+	return n
+}
+
+// countVisual returns the number of visual blocks on a page.
+// Results without a visual position count as one block each.
+func countVisual(results []SearchResult) int {
+	n := 0
+	for _, result := range results {
+		if result.VisualPosition == 0 {
+			n++
+		} else if result.VisualPosition > n {
+			n = result.VisualPosition
+		}
+	}
+	return n
+}
@@ -0,0 +1,352 @@
+package serp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/omer/go-bot/internal/urlmatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pageProvider serves canned result pages; missing pages end the scan
+type pageProvider struct {
+	pages map[int][]SearchResult
+	errs  map[int]error
+	calls []int
+}
+
+func (p *pageProvider) Search(_ context.Context, _ string, page int) ([]SearchResult, error) {
+	p.calls = append(p.calls, page)
+	if err := p.errs[page]; err != nil {
+		return nil, err
+	}
+	results, ok := p.pages[page]
+	if !ok {
+		return nil, ErrNoMorePages
+	}
+	return results, nil
+}
+
+// resultPage builds n results for a page; the target is placed at slot (1-based) if > 0
+func resultPage(n, slot int, target string) []SearchResult {
+	results := make([]SearchResult, n)
+	for i := range results {
+		results[i] = SearchResult{
+			URL:      fmt.Sprintf("https://site%d.example.org/", i+1),
+			Position: i + 1,
+		}
+	}
+	if slot > 0 {
+		results[slot-1].URL = target
+	}
+	return results
+}
+
+// ===== Scan tests =====
+
+func TestScan_AbsolutePosition(t *testing.T) {
+	provider := &pageProvider{pages: map[int][]SearchResult{
+		1: resultPage(10, 0, ""),
+		2: resultPage(10, 4, "https://www.example.com/docs"),
+	}}
+
+	scan, err := Scan(context.Background(), provider, SearchOptions{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+		MaxPages:  5,
+	})
+	require.NoError(t, err)
+	assert.True(t, scan.Found())
+	assert.Equal(t, OutcomeFound, scan.Outcome)
+	assert.Equal(t, 14, scan.Position)
+	assert.Equal(t, 2, scan.Page)
+	assert.Equal(t, 4, scan.Slot)
+	assert.Equal(t, 2, scan.Pages)
+	require.NotNil(t, scan.Result)
+	assert.Equal(t, 14, scan.Result.Position)
+	assert.Equal(t, "https://www.example.com/docs", scan.Result.URL)
+}
+
+func TestScan_UnevenPages(t *testing.T) {
+	provider := &pageProvider{pages: map[int][]SearchResult{
+		1: resultPage(8, 0, ""),
+		2: resultPage(9, 0, ""),
+		3: resultPage(10, 1, "https://example.com/"),
+	}}
+
+	scan, err := Scan(context.Background(), provider, SearchOptions{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 18, scan.Position)
+	assert.Equal(t, 3, scan.Page)
+	assert.Equal(t, 1, scan.Slot)
+}
+
+func TestScan_NotInTop(t *testing.T) {
+	provider := &pageProvider{pages: map[int][]SearchResult{
+		1: resultPage(10, 0, ""),
+		2: resultPage(10, 0, ""),
+		3: resultPage(10, 2, "https://example.com/"),
+	}}
+
+	scan, err := Scan(context.Background(), provider, SearchOptions{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+		MaxPages:  2,
+	})
+	require.NoError(t, err)
+	assert.False(t, scan.Found())
+	assert.Equal(t, OutcomeNotInTop, scan.Outcome)
+	assert.Equal(t, 20, scan.Depth)
+	assert.Equal(t, 2, scan.Pages)
+	assert.Equal(t, 0, scan.Position)
+	assert.Nil(t, scan.Result)
+	assert.Equal(t, []int{1, 2}, provider.calls)
+}
+
+func TestScan_StopsAtLastPage(t *testing.T) {
+	provider := &pageProvider{pages: map[int][]SearchResult{
+		1: resultPage(7, 0, ""),
+	}}
+
+	scan, err := Scan(context.Background(), provider, SearchOptions{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+		MaxPages:  5,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeNotInTop, scan.Outcome)
+	assert.Equal(t, 7, scan.Depth)
+	assert.Equal(t, 1, scan.Pages)
+	assert.Equal(t, []int{1, 2}, provider.calls)
+}
+
+func TestScan_OrganicAndVisualPositions(t *testing.T) {
+	page1 := resultPage(10, 0, "")
+	page2 := []SearchResult{
+		{URL: "https://example.com/landing", Type: ResultTypeAd, VisualPosition: 1},
+		{URL: "https://www.example.com/", Type: ResultTypeFeaturedSnippet, VisualPosition: 2},
+		{URL: "https://other.org/", Type: ResultTypeOrganic, Position: 1, VisualPosition: 3},
+		{URL: "https://other.org/docs", Type: ResultTypeSitelink, VisualPosition: 3},
+		{URL: "https://example.com/", Type: ResultTypeOrganic, Position: 2, VisualPosition: 4},
+	}
+	provider := &pageProvider{pages: map[int][]SearchResult{1: page1, 2: page2}}
+
+	scan, err := Scan(context.Background(), provider, SearchOptions{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+	})
+	require.NoError(t, err)
+	assert.True(t, scan.Found())
+	assert.Equal(t, 12, scan.Position)
+	assert.Equal(t, 2, scan.Slot)
+	assert.Equal(t, 14, scan.VisualPosition)
+	assert.Equal(t, []ResultType{ResultTypeAd, ResultTypeFeaturedSnippet}, scan.Features)
+}
+
+func TestScan_FeaturesWithoutOrganicRank(t *testing.T) {
+	provider := &pageProvider{pages: map[int][]SearchResult{
+		1: {
+			{URL: "https://example.com/", Type: ResultTypeLocalPack, VisualPosition: 1},
+			{URL: "https://other.org/", Type: ResultTypeOrganic, Position: 1, VisualPosition: 2},
+		},
+	}}
+
+	scan, err := Scan(context.Background(), provider, SearchOptions{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeNotInTop, scan.Outcome)
+	assert.Equal(t, 1, scan.Depth)
+	assert.Equal(t, []ResultType{ResultTypeLocalPack}, scan.Features)
+}
+
+func TestScan_MatchMode(t *testing.T) {
+	provider := &pageProvider{pages: map[int][]SearchResult{
+		1: {
+			{URL: "https://example.com/", Position: 1},
+			{URL: "https://example.com/blog/go-tips", Position: 2},
+		},
+	}}
+
+	scan, err := Scan(context.Background(), provider, SearchOptions{
+		Keyword:   "golang",
+		TargetURL: "example.com/blog",
+		Match:     urlmatch.ModePrefix,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, scan.Position)
+
+	_, err = Scan(context.Background(), provider, SearchOptions{
+		Keyword:   "golang",
+		TargetURL: "com",
+		Match:     urlmatch.ModeDomain,
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid target URL")
+}
+
+func TestScan_Competitors(t *testing.T) {
+	page1 := resultPage(10, 3, "https://rival.com/go")
+	page2 := resultPage(10, 5, "https://www.example.com/")
+	page3 := resultPage(10, 2, "https://blog.other.io/")
+	provider := &pageProvider{pages: map[int][]SearchResult{1: page1, 2: page2, 3: page3}}
+
+	scan, err := Scan(context.Background(), provider, SearchOptions{
+		Keyword:     "golang",
+		TargetURL:   "example.com",
+		Competitors: []string{"rival.com", "*.other.io", "missing.net"},
+		MaxPages:    4,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 15, scan.Position)
+	assert.Equal(t, 2, scan.Page)
+
+	// The scan keeps going after the target until the page limit because missing.net is never found
+	assert.Equal(t, 3, scan.Pages)
+	assert.Equal(t, []int{1, 2, 3, 4}, provider.calls)
+
+	require.Len(t, scan.Competitors, 3)
+	assert.Equal(t, "rival.com", scan.Competitors[0].Target)
+	assert.Equal(t, 3, scan.Competitors[0].Position)
+	assert.Equal(t, 1, scan.Competitors[0].Page)
+	assert.Equal(t, "https://rival.com/go", scan.Competitors[0].URL)
+	assert.Equal(t, 22, scan.Competitors[1].Position)
+	assert.Equal(t, 3, scan.Competitors[1].Page)
+	assert.False(t, scan.Competitors[2].Found())
+	assert.Equal(t, 0, scan.Competitors[2].Position)
+}
+
+func TestScan_StopsWhenAllTargetsFound(t *testing.T) {
+	provider := &pageProvider{pages: map[int][]SearchResult{
+		1: resultPage(10, 3, "https://rival.com/"),
+		2: resultPage(10, 1, "https://example.com/"),
+		3: resultPage(10, 0, ""),
+	}}
+
+	scan, err := Scan(context.Background(), provider, SearchOptions{
+		Keyword:     "golang",
+		TargetURL:   "example.com",
+		Competitors: []string{"rival.com"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 11, scan.Position)
+	assert.Equal(t, 3, scan.Competitors[0].Position)
+	assert.Equal(t, []int{1, 2}, provider.calls)
+}
+
+func TestScan_InvalidCompetitor(t *testing.T) {
+	_, err := Scan(context.Background(), &pageProvider{}, SearchOptions{
+		Keyword:     "golang",
+		TargetURL:   "example.com",
+		Competitors: []string{"co.uk"},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid competitor")
+}
+
+func TestScan_Locale(t *testing.T) {
+	dir := t.TempDir()
+	writeFixture(t, filepath.Join(dir, FixtureSlug("hl=de,gl=DE")), "golang", 1, `<html><body>
+		<div class="g"><a href="https://go.dev/"><h3>Go</h3></a></div>
+		<div class="g"><a href="https://example.de/"><h3>Beispiel</h3></a></div>
+	</body></html>`)
+
+	scan, err := Scan(context.Background(), NewFixtureProvider(dir), SearchOptions{
+		Keyword:   "golang",
+		TargetURL: "example.de",
+		Locale:    Locale{HL: "de", GL: "DE"},
+		MaxPages:  1,
+	})
+	require.NoError(t, err)
+	assert.True(t, scan.Found())
+	assert.Equal(t, 2, scan.Position)
+}
+
+func TestScan_LocaleNotSupported(t *testing.T) {
+	provider := &pageProvider{pages: map[int][]SearchResult{1: resultPage(10, 1, "https://example.com/")}}
+
+	_, err := Scan(context.Background(), provider, SearchOptions{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+		Locale:    Locale{GL: "TR"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not support locale settings")
+	assert.Empty(t, provider.calls)
+
+	_, err = Scan(context.Background(), provider, SearchOptions{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+		Locale:    Locale{Device: "watch"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid locale")
+}
+
+func TestScan_ProviderError(t *testing.T) {
+	provider := &pageProvider{
+		pages: map[int][]SearchResult{1: resultPage(10, 0, "")},
+		errs:  map[int]error{2: errors.New("CAPTCHA detected")},
+	}
+
+	_, err := Scan(context.Background(), provider, SearchOptions{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "search failed on page 2")
+}
+
+// hangingProvider serves its pages and hangs on the others until ctx is done
+type hangingProvider struct {
+	pageProvider
+	deadlines []bool // Whether each call had a deadline
+}
+
+func (p *hangingProvider) Search(ctx context.Context, query string, page int) ([]SearchResult, error) {
+	_, ok := ctx.Deadline()
+	p.deadlines = append(p.deadlines, ok)
+	if _, ok := p.pages[page]; ok {
+		return p.pageProvider.Search(ctx, query, page)
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestScan_PageTimeout(t *testing.T) {
+	provider := &hangingProvider{pageProvider: pageProvider{
+		pages: map[int][]SearchResult{1: resultPage(10, 0, "")},
+	}}
+
+	start := time.Now()
+	_, err := Scan(context.Background(), provider, SearchOptions{
+		Keyword:     "golang",
+		TargetURL:   "example.com",
+		PageTimeout: 20 * time.Millisecond,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "search failed on page 2")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, []bool{true, true}, provider.deadlines)
+}
+
+func TestScan_InvalidOptions(t *testing.T) {
+	provider := &pageProvider{}
+
+	_, err := Scan(context.Background(), provider, SearchOptions{TargetURL: "example.com"})
+	assert.Error(t, err)
+
+	_, err = Scan(context.Background(), provider, SearchOptions{Keyword: "golang"})
+	assert.Error(t, err)
+	assert.Empty(t, provider.calls)
+}
@@ -3,81 +3,242 @@
 package serp
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
+	"path"
 	"strings"
 	"time"
 
 	"github.com/omer/go-bot/internal/browser"
+	apperrors "github.com/omer/go-bot/internal/errors"
 	"github.com/omer/go-bot/internal/logger"
+	"github.com/omer/go-bot/internal/urlmatch"
+)
+
+// ResultType identifies the SERP block a result was found in
+type ResultType string
+
+const (
+	// ResultTypeOrganic is a regular organic listing
+	ResultTypeOrganic ResultType = "organic"
+	// ResultTypeAd is a paid search ad
+	ResultTypeAd ResultType = "ad"
+	// ResultTypeFeaturedSnippet is the answer box shown above the organic results
+	ResultTypeFeaturedSnippet ResultType = "featured_snippet"
+	// ResultTypePeopleAlsoAsk is the source link of a "People also ask" answer
+	ResultTypePeopleAlsoAsk ResultType = "people_also_ask"
+	// ResultTypeLocalPack is a business website in the map/local pack
+	ResultTypeLocalPack ResultType = "local_pack"
+	// ResultTypeSitelink is a sub-link shown under an organic listing
+	ResultTypeSitelink ResultType = "sitelink"
 )
 
 // SearchResult represents a single search result from Google
 type SearchResult struct {
-	Title       string // Result title (h3 text)
-	URL         string // Result URL (href attribute)
-	Description string // Result description/snippet
-	Position    int    // Position in search results (1-based)
+	Title          string     `json:"title"`           // Result title (h3 text)
+	URL            string     `json:"url"`             // Result URL (href attribute)
+	Description    string     `json:"description"`     // Result description/snippet
+	Type           ResultType `json:"type,omitempty"`  // SERP block the result belongs to (empty = organic)
+	Position       int        `json:"position"`        // Organic rank (1-based, 0 for non-organic results)
+	VisualPosition int        `json:"visual_position"` // Rank among all blocks in page order, ads and features included
+}
+
+// IsOrganic returns true for organic listings.
+// Results without a type (e.g. from a SERP API) are treated as organic.
+func (r SearchResult) IsOrganic() bool {
+	return r.Type == "" || r.Type == ResultTypeOrganic
 }
 
 // Selectors holds CSS selectors for Google search elements
 type Selectors struct {
-	SearchBox    string // Search input box selector
-	SearchButton string // Search button selector
-	ResultItem   string // Individual result container selector
-	ResultLink   string // Result link selector (relative to result item)
-	ResultTitle  string // Result title selector (relative to result item)
-	NextButton   string // Next page button selector
-	CaptchaFrame string // CAPTCHA iframe selector
+	SearchBox       string // Search input box selector
+	SearchButton    string // Search button selector
+	ResultItem      string // Individual result container selector
+	ResultLink      string // Result link selector (relative to result item)
+	ResultTitle     string // Result title selector (relative to result item)
+	ResultSnippet   string // Result description selector (relative to result item)
+	NextButton      string // Next page button selector
+	CaptchaFrame    string // CAPTCHA iframe selector
+	AdItem          string // Paid ad container selector
+	FeaturedSnippet string // Featured snippet container selector
+	PeopleAlsoAsk   string // "People also ask" question container selector
+	LocalPackItem   string // Local pack entry selector
+	Sitelink        string // Sitelink selector (relative to result item)
+	BlockTitle      string // Fallback title selector for ads and local entries
 }
 
-// DefaultSelectors returns the default Google search selectors
+// DefaultSelectors returns the primary selectors of the default profile
 func DefaultSelectors() Selectors {
-	return Selectors{
-		SearchBox:    "textarea[name='q']",
-		SearchButton: "input[name='btnK']",
-		ResultItem:   "div.g",
-		ResultLink:   "a[href]",
-		ResultTitle:  "h3",
-		NextButton:   "a#pnnext",
-		CaptchaFrame: "iframe[src*='recaptcha']",
-	}
+	return DefaultProfile().Primary()
 }
 
+// ErrTargetNotFound is returned when the target URL is not among the results
+var ErrTargetNotFound = errors.New("target URL not found")
+
 // Searcher handles Google search operations
 type Searcher struct {
-	browser   *browser.Browser
-	selectors Selectors
+	browser   browser.Driver
+	profile   SelectorProfile // Selectors with fallbacks
+	fallbacks []Fallback      // Fallback selectors used so far
 	logger    *logger.Logger
+	page      int      // Result page currently open (0 = no search yet)
+	locale    Locale   // Language, country and domain of the searches
+	baseURL   *url.URL // Search engine root replacing Google (nil = Google on the locale domain)
 }
 
 // SearchOptions holds configuration for search operations
 type SearchOptions struct {
-	Keyword   string        // Search keyword
-	TargetURL string        // Target URL to find
-	MaxPages  int           // Maximum pages to search (default: 5)
-	Timeout   time.Duration // Timeout for search operation (default: 30s)
+	Keyword     string        // Search keyword
+	TargetURL   string        // Target URL to find
+	Match       urlmatch.Mode // How TargetURL is matched (default: urlmatch.DefaultMode)
+	Competitors []string      // Competitor domains ranked from the same pages (urlmatch.DefaultMode)
+	Locale      Locale        // Language, country, domain and device context (zero = provider default)
+	MaxPages    int           // Maximum pages to search (default: 5)
+	Timeout     time.Duration // Timeout for the whole scan (0 = no timeout)
+	PageTimeout time.Duration // Timeout for each result page (0 = no timeout)
 }
 
-// NewSearcher creates a new Searcher instance
+// NewSearcher creates a new Searcher instance that uses the default selector profile
 //
 // Example:
 //
 //	searcher := serp.NewSearcher(browser, logger)
-func NewSearcher(b *browser.Browser, log *logger.Logger) *Searcher {
-	return &Searcher{
-		browser:   b,
-		selectors: DefaultSelectors(),
-		logger:    log,
-	}
+func NewSearcher(b browser.Driver, log *logger.Logger) *Searcher {
+	return NewSearcherWithProfile(b, log, DefaultProfile())
 }
 
 // NewSearcherWithSelectors creates a new Searcher with custom selectors
-func NewSearcherWithSelectors(b *browser.Browser, log *logger.Logger, selectors Selectors) *Searcher {
+func NewSearcherWithSelectors(b browser.Driver, log *logger.Logger, selectors Selectors) *Searcher {
+	return NewSearcherWithProfile(b, log, ProfileFromSelectors("custom", selectors))
+}
+
+// NewSearcherWithProfile creates a new Searcher that uses a selector profile.
+// Elements are looked up with the primary selector of each field first and
+// with its fallbacks when the primary one no longer matches.
+//
+// Example:
+//
+//	profile, _ := serp.LookupProfile("google")
+//	searcher := serp.NewSearcherWithProfile(browser, logger, profile)
+func NewSearcherWithProfile(b browser.Driver, log *logger.Logger, profile SelectorProfile) *Searcher {
 	return &Searcher{
-		browser:   b,
-		selectors: selectors,
-		logger:    log,
+		browser: b,
+		profile: profile,
+		logger:  log,
+	}
+}
+
+// Profile returns the selector profile of the searcher
+func (s *Searcher) Profile() SelectorProfile {
+	return s.profile
+}
+
+// Fallbacks returns the fallback selectors used so far because primary
+// selectors stopped matching
+func (s *Searcher) Fallbacks() []Fallback {
+	return append([]Fallback(nil), s.fallbacks...)
+}
+
+// pick returns the first selector of list that matches the current page.
+// The primary selector is returned when none matches.
+func (s *Searcher) pick(field string, list SelectorList) string {
+	for i, selector := range list {
+		if !s.browser.ElementExists(selector) {
+			continue
+		}
+		if i > 0 {
+			s.useFallback(Fallback{Field: field, Primary: list[0], Selector: selector})
+		}
+		return selector
+	}
+	return list.Primary()
+}
+
+// useFallback records a fallback and warns that the primary selector of
+// its field stopped matching. Each fallback is reported once per searcher.
+func (s *Searcher) useFallback(fallback Fallback) {
+	for _, used := range s.fallbacks {
+		if used == fallback {
+			return
+		}
+	}
+	s.fallbacks = append(s.fallbacks, fallback)
+
+	s.logger.Warn("Primary selector stopped matching, using fallback", map[string]interface{}{
+		"profile":  s.profile.String(),
+		"field":    fallback.Field,
+		"primary":  fallback.Primary,
+		"fallback": fallback.Selector,
+	})
+}
+
+// SetLocale sets the language, country and domain used by later searches
+//
+// Example:
+//
+//	searcher.SetLocale(serp.Locale{HL: "tr", GL: "TR", Domain: "google.com.tr"})
+func (s *Searcher) SetLocale(locale Locale) {
+	s.locale = locale
+}
+
+// SetBaseURL makes the searcher use another search engine root, such as a
+// local mock server, instead of Google on the locale domain. Paths and the
+// locale parameters stay the same: with "http://127.0.0.1:8080" searches
+// open http://127.0.0.1:8080/search?q=...&hl=... An empty URL restores Google.
+//
+// Example:
+//
+//	err := searcher.SetBaseURL(server.URL)
+func (s *Searcher) SetBaseURL(baseURL string) error {
+	if baseURL == "" {
+		s.baseURL = nil
+		return nil
+	}
+
+	u, err := ParseBaseURL(baseURL)
+	if err != nil {
+		return apperrors.NewValidationError(err.Error())
+	}
+	s.baseURL = u
+	return nil
+}
+
+// ParseBaseURL parses a search engine root such as "http://127.0.0.1:8080"
+func ParseBaseURL(baseURL string) (*url.URL, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: expected http(s)://host[:port]", baseURL)
+	}
+	return u, nil
+}
+
+// pageURL moves a URL on the Google locale domain onto the base URL, if one is set
+func (s *Searcher) pageURL(googleURL string) string {
+	if s.baseURL == nil {
+		return googleURL
+	}
+
+	u, err := url.Parse(googleURL)
+	if err != nil {
+		return googleURL
 	}
+	u.Scheme = s.baseURL.Scheme
+	u.Host = s.baseURL.Host
+	rebased := path.Join("/", s.baseURL.Path, u.Path)
+	if strings.HasSuffix(u.Path, "/") && rebased != "/" {
+		rebased += "/"
+	}
+	u.Path = rebased
+	return u.String()
+}
+
+// Locale returns the locale used by searches
+func (s *Searcher) Locale() Locale {
+	return s.locale
 }
 
 // Search performs a Google search with the given keyword
@@ -87,49 +248,46 @@ func NewSearcherWithSelectors(b *browser.Browser, log *logger.Logger, selectors
 //	err := searcher.Search("golang tutorial")
 func (s *Searcher) Search(keyword string) error {
 	if keyword == "" {
-		return fmt.Errorf("keyword cannot be empty")
+		return apperrors.NewValidationError("keyword cannot be empty")
 	}
 
 	s.logger.Info("Starting search", map[string]interface{}{
 		"keyword": keyword,
 	})
 
-	// Navigate to Google
-	err := s.browser.Navigate("https://www.google.com")
+	// Navigate to Google on the locale domain
+	err := s.browser.Navigate(s.pageURL(s.locale.HomeURL()))
 	if err != nil {
 		return fmt.Errorf("failed to navigate to Google: %w", err)
 	}
 
 	// Wait for search box to be visible
-	err = s.browser.WaitVisible(s.selectors.SearchBox)
+	err = s.browser.WaitVisible(s.profile.SearchBox.Any())
 	if err != nil {
-		return fmt.Errorf("search box not found: %w", err)
+		return apperrors.NewSelectorError("search box not found", err)
 	}
+	searchBox := s.pick("search_box", s.profile.SearchBox)
 
-	// Type the keyword
-	err = s.browser.Type(s.selectors.SearchBox, keyword)
+	// Type the keyword and submit the search (press Enter). Type clears the
+	// box first, so the Enter must be sent with the keyword.
+	err = s.browser.Type(searchBox, keyword+"\n")
 	if err != nil {
 		return fmt.Errorf("failed to type keyword: %w", err)
 	}
 
-	// Small delay before submitting
-	time.Sleep(500 * time.Millisecond)
-
-	// Submit the search (press Enter)
-	err = s.browser.Type(s.selectors.SearchBox, "\n")
-	if err != nil {
-		return fmt.Errorf("failed to submit search: %w", err)
-	}
-
 	// Wait for results to load
-	time.Sleep(2 * time.Second)
+	if err := s.browser.Sleep(2 * time.Second); err != nil {
+		return err
+	}
 
 	// Check for CAPTCHA
-	if s.browser.ElementExists(s.selectors.CaptchaFrame) {
+	if s.browser.ElementExists(s.profile.CaptchaFrame.Any()) {
 		s.logger.Warn("CAPTCHA detected", nil)
-		return fmt.Errorf("CAPTCHA detected - please solve manually or use a different proxy")
+		return apperrors.NewCaptchaError("CAPTCHA detected - please solve manually or use a different proxy")
 	}
 
+	s.page = 1
+
 	s.logger.Info("Search completed successfully", nil)
 	return nil
 }
@@ -143,22 +301,27 @@ func (s *Searcher) GetResults() ([]SearchResult, error) {
 	s.logger.Debug("Parsing search results", nil)
 
 	// Wait for results to be visible
-	err := s.browser.WaitVisible(s.selectors.ResultItem)
+	err := s.browser.WaitVisible(s.profile.ResultItem.Any())
 	if err != nil {
-		return nil, fmt.Errorf("no results found: %w", err)
+		return nil, apperrors.NewSelectorError("no results found", err)
 	}
 
-	// In a real implementation, we would use chromedp to extract multiple elements
-	// For now, we'll return a placeholder implementation
-	// This will be properly implemented with chromedp.Nodes and iteration
-
-	results := []SearchResult{}
+	// Extract results from a snapshot of the current DOM
+	html, err := s.browser.GetHTML()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page HTML: %w", err)
+	}
 
-	// Note: This is a simplified version. Full implementation would:
-	// 1. Use chromedp.Nodes to get all result items
-	// 2. Iterate through each item
-	// 3. Extract title, URL, description for each
-	// 4. Return the complete list
+	results, fallbacks, err := ParseResultsWithProfile(strings.NewReader(html), s.profile)
+	if err != nil {
+		return nil, apperrors.NewSelectorError("failed to parse results", err)
+	}
+	for _, fallback := range fallbacks {
+		s.useFallback(fallback)
+	}
+	if len(results) == 0 {
+		return nil, apperrors.NewSelectorError(fmt.Sprintf("no results parsed from visible %s items", s.profile.ResultItem.Any()), nil)
+	}
 
 	s.logger.Info("Found search results", map[string]interface{}{
 		"count": len(results),
@@ -168,7 +331,9 @@ func (s *Searcher) GetResults() ([]SearchResult, error) {
 }
 
 // FindTarget searches for a specific target URL in the search results
-// Returns the SearchResult and its position if found, error otherwise
+// Returns the SearchResult and its position if found, error otherwise.
+// The target is matched with urlmatch.DefaultMode: by domain, or by path
+// prefix when it contains a path.
 //
 // Example:
 //
@@ -178,43 +343,75 @@ func (s *Searcher) FindTarget(targetURL string) (*SearchResult, error) {
 		return nil, fmt.Errorf("target URL cannot be empty")
 	}
 
+	target, err := urlmatch.New("", targetURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target URL: %w", err)
+	}
+
 	s.logger.Info("Searching for target URL", map[string]interface{}{
 		"target": targetURL,
 	})
 
-	// Normalize target URL (remove protocol, www, trailing slash)
-	normalizedTarget := normalizeURL(targetURL)
-
 	results, err := s.GetResults()
 	if err != nil {
 		return nil, err
 	}
 
-	// Search through results
-	for _, result := range results {
-		normalizedResultURL := normalizeURL(result.URL)
-
-		// Check if result URL contains target
-		if strings.Contains(normalizedResultURL, normalizedTarget) {
-			s.logger.Info("Target found", map[string]interface{}{
-				"position": result.Position,
-				"url":      result.URL,
-			})
-			return &result, nil
-		}
+	if result := MatchTarget(results, target); result != nil {
+		s.logger.Info("Target found", map[string]interface{}{
+			"position": result.Position,
+			"url":      result.URL,
+		})
+		return result, nil
 	}
 
 	s.logger.Warn("Target not found in current page", map[string]interface{}{
 		"target": targetURL,
 	})
-	return nil, fmt.Errorf("target URL not found: %s", targetURL)
+	return nil, fmt.Errorf("%w: %s", ErrTargetNotFound, targetURL)
+}
+
+// MatchTarget returns the first organic result matched by target, or nil
+func MatchTarget(results []SearchResult, target *urlmatch.Matcher) *SearchResult {
+	if i := matchIndex(results, target); i >= 0 {
+		return &results[i]
+	}
+	return nil
+}
+
+// matchIndex returns the index of the first organic result matched by target, or -1
+func matchIndex(results []SearchResult, target *urlmatch.Matcher) int {
+	for i := range results {
+		if results[i].IsOrganic() && target.Match(results[i].URL) {
+			return i
+		}
+	}
+	return -1
+}
+
+// MatchFeatures returns the distinct non-organic result types (ads, featured
+// snippets, local pack entries, ...) matched by target, in page order
+func MatchFeatures(results []SearchResult, target *urlmatch.Matcher) []ResultType {
+	var features []ResultType
+	seen := make(map[ResultType]bool)
+	for _, result := range results {
+		if result.IsOrganic() || seen[result.Type] {
+			continue
+		}
+		if target.Match(result.URL) {
+			seen[result.Type] = true
+			features = append(features, result.Type)
+		}
+	}
+
+	return features
 }
 
 // HasCaptcha checks if a CAPTCHA is present on the page.
 // It checks for various CAPTCHA indicators including reCAPTCHA, Cloudflare, and generic CAPTCHA elements.
 func (s *Searcher) HasCaptcha() bool {
 	// Check for reCAPTCHA iframe
-	if s.browser.ElementExists(s.selectors.CaptchaFrame) {
+	if s.browser.ElementExists(s.profile.CaptchaFrame.Any()) {
 		s.logger.Info("reCAPTCHA detected (iframe)")
 		return true
 	}
@@ -258,7 +455,9 @@ func (s *Searcher) HasCaptcha() bool {
 	return false
 }
 
-// DetectAndLogCaptcha checks for CAPTCHA and logs detailed information
+// DetectAndLogCaptcha checks for CAPTCHA and logs detailed information.
+// The worker saves the screenshot and DOM of the challenge page as debug
+// artifacts when the task fails.
 func (s *Searcher) DetectAndLogCaptcha() bool {
 	hasCaptcha := s.HasCaptcha()
 
@@ -271,30 +470,7 @@ func (s *Searcher) DetectAndLogCaptcha() bool {
 			"title":  title,
 			"action": "Please solve manually or use CAPTCHA solving service",
 		})
-
-		// Take screenshot for debugging (optional)
-		if screenshot, err := s.browser.Screenshot(); err == nil {
-			s.logger.WithField("size", len(screenshot)).Debug("CAPTCHA screenshot taken")
-		}
 	}
 
 	return hasCaptcha
 }
-
-// normalizeURL removes protocol, www prefix, and trailing slashes
-func normalizeURL(url string) string {
-	// Convert to lowercase first
-	url = strings.ToLower(url)
-
-	// Remove protocol
-	url = strings.TrimPrefix(url, "https://")
-	url = strings.TrimPrefix(url, "http://")
-
-	// Remove www
-	url = strings.TrimPrefix(url, "www.")
-
-	// Remove trailing slash
-	url = strings.TrimSuffix(url, "/")
-
-	return url
-}
@@ -0,0 +1,278 @@
+package serp
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// SelectorList is an ordered list of selectors for one page element. The
+// first selector is the primary one; the others are fallbacks tried in order
+// when the primary one stops matching, e.g. after a layout change.
+type SelectorList []string
+
+// Primary returns the primary selector (empty for an empty list)
+func (l SelectorList) Primary() string {
+	if len(l) == 0 {
+		return ""
+	}
+	return l[0]
+}
+
+// Any returns a selector group matching any selector of the list
+func (l SelectorList) Any() string {
+	return joinSelectors(l...)
+}
+
+// UnmarshalJSON accepts a single selector as well as a list of selectors
+func (l *SelectorList) UnmarshalJSON(data []byte) error {
+	var selector string
+	if err := json.Unmarshal(data, &selector); err == nil {
+		*l = nil
+		if selector != "" {
+			*l = SelectorList{selector}
+		}
+		return nil
+	}
+
+	var selectors []string
+	if err := json.Unmarshal(data, &selectors); err != nil {
+		return fmt.Errorf("selector must be a string or a list of strings: %w", err)
+	}
+	*l = selectors
+	return nil
+}
+
+// SelectorProfile is a named, versioned set of selectors for a search engine
+// layout, with fallbacks for every element. Bump the version whenever the
+// selectors change, so results can be traced back to the selectors that
+// produced them.
+type SelectorProfile struct {
+	Name            string       `json:"name"`
+	Version         int          `json:"version"`
+	SearchBox       SelectorList `json:"search_box"`
+	SearchButton    SelectorList `json:"search_button"`
+	ResultItem      SelectorList `json:"result_item"`
+	ResultLink      SelectorList `json:"result_link"`
+	ResultTitle     SelectorList `json:"result_title"`
+	ResultSnippet   SelectorList `json:"result_snippet"`
+	NextButton      SelectorList `json:"next_button"`
+	CaptchaFrame    SelectorList `json:"captcha_frame"`
+	AdItem          SelectorList `json:"ad_item"`
+	FeaturedSnippet SelectorList `json:"featured_snippet"`
+	PeopleAlsoAsk   SelectorList `json:"people_also_ask"`
+	LocalPackItem   SelectorList `json:"local_pack_item"`
+	Sitelink        SelectorList `json:"sitelink"`
+	BlockTitle      SelectorList `json:"block_title"`
+}
+
+// Fallback records a page element found with a fallback selector because
+// its primary selector no longer matched
+type Fallback struct {
+	Field    string `json:"field"`    // Profile field, e.g. result_item
+	Primary  string `json:"primary"`  // Primary selector that did not match
+	Selector string `json:"selector"` // Fallback selector that matched
+}
+
+// String returns the fallback as "field: primary -> selector"
+func (f Fallback) String() string {
+	return fmt.Sprintf("%s: %s -> %s", f.Field, f.Primary, f.Selector)
+}
+
+// selectorField pairs a profile field with its Selectors counterpart
+type selectorField struct {
+	name     string
+	list     *SelectorList
+	selector *string
+}
+
+// fields returns the fields of the profile, paired with the fields of s
+// (s may be nil when only the profile side is needed)
+func (p *SelectorProfile) fields(s *Selectors) []selectorField {
+	if s == nil {
+		s = &Selectors{}
+	}
+	return []selectorField{
+		{"search_box", &p.SearchBox, &s.SearchBox},
+		{"search_button", &p.SearchButton, &s.SearchButton},
+		{"result_item", &p.ResultItem, &s.ResultItem},
+		{"result_link", &p.ResultLink, &s.ResultLink},
+		{"result_title", &p.ResultTitle, &s.ResultTitle},
+		{"result_snippet", &p.ResultSnippet, &s.ResultSnippet},
+		{"next_button", &p.NextButton, &s.NextButton},
+		{"captcha_frame", &p.CaptchaFrame, &s.CaptchaFrame},
+		{"ad_item", &p.AdItem, &s.AdItem},
+		{"featured_snippet", &p.FeaturedSnippet, &s.FeaturedSnippet},
+		{"people_also_ask", &p.PeopleAlsoAsk, &s.PeopleAlsoAsk},
+		{"local_pack_item", &p.LocalPackItem, &s.LocalPackItem},
+		{"sitelink", &p.Sitelink, &s.Sitelink},
+		{"block_title", &p.BlockTitle, &s.BlockTitle},
+	}
+}
+
+// String returns the profile as "name@version"
+func (p SelectorProfile) String() string {
+	return fmt.Sprintf("%s@%d", p.Name, p.Version)
+}
+
+// Primary returns the primary selector of every field
+func (p SelectorProfile) Primary() Selectors {
+	var selectors Selectors
+	for _, field := range p.fields(&selectors) {
+		*field.selector = field.list.Primary()
+	}
+	return selectors
+}
+
+// Resolve picks the first selector of every field for which matches
+// returns true. Fields with no matching selector keep their primary
+// selector: most elements (ads, local packs, ...) are optional. The returned
+// fallbacks list the fields whose primary selector did not match.
+func (p SelectorProfile) Resolve(matches func(selector string) bool) (Selectors, []Fallback) {
+	var selectors Selectors
+	var fallbacks []Fallback
+
+	for _, field := range p.fields(&selectors) {
+		list := *field.list
+		*field.selector = list.Primary()
+		for i, selector := range list {
+			if !matches(selector) {
+				continue
+			}
+			if i > 0 {
+				*field.selector = selector
+				fallbacks = append(fallbacks, Fallback{Field: field.name, Primary: list[0], Selector: selector})
+			}
+			break
+		}
+	}
+
+	return selectors, fallbacks
+}
+
+// Override returns a copy of the profile with the selectors of o put in
+// front of its own, so they become the primary selectors and the profile's
+// selectors their fallbacks. The name and version of o are used when set.
+//
+// Example:
+//
+//	profile := serp.DefaultProfile().Override(serp.SelectorProfile{
+//	    ResultItem: serp.SelectorList{"div.MjjYud"},
+//	})
+func (p SelectorProfile) Override(o SelectorProfile) SelectorProfile {
+	merged := p
+	if o.Name != "" {
+		merged.Name = o.Name
+		merged.Version = o.Version
+	}
+
+	overrides := o.fields(nil)
+	for i, field := range merged.fields(nil) {
+		*field.list = mergeSelectors(*overrides[i].list, *field.list)
+	}
+	return merged
+}
+
+// mergeSelectors concatenates selector lists, dropping duplicates and empty selectors
+func mergeSelectors(lists ...SelectorList) SelectorList {
+	var merged SelectorList
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, selector := range list {
+			if selector == "" || seen[selector] {
+				continue
+			}
+			seen[selector] = true
+			merged = append(merged, selector)
+		}
+	}
+	return merged
+}
+
+// Validate checks that the profile is named and has the selectors a search needs
+func (p SelectorProfile) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("selector profile name cannot be empty")
+	}
+	if p.Version < 0 {
+		return fmt.Errorf("selector profile %s: version must be non-negative, got %d", p.Name, p.Version)
+	}
+	if len(p.SearchBox) == 0 {
+		return fmt.Errorf("selector profile %s: search_box cannot be empty", p.Name)
+	}
+	if len(p.ResultItem) == 0 {
+		return fmt.Errorf("selector profile %s: result_item cannot be empty", p.Name)
+	}
+	return nil
+}
+
+// DefaultProfileName is the name of the profile used when none is configured
+const DefaultProfileName = "google"
+
+// profiles holds the built-in selector profiles
+var profiles = map[string]func() SelectorProfile{
+	DefaultProfileName: googleProfile,
+}
+
+// googleProfile returns the selectors of the current Google layout, with
+// fallbacks for the layouts Google serves to some clients
+func googleProfile() SelectorProfile {
+	return SelectorProfile{
+		Name:            DefaultProfileName,
+		Version:         2,
+		SearchBox:       SelectorList{"textarea[name='q']", "input[name='q']"},
+		SearchButton:    SelectorList{"input[name='btnK']", "button[type='submit']"},
+		ResultItem:      SelectorList{"div.g", "div.MjjYud", "div.tF2Cxc"},
+		ResultLink:      SelectorList{"a[href]"},
+		ResultTitle:     SelectorList{"h3", "div[role='heading'][aria-level='3']"},
+		ResultSnippet:   SelectorList{"div.VwiC3b, div[data-sncf], span.aCOpRe, div.IsZvec", "div[style*='-webkit-line-clamp']"},
+		NextButton:      SelectorList{"a#pnnext", "a[aria-label='Next page']"},
+		CaptchaFrame:    SelectorList{"iframe[src*='recaptcha']", "iframe[title*='reCAPTCHA']"},
+		AdItem:          SelectorList{"div[data-text-ad]"},
+		FeaturedSnippet: SelectorList{"block-component, div.xpdopen"},
+		PeopleAlsoAsk:   SelectorList{"div.related-question-pair"},
+		LocalPackItem:   SelectorList{"div.VkpGBb"},
+		Sitelink:        SelectorList{"table.jmjoTe a[href], div.HiHjCd a[href], div.usJj9c a[href]"},
+		BlockTitle:      SelectorList{"div[role='heading'], span[role='heading']"},
+	}
+}
+
+// DefaultProfile returns the built-in profile for Google
+func DefaultProfile() SelectorProfile {
+	return googleProfile()
+}
+
+// LookupProfile returns a built-in selector profile by name
+//
+// Example:
+//
+//	profile, ok := serp.LookupProfile("google")
+func LookupProfile(name string) (SelectorProfile, bool) {
+	profile, ok := profiles[name]
+	if !ok {
+		return SelectorProfile{}, false
+	}
+	return profile(), true
+}
+
+// ProfileNames returns the names of the built-in selector profiles
+func ProfileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProfileFromSelectors creates a profile without fallbacks from a fixed
+// selector set
+func ProfileFromSelectors(name string, selectors Selectors) SelectorProfile {
+	profile := SelectorProfile{Name: name}
+	for _, field := range profile.fields(&selectors) {
+		if *field.selector != "" {
+			*field.list = SelectorList{*field.selector}
+		}
+	}
+	return profile
+}
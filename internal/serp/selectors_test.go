@@ -0,0 +1,173 @@
+package serp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ===== SelectorList tests =====
+
+func TestSelectorList_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    SelectorList
+		wantErr bool
+	}{
+		{name: "string", input: `"div.g"`, want: SelectorList{"div.g"}},
+		{name: "empty_string", input: `""`, want: nil},
+		{name: "list", input: `["div.g", "div.MjjYud"]`, want: SelectorList{"div.g", "div.MjjYud"}},
+		{name: "number", input: `3`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var list SelectorList
+			err := json.Unmarshal([]byte(tt.input), &list)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, list)
+		})
+	}
+}
+
+func TestSelectorList_PrimaryAndAny(t *testing.T) {
+	list := SelectorList{"div.g", "div.MjjYud"}
+
+	assert.Equal(t, "div.g", list.Primary())
+	assert.Equal(t, "div.g, div.MjjYud", list.Any())
+	assert.Empty(t, SelectorList{}.Primary())
+}
+
+// ===== SelectorProfile tests =====
+
+func TestDefaultProfile(t *testing.T) {
+	profile := DefaultProfile()
+
+	assert.Equal(t, "google@2", profile.String())
+	assert.NoError(t, profile.Validate())
+	assert.Equal(t, DefaultSelectors(), profile.Primary())
+
+	found, ok := LookupProfile(DefaultProfileName)
+	require.True(t, ok)
+	assert.Equal(t, profile, found)
+	assert.Contains(t, ProfileNames(), DefaultProfileName)
+
+	_, ok = LookupProfile("bing")
+	assert.False(t, ok)
+}
+
+func TestSelectorProfile_Resolve(t *testing.T) {
+	profile := SelectorProfile{
+		Name:       "test",
+		SearchBox:  SelectorList{"textarea[name='q']", "input[name='q']"},
+		ResultItem: SelectorList{"div.g", "div.MjjYud"},
+		AdItem:     SelectorList{"div[data-text-ad]", "div.uEierd"},
+	}
+	present := map[string]bool{
+		"textarea[name='q']": true, // Primary matches
+		"div.MjjYud":         true, // Only the fallback matches
+	}
+
+	selectors, fallbacks := profile.Resolve(func(selector string) bool {
+		return present[selector]
+	})
+
+	assert.Equal(t, "textarea[name='q']", selectors.SearchBox)
+	assert.Equal(t, "div.MjjYud", selectors.ResultItem)
+	assert.Equal(t, "div[data-text-ad]", selectors.AdItem, "no match keeps the primary selector")
+	assert.Equal(t, []Fallback{{Field: "result_item", Primary: "div.g", Selector: "div.MjjYud"}}, fallbacks)
+	assert.Equal(t, "result_item: div.g -> div.MjjYud", fallbacks[0].String())
+}
+
+func TestSelectorProfile_Override(t *testing.T) {
+	base := DefaultProfile()
+
+	profile := base.Override(SelectorProfile{
+		ResultItem: SelectorList{"div.MjjYud", "div.g"},
+		NextButton: SelectorList{"a.next"},
+	})
+
+	assert.Equal(t, base.String(), profile.String())
+	assert.Equal(t, SelectorList{"div.MjjYud", "div.g", "div.tF2Cxc"}, profile.ResultItem)
+	assert.Equal(t, append(SelectorList{"a.next"}, base.NextButton...), profile.NextButton)
+	assert.Equal(t, base.SearchBox, profile.SearchBox)
+	assert.Equal(t, SelectorList{"div.g", "div.MjjYud", "div.tF2Cxc"}, base.ResultItem, "base profile is unchanged")
+
+	renamed := base.Override(SelectorProfile{Name: "google-eu", Version: 5})
+	assert.Equal(t, "google-eu@5", renamed.String())
+}
+
+func TestSelectorProfile_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile SelectorProfile
+		errMsg  string
+	}{
+		{name: "valid", profile: SelectorProfile{Name: "p", SearchBox: SelectorList{"input"}, ResultItem: SelectorList{"div"}}},
+		{name: "no_name", profile: SelectorProfile{SearchBox: SelectorList{"input"}, ResultItem: SelectorList{"div"}}, errMsg: "name cannot be empty"},
+		{name: "negative_version", profile: SelectorProfile{Name: "p", Version: -1, SearchBox: SelectorList{"input"}, ResultItem: SelectorList{"div"}}, errMsg: "version must be non-negative"},
+		{name: "no_search_box", profile: SelectorProfile{Name: "p", ResultItem: SelectorList{"div"}}, errMsg: "search_box cannot be empty"},
+		{name: "no_result_item", profile: SelectorProfile{Name: "p", SearchBox: SelectorList{"input"}}, errMsg: "result_item cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.profile.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestProfileFromSelectors(t *testing.T) {
+	profile := ProfileFromSelectors("custom", Selectors{SearchBox: "input#q", ResultItem: "div.result"})
+
+	assert.Equal(t, "custom@0", profile.String())
+	assert.Equal(t, SelectorList{"input#q"}, profile.SearchBox)
+	assert.Equal(t, SelectorList{"div.result"}, profile.ResultItem)
+	assert.Empty(t, profile.NextButton)
+}
+
+// ===== ParseResultsWithProfile tests =====
+
+func TestParseResultsWithProfile_Fallback(t *testing.T) {
+	html := `<html><body>
+		<div class="MjjYud"><a href="https://golang.org/"><h3>The Go Programming Language</h3></a></div>
+		<div class="MjjYud"><a href="https://example.com/"><h3>Example</h3></a></div>
+	</body></html>`
+
+	// The primary result container no longer matches
+	results, err := ParseResults(strings.NewReader(html), DefaultSelectors())
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	results, fallbacks, err := ParseResultsWithProfile(strings.NewReader(html), DefaultProfile())
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "https://golang.org/", results[0].URL)
+	assert.Equal(t, 2, results[1].Position)
+	assert.Equal(t, []Fallback{{Field: "result_item", Primary: "div.g", Selector: "div.MjjYud"}}, fallbacks)
+}
+
+func TestParseResultsWithProfile_Primary(t *testing.T) {
+	html := `<html><body>
+		<div class="g"><a href="https://golang.org/"><h3>The Go Programming Language</h3></a></div>
+	</body></html>`
+
+	results, fallbacks, err := ParseResultsWithProfile(strings.NewReader(html), DefaultProfile())
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Empty(t, fallbacks)
+}
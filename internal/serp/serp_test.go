@@ -1,11 +1,17 @@
 package serp
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/omer/go-bot/internal/browser"
+	"github.com/omer/go-bot/internal/browser/browsertest"
+	apperrors "github.com/omer/go-bot/internal/errors"
 	"github.com/omer/go-bot/internal/logger"
+	"github.com/omer/go-bot/internal/serp/serptest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -54,7 +60,7 @@ func TestNewSearcher(t *testing.T) {
 	assert.NotNil(t, searcher)
 	assert.NotNil(t, searcher.browser)
 	assert.NotNil(t, searcher.logger)
-	assert.NotNil(t, searcher.selectors)
+	assert.Equal(t, DefaultProfile(), searcher.Profile())
 }
 
 func TestNewSearcherWithSelectors(t *testing.T) {
@@ -79,8 +85,8 @@ func TestNewSearcherWithSelectors(t *testing.T) {
 
 	searcher := NewSearcherWithSelectors(b, log, customSelectors)
 	assert.NotNil(t, searcher)
-	assert.Equal(t, "input.custom", searcher.selectors.SearchBox)
-	assert.Equal(t, "div.custom-result", searcher.selectors.ResultItem)
+	assert.Equal(t, "input.custom", searcher.Profile().Primary().SearchBox)
+	assert.Equal(t, "div.custom-result", searcher.Profile().Primary().ResultItem)
 }
 
 func TestDefaultSelectors(t *testing.T) {
@@ -92,6 +98,11 @@ func TestDefaultSelectors(t *testing.T) {
 	assert.NotEmpty(t, selectors.ResultTitle)
 	assert.NotEmpty(t, selectors.NextButton)
 	assert.NotEmpty(t, selectors.CaptchaFrame)
+	assert.NotEmpty(t, selectors.AdItem)
+	assert.NotEmpty(t, selectors.FeaturedSnippet)
+	assert.NotEmpty(t, selectors.PeopleAlsoAsk)
+	assert.NotEmpty(t, selectors.LocalPackItem)
+	assert.NotEmpty(t, selectors.Sitelink)
 }
 
 // ===== Search tests =====
@@ -114,22 +125,52 @@ func TestSearch_Integration(t *testing.T) {
 		t.Skip("Skipping integration test in short mode")
 	}
 
+	server := serptest.NewServer(serptest.Config{})
+	defer server.Close()
+
 	searcher, b := createTestSearcher(t)
 	defer b.Close()
+	require.NoError(t, searcher.SetBaseURL(server.URL))
 
-	// Perform a search
+	// Perform a search on the local search engine
 	err := searcher.Search("golang")
-	if err != nil {
-		// Network or Google-related issues are acceptable in tests
-		t.Logf("Search failed (network issue expected): %v", err)
-		t.Skip("Skipping - network required")
-	}
+	require.NoError(t, err)
 
-	// Verify we're on a Google results page
+	// Verify we're on its results page
 	url, err := b.GetCurrentURL()
-	if err == nil {
-		assert.Contains(t, url, "google.com")
-	}
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(url, server.URL+"/search?"), url)
+
+	results, err := searcher.GetResults()
+	require.NoError(t, err)
+	require.Len(t, results, serptest.PerPage)
+	assert.Equal(t, server.ResultsFor("golang")[0].URL, results[0].URL)
+}
+
+func TestSetBaseURL(t *testing.T) {
+	searcher := &Searcher{locale: Locale{HL: "tr", GL: "TR", Domain: "google.com.tr"}}
+
+	// Google on the locale domain by default
+	assert.Equal(t, "https://www.google.com.tr/search?gl=TR&hl=tr&q=golang&start=10",
+		searcher.pageURL(searcher.locale.SearchURL("golang", 2)))
+
+	require.NoError(t, searcher.SetBaseURL("http://127.0.0.1:8080"))
+	assert.Equal(t, "http://127.0.0.1:8080/?gl=TR&hl=tr", searcher.pageURL(searcher.locale.HomeURL()))
+	assert.Equal(t, "http://127.0.0.1:8080/search?gl=TR&hl=tr&q=golang&start=10",
+		searcher.pageURL(searcher.locale.SearchURL("golang", 2)))
+
+	require.NoError(t, searcher.SetBaseURL("https://serp.example.com/google/"))
+	assert.Equal(t, "https://serp.example.com/google/search?gl=TR&hl=tr&q=golang",
+		searcher.pageURL(searcher.locale.SearchURL("golang", 1)))
+
+	// Invalid URLs keep the current base URL
+	err := searcher.SetBaseURL("127.0.0.1:8080")
+	assert.True(t, apperrors.Is(err, apperrors.ErrorTypeValidation), "got %v", err)
+	assert.Error(t, searcher.SetBaseURL("ftp://example.com"))
+	assert.Equal(t, "https://serp.example.com/google/?gl=TR&hl=tr", searcher.pageURL(searcher.locale.HomeURL()))
+
+	require.NoError(t, searcher.SetBaseURL(""))
+	assert.Equal(t, "https://www.google.com.tr/?gl=TR&hl=tr", searcher.pageURL(searcher.locale.HomeURL()))
 }
 
 // ===== GetResults tests =====
@@ -240,59 +281,6 @@ func TestHasNoCaptcha(t *testing.T) {
 	assert.False(t, hasCaptcha)
 }
 
-// ===== URL normalization tests =====
-
-func TestNormalizeURL(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected string
-	}{
-		{
-			name:     "http_protocol",
-			input:    "http://example.com",
-			expected: "example.com",
-		},
-		{
-			name:     "https_protocol",
-			input:    "https://example.com",
-			expected: "example.com",
-		},
-		{
-			name:     "with_www",
-			input:    "https://www.example.com",
-			expected: "example.com",
-		},
-		{
-			name:     "trailing_slash",
-			input:    "https://example.com/",
-			expected: "example.com",
-		},
-		{
-			name:     "with_path",
-			input:    "https://example.com/page",
-			expected: "example.com/page",
-		},
-		{
-			name:     "uppercase",
-			input:    "https://Example.COM",
-			expected: "example.com",
-		},
-		{
-			name:     "complex",
-			input:    "HTTPS://WWW.Example.COM/",
-			expected: "example.com",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := normalizeURL(tt.input)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
 // ===== Navigation tests =====
 
 func TestNextPage_NoButton(t *testing.T) {
@@ -365,6 +353,30 @@ func TestGetCurrentPage(t *testing.T) {
 	assert.Equal(t, 1, page)
 }
 
+func TestPageFromURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected int
+		ok       bool
+	}{
+		{"first_page", "https://www.google.com/search?q=golang&start=0", 1, true},
+		{"second_page", "https://www.google.com/search?q=golang&start=10", 2, true},
+		{"custom_page_size", "https://www.google.com/search?q=golang&start=40&num=20", 3, true},
+		{"no_start", "https://www.google.com/search?q=golang", 0, false},
+		{"invalid_start", "https://www.google.com/search?q=golang&start=abc", 0, false},
+		{"blank", "about:blank", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, ok := pageFromURL(tt.url)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, page)
+		})
+	}
+}
+
 func TestScrollToResult_InvalidPosition(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping searcher test in short mode")
@@ -441,3 +453,123 @@ func TestSelectors_Struct(t *testing.T) {
 	assert.Equal(t, "a.next", selectors.NextButton)
 	assert.Equal(t, "iframe.captcha", selectors.CaptchaFrame)
 }
+
+// ===== Fake driver tests =====
+
+// fakeHomeHTML is a Google home page with the search box in a form
+const fakeHomeHTML = `<html><head><title>Google</title></head><body>
+<form action="/search" method="GET"><textarea name="q"></textarea></form>
+</body></html>`
+
+// newFakeSearcher returns a searcher on a fake driver that serves the
+// Google home page and the given pages, by URL
+func newFakeSearcher(t *testing.T, pages map[string]string) (*Searcher, *browsertest.Driver) {
+	t.Helper()
+
+	log, err := logger.New(logger.Config{Level: logger.ErrorLevel})
+	require.NoError(t, err)
+
+	driver := browsertest.NewDriver().AddPage("https://www.google.com/", fakeHomeHTML)
+	for pageURL, html := range pages {
+		driver.AddPage(pageURL, html)
+	}
+	return NewSearcher(driver, log), driver
+}
+
+// goldenPage returns the HTML of a golden SERP file
+func goldenPage(t *testing.T, name string) string {
+	t.Helper()
+
+	html, err := os.ReadFile(filepath.Join("testdata", "golden", name+".html"))
+	require.NoError(t, err)
+	return string(html)
+}
+
+func TestSearcher_FakeDriver_SearchAndNextPage(t *testing.T) {
+	searcher, driver := newFakeSearcher(t, map[string]string{
+		"https://www.google.com/search?q=golang+tutorial":          goldenPage(t, "classic"),
+		"https://www.google.com/search?q=golang+tutorial&start=10": goldenPage(t, "mjjyud"),
+	})
+
+	require.NoError(t, searcher.Search("golang tutorial"))
+	assert.Equal(t, []browsertest.Call{{Method: "Type", Args: []string{"textarea[name='q']", "golang tutorial\n"}}},
+		driver.CallsTo("Type"), "the keyword is submitted with Enter")
+
+	results, err := searcher.GetResults()
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+	assert.Equal(t, "https://go.dev/doc/tutorial/getting-started", results[0].URL)
+
+	target, err := searcher.FindTarget("gobyexample.com")
+	require.NoError(t, err)
+	assert.Equal(t, 3, target.Position, "sitelinks share the position of their result")
+
+	// The second page only matches the fallback selectors
+	hasNext, err := searcher.NextPage()
+	require.NoError(t, err)
+	assert.True(t, hasNext)
+	page, err := searcher.GetCurrentPage()
+	require.NoError(t, err)
+	assert.Equal(t, 2, page)
+
+	results, err = searcher.GetResults()
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, "https://blog.example.dev/rust-vs-go", results[0].URL)
+	assert.Contains(t, searcher.Fallbacks(), Fallback{Field: "result_item", Primary: "div.g", Selector: "div.MjjYud"})
+
+	_, err = searcher.FindTarget("gobyexample.com")
+	assert.ErrorIs(t, err, ErrTargetNotFound)
+}
+
+func TestSearcher_FakeDriver_Captcha(t *testing.T) {
+	searcher, _ := newFakeSearcher(t, map[string]string{
+		"https://www.google.com/search?q=golang": goldenPage(t, "captcha"),
+	})
+
+	err := searcher.Search("golang")
+	assert.True(t, apperrors.Is(err, apperrors.ErrorTypeCaptcha), "got %v", err)
+	assert.True(t, searcher.HasCaptcha())
+}
+
+func TestSearcher_FakeDriver_NoResults(t *testing.T) {
+	searcher, _ := newFakeSearcher(t, map[string]string{
+		"https://www.google.com/search?q=xqzvkkw+plorbt": goldenPage(t, "no_results"),
+	})
+
+	require.NoError(t, searcher.Search("xqzvkkw plorbt"))
+
+	_, err := searcher.GetResults()
+	assert.True(t, apperrors.Is(err, apperrors.ErrorTypeSelector), "got %v", err)
+
+	hasNext, err := searcher.NextPage()
+	require.NoError(t, err)
+	assert.False(t, hasNext)
+}
+
+func TestSearcher_FakeDriver_NoSearchBox(t *testing.T) {
+	searcher, driver := newFakeSearcher(t, nil)
+	driver.AddPage("https://www.google.com/", `<html><body><p>Before you continue to Google</p></body></html>`)
+
+	err := searcher.Search("golang")
+	assert.True(t, apperrors.Is(err, apperrors.ErrorTypeSelector), "got %v", err)
+}
+
+func TestSearcher_FakeDriver_GoToPage(t *testing.T) {
+	searcher, driver := newFakeSearcher(t, map[string]string{
+		"http://127.0.0.1:8080/search?gl=TR&hl=tr&q=golang&start=20": goldenPage(t, "legacy"),
+	})
+	searcher.SetLocale(Locale{HL: "tr", GL: "TR"})
+	require.NoError(t, searcher.SetBaseURL("http://127.0.0.1:8080"))
+
+	require.NoError(t, searcher.GoToPage("golang", 3))
+	assert.Equal(t, "Navigate(http://127.0.0.1:8080/search?gl=TR&hl=tr&q=golang&start=20)", driver.CallsTo("Navigate")[0].String())
+
+	page, err := searcher.GetCurrentPage()
+	require.NoError(t, err)
+	assert.Equal(t, 3, page)
+
+	// Unknown pages fail with the driver's network error
+	err = searcher.GoToPage("golang", 4)
+	assert.True(t, apperrors.Is(err, apperrors.ErrorTypeNetwork), "got %v", err)
+}
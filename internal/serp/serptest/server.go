@@ -0,0 +1,237 @@
+// Package serptest provides a local search engine for testing the SERP
+// automation without Google or network access. The server serves a Google-like
+// home page, deterministic result pages with pagination, a "sorry" challenge
+// page with a reCAPTCHA frame for blocked queries, and slow responses.
+//
+// Point a Searcher (or WorkerPoolConfig.SearchURL) at Server.URL to run the
+// full pipeline against it with only a local Chromium.
+package serptest
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PerPage is the number of organic results on a result page, matching the
+// start parameter of serp.Locale.SearchURL
+const PerPage = 10
+
+// Result is an organic result served by the server
+type Result struct {
+	Title   string // Result title (h3)
+	URL     string // Result link
+	Snippet string // Result description
+}
+
+// Config holds the behavior of a Server
+type Config struct {
+	Results   map[string][]Result      // Results of a query across all pages; an empty list serves a no-results page (default: generated)
+	Generated int                      // Number of results generated for queries missing from Results (default: 30)
+	Blocked   []string                 // Queries answered with the challenge page
+	Delay     time.Duration            // Delay of every home and result page
+	Slow      map[string]time.Duration // Extra delay of the result pages of a query
+}
+
+// Search is a result page request received by the server
+type Search struct {
+	Query string // Query (q parameter)
+	Page  int    // Result page (1-based)
+	HL    string // Interface language (hl parameter)
+	GL    string // Country (gl parameter)
+}
+
+// Server is a local search engine backed by an httptest.Server
+type Server struct {
+	*httptest.Server
+
+	config   Config
+	blocked  map[string]bool
+	mu       sync.Mutex
+	searches []Search
+}
+
+// NewServer starts a search engine server. Close it when done.
+//
+// Example:
+//
+//	server := serptest.NewServer(serptest.Config{
+//	    Results: map[string][]serptest.Result{
+//	        "golang": {{Title: "The Go Programming Language", URL: "https://go.dev/"}},
+//	    },
+//	    Blocked: []string{"blocked query"},
+//	})
+//	defer server.Close()
+func NewServer(config Config) *Server {
+	// Set defaults
+	if config.Generated <= 0 {
+		config.Generated = 30
+	}
+
+	blocked := make(map[string]bool, len(config.Blocked))
+	for _, query := range config.Blocked {
+		blocked[query] = true
+	}
+
+	s := &Server{
+		config:  config,
+		blocked: blocked,
+	}
+	s.Server = httptest.NewServer(s.handler())
+	return s
+}
+
+// handler returns the routes of the server
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleHome)
+	mux.HandleFunc("/search", s.handleSearch)
+	mux.HandleFunc("/sorry/index", s.handleSorry)
+	mux.HandleFunc("/recaptcha/", s.handleRecaptcha)
+	return mux
+}
+
+// Searches returns the result pages requested so far, in order
+func (s *Server) Searches() []Search {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Search(nil), s.searches...)
+}
+
+// ResultsFor returns every result the server has for a query, across all pages
+func (s *Server) ResultsFor(query string) []Result {
+	if results, ok := s.config.Results[query]; ok {
+		return results
+	}
+	return GenerateResults(query, s.config.Generated)
+}
+
+// GenerateResults creates n deterministic results for a query, the ones the
+// server serves for queries without configured results
+//
+// Example:
+//
+//	results := append(serptest.GenerateResults("golang", 12), serptest.Result{URL: "https://go.dev/"})
+func GenerateResults(query string, n int) []Result {
+	slug := url.PathEscape(strings.ReplaceAll(strings.ToLower(query), " ", "-"))
+	results := make([]Result, n)
+	for i := range results {
+		position := i + 1
+		results[i] = Result{
+			Title:   fmt.Sprintf("%s - Result %d", query, position),
+			URL:     fmt.Sprintf("https://site%d.example/%s", position, slug),
+			Snippet: fmt.Sprintf("Result %d for %s.", position, query),
+		}
+	}
+	return results
+}
+
+// handleHome serves the home page with the search box
+func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if !wait(r, s.config.Delay) {
+		return
+	}
+
+	render(w, http.StatusOK, homeTemplate, struct{ HL, GL string }{
+		HL: r.URL.Query().Get("hl"),
+		GL: r.URL.Query().Get("gl"),
+	})
+}
+
+// handleSearch serves a result page, or redirects blocked queries to the challenge page
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := params.Get("q")
+	start, _ := strconv.Atoi(params.Get("start"))
+	if start < 0 {
+		start = 0
+	}
+
+	s.mu.Lock()
+	s.searches = append(s.searches, Search{
+		Query: query,
+		Page:  start/PerPage + 1,
+		HL:    params.Get("hl"),
+		GL:    params.Get("gl"),
+	})
+	s.mu.Unlock()
+
+	if !wait(r, s.config.Delay+s.config.Slow[query]) {
+		return
+	}
+
+	if s.blocked[query] {
+		sorry := url.URL{Path: "/sorry/index", RawQuery: url.Values{"continue": {r.URL.String()}}.Encode()}
+		http.Redirect(w, r, sorry.String(), http.StatusFound)
+		return
+	}
+
+	all := s.ResultsFor(query)
+	end := min(start+PerPage, len(all))
+	page := resultsPage{Query: query, Start: start}
+	if start < end {
+		page.Results = all[start:end]
+	}
+	if end < len(all) {
+		next := *r.URL
+		params.Set("start", strconv.Itoa(end))
+		next.RawQuery = params.Encode()
+		page.Next = next.String()
+	}
+
+	render(w, http.StatusOK, resultsTemplate, page)
+}
+
+// handleSorry serves the challenge page Google shows for unusual traffic
+func (s *Server) handleSorry(w http.ResponseWriter, r *http.Request) {
+	render(w, http.StatusTooManyRequests, sorryTemplate, struct{ Continue string }{
+		Continue: r.URL.Query().Get("continue"),
+	})
+}
+
+// handleRecaptcha serves an empty reCAPTCHA frame
+func (s *Server) handleRecaptcha(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, "<!DOCTYPE html><html><body></body></html>")
+}
+
+// wait delays a response; it returns false if the client went away first
+func wait(r *http.Request, delay time.Duration) bool {
+	if delay <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// render writes a template as an HTML page
+func render(w http.ResponseWriter, status int, tmpl *template.Template, data interface{}) {
+	var page bytes.Buffer
+	if err := tmpl.Execute(&page, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(page.Bytes())
+}
@@ -0,0 +1,200 @@
+package serptest
+
+import (
+	"context"
+	"errors"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/omer/go-bot/internal/serp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ===== Helper functions =====
+
+// get requests a page of the server and returns the response and its body
+func get(t *testing.T, client *http.Client, rawURL string) (*http.Response, string) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, rawURL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+// parse runs the results parser of the default profile on a page
+func parse(t *testing.T, body string) []serp.SearchResult {
+	t.Helper()
+
+	results, err := serp.ParseResults(strings.NewReader(body), serp.DefaultSelectors())
+	require.NoError(t, err)
+	return results
+}
+
+// nextLink returns the link of the next page button, resolved against base
+func nextLink(t *testing.T, base, body string) string {
+	t.Helper()
+
+	start := strings.Index(body, `id="pnnext" href="`)
+	if start < 0 {
+		return ""
+	}
+	href := body[start+len(`id="pnnext" href="`):]
+	href = html.UnescapeString(href[:strings.Index(href, `"`)])
+
+	baseURL, err := url.Parse(base)
+	require.NoError(t, err)
+	next, err := baseURL.Parse(href)
+	require.NoError(t, err)
+	return next.String()
+}
+
+// ===== Home page tests =====
+
+func TestServer_Home(t *testing.T) {
+	server := NewServer(Config{})
+	defer server.Close()
+
+	resp, body := get(t, server.Client(), server.URL+"/?hl=tr&gl=TR")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, body, `<textarea name="q"`)
+	assert.Contains(t, body, `name="btnK"`)
+	assert.Contains(t, body, `<input type="hidden" name="hl" value="tr">`)
+	assert.Contains(t, body, `<input type="hidden" name="gl" value="TR">`)
+
+	resp, _ = get(t, server.Client(), server.URL+"/missing")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+// ===== Result page tests =====
+
+func TestServer_GeneratedResults(t *testing.T) {
+	server := NewServer(Config{Generated: 25})
+	defer server.Close()
+
+	var positions []int
+	var urls []string
+	pageURL := server.URL + "/search?q=golang+tutorial&hl=en"
+	for pageURL != "" {
+		resp, body := get(t, server.Client(), pageURL)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		for _, result := range parse(t, body) {
+			positions = append(positions, result.Position)
+			urls = append(urls, result.URL)
+		}
+		pageURL = nextLink(t, server.URL, body)
+	}
+
+	// Three pages of 10, 10 and 5 results
+	assert.Len(t, urls, 25)
+	assert.Equal(t, []int{1, 10, 1, 10, 1, 5}, []int{positions[0], positions[9], positions[10], positions[19], positions[20], positions[24]})
+	assert.Equal(t, "https://site1.example/golang-tutorial", urls[0])
+	assert.Equal(t, "https://site25.example/golang-tutorial", urls[24])
+
+	assert.Equal(t, []Search{
+		{Query: "golang tutorial", Page: 1, HL: "en"},
+		{Query: "golang tutorial", Page: 2, HL: "en"},
+		{Query: "golang tutorial", Page: 3, HL: "en"},
+	}, server.Searches())
+}
+
+func TestServer_ConfiguredResults(t *testing.T) {
+	server := NewServer(Config{
+		Results: map[string][]Result{
+			"golang": {
+				{Title: "The Go Programming Language", URL: "https://go.dev/", Snippet: "Go is an open source programming language."},
+				{Title: "Go by Example", URL: "https://gobyexample.com/"},
+			},
+			"nothing": {},
+		},
+	})
+	defer server.Close()
+
+	_, body := get(t, server.Client(), server.URL+"/search?q=golang")
+	results := parse(t, body)
+	require.Len(t, results, 2)
+	assert.Equal(t, "The Go Programming Language", results[0].Title)
+	assert.Equal(t, "https://go.dev/", results[0].URL)
+	assert.Equal(t, "Go is an open source programming language.", results[0].Description)
+	assert.Equal(t, "https://gobyexample.com/", results[1].URL)
+	assert.Empty(t, nextLink(t, server.URL, body), "single page has no next button")
+
+	// Pages past the last result are empty
+	_, body = get(t, server.Client(), server.URL+"/search?q=golang&start=10")
+	assert.Empty(t, parse(t, body))
+
+	resp, body := get(t, server.Client(), server.URL+"/search?q=nothing")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, parse(t, body))
+	assert.Contains(t, body, "did not match any documents")
+}
+
+// ===== Challenge page tests =====
+
+func TestServer_Blocked(t *testing.T) {
+	server := NewServer(Config{Blocked: []string{"blocked"}})
+	defer server.Close()
+
+	resp, body := get(t, server.Client(), server.URL+"/search?q=blocked")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "/sorry/index", resp.Request.URL.Path)
+	assert.Equal(t, "/search?q=blocked", resp.Request.URL.Query().Get("continue"))
+	assert.Contains(t, body, `<iframe title="reCAPTCHA" src="/recaptcha/api2/anchor?k=serptest">`)
+	assert.Empty(t, parse(t, body))
+
+	resp, _ = get(t, server.Client(), server.URL+"/recaptcha/api2/anchor?k=serptest")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Other queries are not blocked
+	resp, _ = get(t, server.Client(), server.URL+"/search?q=golang")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+// ===== Slow response tests =====
+
+func TestServer_Slow(t *testing.T) {
+	server := NewServer(Config{
+		Slow: map[string]time.Duration{"slow": time.Second},
+	})
+	defer server.Close()
+
+	client := server.Client()
+	client.Timeout = 200 * time.Millisecond
+
+	start := time.Now()
+	resp, _ := get(t, client, server.URL+"/search?q=fast")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Less(t, time.Since(start), 200*time.Millisecond)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+"/search?q=slow", nil)
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	if err == nil {
+		resp.Body.Close()
+	}
+	var netErr interface{ Timeout() bool }
+	require.True(t, errors.As(err, &netErr), "got %v", err)
+	assert.True(t, netErr.Timeout())
+}
+
+func TestServer_Delay(t *testing.T) {
+	server := NewServer(Config{Delay: 100 * time.Millisecond})
+	defer server.Close()
+
+	start := time.Now()
+	resp, _ := get(t, server.Client(), server.URL+"/")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
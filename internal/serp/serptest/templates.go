@@ -0,0 +1,84 @@
+package serptest
+
+import "html/template"
+
+// resultsPage is the data of a result page
+type resultsPage struct {
+	Query   string   // Query of the page
+	Start   int      // Offset of the first result
+	Results []Result // Organic results of the page
+	Next    string   // Link to the next page (empty on the last page)
+}
+
+// Position returns the 1-based position of the i-th result of the page
+func (p resultsPage) Position(i int) int {
+	return p.Start + i + 1
+}
+
+// homeTemplate is the home page. Like Google, the search box is a textarea
+// and pressing Enter in it submits the search.
+var homeTemplate = template.Must(template.New("home").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Google</title></head>
+<body>
+<form id="tsf" action="/search" method="GET">
+  <textarea name="q" rows="1" autofocus></textarea>
+  {{if .HL}}<input type="hidden" name="hl" value="{{.HL}}">{{end}}
+  {{if .GL}}<input type="hidden" name="gl" value="{{.GL}}">{{end}}
+  <input type="submit" name="btnK" value="Google Search">
+</form>
+<script>
+document.querySelector("textarea[name=q]").addEventListener("keydown", function (e) {
+  if (e.key === "Enter") {
+    e.preventDefault();
+    this.value = this.value.trim();
+    this.form.submit();
+  }
+});
+</script>
+</body>
+</html>
+`))
+
+// resultsTemplate is a result page in the classic Google layout
+var resultsTemplate = template.Must(template.New("results").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>{{.Query}} - Google Search</title></head>
+<body>
+<form action="/search" method="GET"><textarea name="q" rows="1">{{.Query}}</textarea></form>
+<div id="search">
+  <div id="rso">
+{{- range $i, $r := .Results}}
+    <div class="g" data-position="{{$.Position $i}}">
+      <div class="yuRUbf">
+        <a href="{{$r.URL}}"><h3>{{$r.Title}}</h3></a>
+      </div>
+      <div class="VwiC3b">{{$r.Snippet}}</div>
+    </div>
+{{- else}}
+    <div class="card-section"><p>Your search - <em>{{.Query}}</em> - did not match any documents.</p></div>
+{{- end}}
+  </div>
+{{- if .Next}}
+  <table class="AaVjTc">
+    <tr><td><a id="pnnext" href="{{.Next}}">Next</a></td></tr>
+  </table>
+{{- end}}
+</div>
+</body>
+</html>
+`))
+
+// sorryTemplate is the challenge page served to blocked queries
+var sorryTemplate = template.Must(template.New("sorry").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Continue}}</title></head>
+<body>
+<div id="infoDiv">Our systems have detected unusual traffic from your computer network.</div>
+<form id="captcha-form" action="index" method="post">
+  <iframe title="reCAPTCHA" src="/recaptcha/api2/anchor?k=serptest"></iframe>
+  <input type="hidden" name="continue" value="{{.Continue}}">
+</form>
+</body>
+</html>
+`))
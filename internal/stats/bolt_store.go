@@ -0,0 +1,384 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Bucket layout of a BoltStore:
+//
+//	series/<keyword \x00 target \x00 locale>/raw/<unix nanos><sequence> -> RankSample
+//	series/<keyword \x00 target \x00 locale>/daily/<unix nanos of day>  -> RankBucket
+//
+// Keys are big-endian so cursors walk them in time order.
+var (
+	seriesBucket = []byte("series")
+	rawBucket    = []byte("raw")
+	dailyBucket  = []byte("daily")
+)
+
+// BoltStore is a Store backed by an embedded bbolt database file
+type BoltStore struct {
+	db  *bolt.DB
+	now func() time.Time
+}
+
+// OpenBoltStore opens (or creates) the rank history database at path.
+// The file is locked while open; a second process waits up to one second
+// for the lock before giving up.
+//
+// Example:
+//
+//	store, err := stats.OpenBoltStore("data/history.db")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer store.Close()
+func OpenBoltStore(path string) (*BoltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(seriesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize history database: %w", err)
+	}
+
+	return &BoltStore{db: db, now: time.Now}, nil
+}
+
+// Append records the ranks measured by a task in a single transaction
+func (s *BoltStore) Append(taskStats TaskStats) error {
+	samples := rankSamples(taskStats)
+	if len(samples) == 0 {
+		return nil
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for key, sample := range samples {
+			series, err := tx.Bucket(seriesBucket).CreateBucketIfNotExists(encodeSeriesKey(key))
+			if err != nil {
+				return fmt.Errorf("failed to create series %s: %w", key, err)
+			}
+			raw, err := series.CreateBucketIfNotExists(rawBucket)
+			if err != nil {
+				return fmt.Errorf("failed to create series %s: %w", key, err)
+			}
+
+			// The sequence keeps samples with the same timestamp apart
+			seq, err := raw.NextSequence()
+			if err != nil {
+				return err
+			}
+			data, err := json.Marshal(sample)
+			if err != nil {
+				return fmt.Errorf("failed to marshal sample: %w", err)
+			}
+			if err := raw.Put(sampleKey(sample.Time, seq), data); err != nil {
+				return fmt.Errorf("failed to write sample: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Query returns the rank history of one series.
+// Rolled-up days are returned as a whole: with hourly granularity a
+// compacted day shows up as a single bucket at midnight.
+//
+// Example:
+//
+//	buckets, err := store.Query(stats.RankQuery{
+//	    Keyword:     "golang tutorial",
+//	    TargetURL:   "example.com",
+//	    From:        time.Now().AddDate(0, 0, -30),
+//	    Granularity: stats.GranularityDay,
+//	})
+func (s *BoltStore) Query(query RankQuery) ([]RankBucket, error) {
+	query, err := query.normalize(s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := newBucketer(query.Granularity)
+	err = s.db.View(func(tx *bolt.Tx) error {
+		series := tx.Bucket(seriesBucket).Bucket(encodeSeriesKey(query.Series()))
+		if series == nil {
+			return nil
+		}
+
+		// Rollups cover older days than the raw samples, so reading them
+		// first keeps the buckets in time order
+		if daily := series.Bucket(dailyBucket); daily != nil {
+			err := scanRange(daily, startOfDay(query.From), query.To, func(_ time.Time, value []byte) error {
+				var rollup RankBucket
+				if err := json.Unmarshal(value, &rollup); err != nil {
+					return fmt.Errorf("failed to decode daily bucket: %w", err)
+				}
+				buckets.bucket(rollup.Start).merge(rollup)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if raw := series.Bucket(rawBucket); raw != nil {
+			return scanRange(raw, query.From, query.To, func(t time.Time, value []byte) error {
+				var sample RankSample
+				if err := json.Unmarshal(value, &sample); err != nil {
+					return fmt.Errorf("failed to decode sample: %w", err)
+				}
+				buckets.bucket(t).add(sample)
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buckets.sorted(), nil
+}
+
+// Series lists every series with stored history
+func (s *BoltStore) Series() ([]SeriesKey, error) {
+	var keys []SeriesKey
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(seriesBucket).ForEachBucket(func(name []byte) error {
+			keys = append(keys, decodeSeriesKey(name))
+			return nil
+		})
+	})
+	return keys, err
+}
+
+// Compact applies a retention policy. Samples older than policy.RawFor are
+// merged into daily buckets (whole days only); samples and daily buckets
+// older than policy.KeepFor are deleted.
+//
+// Example:
+//
+//	result, err := store.Compact(stats.RetentionPolicy{
+//	    RawFor:  30 * 24 * time.Hour,
+//	    KeepFor: 365 * 24 * time.Hour,
+//	}, time.Now())
+func (s *BoltStore) Compact(policy RetentionPolicy, now time.Time) (CompactResult, error) {
+	var result CompactResult
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		root := tx.Bucket(seriesBucket)
+
+		var empty [][]byte
+		err := root.ForEachBucket(func(name []byte) error {
+			series := root.Bucket(name)
+			compacted, err := compactSeries(series, policy, now)
+			if err != nil {
+				return err
+			}
+			result.RolledUp += compacted.RolledUp
+			result.Deleted += compacted.Deleted
+			if isEmptySeries(series) {
+				empty = append(empty, append([]byte(nil), name...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, name := range empty {
+			if err := root.DeleteBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return CompactResult{}, fmt.Errorf("compaction failed: %w", err)
+	}
+
+	return result, nil
+}
+
+// Close closes the database file
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// compactSeries applies a retention policy to one series
+func compactSeries(series *bolt.Bucket, policy RetentionPolicy, now time.Time) (CompactResult, error) {
+	var result CompactResult
+
+	if policy.RawFor > 0 {
+		n, err := rollUp(series, startOfDay(now.Add(-policy.RawFor)))
+		if err != nil {
+			return result, err
+		}
+		result.RolledUp = n
+	}
+
+	if policy.KeepFor > 0 {
+		// A daily bucket is deleted once its whole day is past the cutoff
+		cutoff := now.Add(-policy.KeepFor)
+		n, err := deleteBefore(series.Bucket(rawBucket), cutoff)
+		if err != nil {
+			return result, err
+		}
+		m, err := deleteBefore(series.Bucket(dailyBucket), startOfDay(cutoff))
+		if err != nil {
+			return result, err
+		}
+		result.Deleted = n + m
+	}
+
+	return result, nil
+}
+
+// rollUp merges the raw samples of a series that are older than cutoff into
+// its daily buckets and deletes them. It returns the number of samples merged.
+func rollUp(series *bolt.Bucket, cutoff time.Time) (int, error) {
+	raw := series.Bucket(rawBucket)
+	if raw == nil {
+		return 0, nil
+	}
+
+	days := newBucketer(GranularityDay)
+	err := scanRange(raw, time.Time{}, cutoff.Add(-time.Nanosecond), func(t time.Time, value []byte) error {
+		var sample RankSample
+		if err := json.Unmarshal(value, &sample); err != nil {
+			return fmt.Errorf("failed to decode sample: %w", err)
+		}
+		days.bucket(t).add(sample)
+		return nil
+	})
+	if err != nil || len(days.buckets) == 0 {
+		return 0, err
+	}
+
+	daily, err := series.CreateBucketIfNotExists(dailyBucket)
+	if err != nil {
+		return 0, err
+	}
+	for _, day := range days.sorted() {
+		key := timeKey(day.Start)
+		if existing := daily.Get(key); existing != nil {
+			var rollup RankBucket
+			if err := json.Unmarshal(existing, &rollup); err != nil {
+				return 0, fmt.Errorf("failed to decode daily bucket: %w", err)
+			}
+			rollup.merge(day)
+			day = rollup
+		}
+		data, err := json.Marshal(day)
+		if err != nil {
+			return 0, err
+		}
+		if err := daily.Put(key, data); err != nil {
+			return 0, err
+		}
+	}
+
+	return deleteBefore(raw, cutoff)
+}
+
+// deleteBefore deletes the entries of a time-keyed bucket before cutoff
+func deleteBefore(bucket *bolt.Bucket, cutoff time.Time) (int, error) {
+	if bucket == nil {
+		return 0, nil
+	}
+
+	// Keys are collected first; deleting while iterating skips entries
+	var keys [][]byte
+	c := bucket.Cursor()
+	end := timeKey(cutoff)
+	for k, _ := c.First(); k != nil && bytes.Compare(k[:8], end) < 0; k, _ = c.Next() {
+		keys = append(keys, append([]byte(nil), k...))
+	}
+
+	for _, k := range keys {
+		if err := bucket.Delete(k); err != nil {
+			return 0, err
+		}
+	}
+	return len(keys), nil
+}
+
+// scanRange calls fn for the entries of a time-keyed bucket between from and to (inclusive)
+func scanRange(bucket *bolt.Bucket, from, to time.Time, fn func(t time.Time, value []byte) error) error {
+	c := bucket.Cursor()
+	end := timeKey(to)
+	for k, v := c.Seek(timeKey(from)); k != nil && bytes.Compare(k[:8], end) <= 0; k, v = c.Next() {
+		if err := fn(keyTime(k), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isEmptySeries reports whether a series has neither samples nor daily buckets left
+func isEmptySeries(series *bolt.Bucket) bool {
+	for _, name := range [][]byte{rawBucket, dailyBucket} {
+		if b := series.Bucket(name); b != nil {
+			if k, _ := b.Cursor().First(); k != nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// timeKey encodes a time as 8 big-endian bytes of Unix nanoseconds.
+// Times before 1970 sort before all others.
+func timeKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	if !t.IsZero() && t.UnixNano() > 0 {
+		binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	}
+	return key
+}
+
+// sampleKey appends a sequence number to the time key of a sample
+func sampleKey(t time.Time, seq uint64) []byte {
+	key := make([]byte, 16)
+	copy(key, timeKey(t))
+	binary.BigEndian.PutUint64(key[8:], seq)
+	return key
+}
+
+// keyTime decodes the time of a timeKey or sampleKey
+func keyTime(key []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key[:8]))).UTC()
+}
+
+// encodeSeriesKey returns the bucket name of a series
+func encodeSeriesKey(key SeriesKey) []byte {
+	return []byte(strings.Join([]string{key.Keyword, key.TargetURL, key.Locale}, "\x00"))
+}
+
+// decodeSeriesKey parses a series bucket name
+func decodeSeriesKey(name []byte) SeriesKey {
+	parts := strings.SplitN(string(name), "\x00", 3)
+	for len(parts) < 3 {
+		parts = append(parts, "")
+	}
+	return SeriesKey{Keyword: parts[0], TargetURL: parts[1], Locale: parts[2]}
+}
@@ -0,0 +1,250 @@
+package stats
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ===== Helper functions =====
+
+func openTestStore(t *testing.T) *BoltStore {
+	t.Helper()
+	store, err := OpenBoltStore(filepath.Join(t.TempDir(), "history.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// rankAt builds a successful rank check of golang/example.com at the given time
+func rankAt(ts time.Time, position int) TaskStats {
+	taskStats := TaskStats{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+		Success:   true,
+		Position:  position,
+		Outcome:   OutcomeFound,
+		Depth:     50,
+		Timestamp: ts,
+	}
+	if position == 0 {
+		taskStats.Outcome = OutcomeNotInTop
+	}
+	return taskStats
+}
+
+var day0 = time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC) // A Monday
+
+// ===== BoltStore tests =====
+
+func TestBoltStore_QueryDaily(t *testing.T) {
+	store := openTestStore(t)
+
+	require.NoError(t, store.Append(rankAt(day0.Add(9*time.Hour), 5)))
+	require.NoError(t, store.Append(rankAt(day0.Add(15*time.Hour), 3)))
+	require.NoError(t, store.Append(rankAt(day0.AddDate(0, 0, 1).Add(9*time.Hour), 0)))
+	require.NoError(t, store.Append(rankAt(day0.AddDate(0, 0, 2).Add(9*time.Hour), 4)))
+
+	buckets, err := store.Query(RankQuery{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+		From:      day0,
+		To:        day0.AddDate(0, 0, 2),
+	})
+	require.NoError(t, err)
+	require.Len(t, buckets, 2, "the third day is after To")
+
+	assert.Equal(t, day0, buckets[0].Start)
+	assert.Equal(t, 2, buckets[0].Samples)
+	assert.Equal(t, 4.0, buckets[0].AvgPosition)
+	assert.Equal(t, 3, buckets[0].BestPosition)
+	assert.Equal(t, 5, buckets[0].WorstPosition)
+	assert.Equal(t, 3, buckets[0].LastPosition)
+
+	assert.Equal(t, day0.AddDate(0, 0, 1), buckets[1].Start)
+	assert.Equal(t, 1, buckets[1].NotInTop)
+	assert.Equal(t, 0, buckets[1].Ranked)
+}
+
+func TestBoltStore_QueryGranularity(t *testing.T) {
+	store := openTestStore(t)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, store.Append(rankAt(day0.AddDate(0, 0, i).Add(time.Hour), i+1)))
+	}
+
+	weekly, err := store.Query(RankQuery{Keyword: "golang", TargetURL: "example.com", From: day0, To: day0.AddDate(0, 0, 10), Granularity: GranularityWeek})
+	require.NoError(t, err)
+	require.Len(t, weekly, 2)
+	assert.Equal(t, day0, weekly[0].Start)
+	assert.Equal(t, 7, weekly[0].Samples)
+	assert.Equal(t, 4.0, weekly[0].AvgPosition)
+	assert.Equal(t, 3, weekly[1].Samples)
+
+	hourly, err := store.Query(RankQuery{Keyword: "golang", TargetURL: "example.com", From: day0, To: day0.AddDate(0, 0, 1), Granularity: GranularityHour})
+	require.NoError(t, err)
+	require.Len(t, hourly, 1)
+	assert.Equal(t, day0.Add(time.Hour), hourly[0].Start)
+}
+
+func TestBoltStore_SeriesAndCompetitors(t *testing.T) {
+	store := openTestStore(t)
+
+	taskStats := rankAt(day0, 4)
+	taskStats.Locale = "hl=tr,gl=TR"
+	taskStats.Competitors = []CompetitorStats{{TargetURL: "rival.com", Position: 2}}
+	require.NoError(t, store.Append(taskStats))
+
+	series, err := store.Series()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []SeriesKey{
+		{Keyword: "golang", TargetURL: "example.com", Locale: "hl=tr,gl=TR"},
+		{Keyword: "golang", TargetURL: "rival.com", Locale: "hl=tr,gl=TR"},
+	}, series)
+
+	buckets, err := store.Query(RankQuery{Keyword: "golang", TargetURL: "rival.com", Locale: "hl=tr,gl=TR", From: day0, To: day0.AddDate(0, 0, 1)})
+	require.NoError(t, err)
+	require.Len(t, buckets, 1)
+	assert.Equal(t, 2, buckets[0].BestPosition)
+
+	// Without the locale the series is a different one
+	buckets, err = store.Query(RankQuery{Keyword: "golang", TargetURL: "rival.com", From: day0, To: day0.AddDate(0, 0, 1)})
+	require.NoError(t, err)
+	assert.Empty(t, buckets)
+}
+
+func TestBoltStore_Compact(t *testing.T) {
+	store := openTestStore(t)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, store.Append(rankAt(day0.AddDate(0, 0, i).Add(8*time.Hour), 10)))
+		require.NoError(t, store.Append(rankAt(day0.AddDate(0, 0, i).Add(20*time.Hour), 20)))
+	}
+	query := RankQuery{Keyword: "golang", TargetURL: "example.com", From: day0, To: day0.AddDate(0, 0, 10)}
+	before, err := store.Query(query)
+	require.NoError(t, err)
+
+	now := day0.AddDate(0, 0, 10).Add(12 * time.Hour)
+	result, err := store.Compact(RetentionPolicy{RawFor: 3 * 24 * time.Hour}, now)
+	require.NoError(t, err)
+	assert.Equal(t, 14, result.RolledUp, "days 0-6 are rolled up")
+	assert.Equal(t, 0, result.Deleted)
+
+	// Daily answers do not change when samples are rolled up
+	after, err := store.Query(query)
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	// Compacting again is a no-op
+	result, err = store.Compact(RetentionPolicy{RawFor: 3 * 24 * time.Hour}, now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.RolledUp)
+
+	result, err = store.Compact(RetentionPolicy{RawFor: 3 * 24 * time.Hour, KeepFor: 5 * 24 * time.Hour}, now)
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.Deleted, "daily buckets of days 0-4")
+
+	buckets, err := store.Query(query)
+	require.NoError(t, err)
+	require.Len(t, buckets, 5)
+	assert.Equal(t, day0.AddDate(0, 0, 5), buckets[0].Start)
+	assert.Equal(t, 15.0, buckets[0].AvgPosition)
+}
+
+func TestBoltStore_CompactRemovesEmptySeries(t *testing.T) {
+	store := openTestStore(t)
+	require.NoError(t, store.Append(rankAt(day0, 1)))
+
+	result, err := store.Compact(RetentionPolicy{KeepFor: 24 * time.Hour}, day0.AddDate(0, 0, 30))
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Deleted)
+
+	series, err := store.Series()
+	require.NoError(t, err)
+	assert.Empty(t, series)
+}
+
+func TestBoltStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	store, err := OpenBoltStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Append(rankAt(day0, 7)))
+	require.NoError(t, store.Close())
+
+	store, err = OpenBoltStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	buckets, err := store.Query(RankQuery{Keyword: "golang", TargetURL: "example.com", From: day0})
+	require.NoError(t, err)
+	require.Len(t, buckets, 1)
+	assert.Equal(t, 7, buckets[0].LastPosition)
+}
+
+func TestBoltStore_InvalidQuery(t *testing.T) {
+	store := openTestStore(t)
+
+	_, err := store.Query(RankQuery{TargetURL: "example.com"})
+	assert.Error(t, err)
+
+	_, err = store.Query(RankQuery{Keyword: "golang", TargetURL: "example.com", Granularity: "minute"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown granularity")
+
+	_, err = store.Query(RankQuery{Keyword: "golang", TargetURL: "example.com", From: day0, To: day0.Add(-time.Hour)})
+	assert.Error(t, err)
+}
+
+// ===== StatsCollector with store tests =====
+
+// failingStore is a Store whose appends always fail
+type failingStore struct {
+	Store
+	appends int
+}
+
+func (s *failingStore) Append(TaskStats) error {
+	s.appends++
+	return errors.New("disk full")
+}
+
+func TestStatsCollector_WithStore(t *testing.T) {
+	store := openTestStore(t)
+	collector := NewStatsCollectorWithStore(filepath.Join(t.TempDir(), "stats.json"), store)
+	assert.Equal(t, store, collector.Store())
+
+	for i := 0; i < maxRecentTasks+5; i++ {
+		collector.RecordTask(rankAt(day0.Add(time.Duration(i)*time.Minute), 3))
+	}
+
+	stats := collector.GetStats()
+	assert.Len(t, stats.TaskHistory, maxRecentTasks)
+	assert.Equal(t, maxRecentTasks+5, stats.TotalTasks)
+
+	buckets, err := store.Query(RankQuery{Keyword: "golang", TargetURL: "example.com", From: day0})
+	require.NoError(t, err)
+	require.Len(t, buckets, 1)
+	assert.Equal(t, maxRecentTasks+5, buckets[0].Samples)
+}
+
+func TestStatsCollector_StoreError(t *testing.T) {
+	store := &failingStore{}
+	collector := NewStatsCollectorWithStore(filepath.Join(t.TempDir(), "stats.json"), store)
+
+	collector.RecordTask(rankAt(day0, 3))
+	collector.RecordTask(rankAt(day0, 4))
+	assert.Equal(t, 2, store.appends)
+	assert.Equal(t, 2, collector.GetStats().TotalTasks)
+
+	err := collector.Save()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "disk full")
+
+	// The error is reported once
+	assert.NoError(t, collector.Save())
+}
@@ -13,30 +13,60 @@ import (
 
 // TaskStats represents statistics for a single task execution
 type TaskStats struct {
-	TaskID     string    `json:"task_id"`
-	Keyword    string    `json:"keyword"`
-	TargetURL  string    `json:"target_url"`
-	Success    bool      `json:"success"`
-	Position   int       `json:"position"`    // Position where target was found (0 if not found)
-	PageNumber int       `json:"page_number"` // Page number where target was found
-	Duration   float64   `json:"duration_ms"` // Duration in milliseconds
-	ProxyUsed  string    `json:"proxy_used"`  // Proxy URL used
-	Error      string    `json:"error"`       // Error message if failed
-	Timestamp  time.Time `json:"timestamp"`   // When the task was executed
+	TaskID         string            `json:"task_id"`
+	Keyword        string            `json:"keyword"`
+	TargetURL      string            `json:"target_url"`
+	Locale         string            `json:"locale,omitempty"` // Search context, e.g. "hl=tr,gl=TR,device=mobile"
+	Success        bool              `json:"success"`
+	Position       int               `json:"position"`                  // Absolute organic position where target was found (0 if not found)
+	VisualPosition int               `json:"visual_position,omitempty"` // Absolute position counting ads and SERP features
+	PageNumber     int               `json:"page_number"`               // Page number where target was found
+	Outcome        string            `json:"outcome,omitempty"`         // Rank scan outcome: found or not_in_top
+	Depth          int               `json:"depth,omitempty"`           // Number of organic results scanned
+	Features       []string          `json:"features,omitempty"`        // SERP features that showed the target
+	Competitors    []CompetitorStats `json:"competitors,omitempty"`     // Competitor ranks from the same results
+	Duration       float64           `json:"duration_ms"`               // Duration in milliseconds
+	ProxyUsed      string            `json:"proxy_used"`                // Proxy URL used
+	Attempts       int               `json:"attempts,omitempty"`        // Number of executions, including retries
+	Selectors      string            `json:"selectors,omitempty"`       // Selector profile used, e.g. "google@2"
+	Fallbacks      []string          `json:"fallbacks,omitempty"`       // Selector fallbacks used, as "field: primary -> fallback"
+	Error          string            `json:"error"`                     // Error message if failed
+	Timestamp      time.Time         `json:"timestamp"`                 // When the task was executed
 }
 
+// CompetitorStats holds the rank of a competitor domain in a task execution
+type CompetitorStats struct {
+	TargetURL      string `json:"target_url"`
+	URL            string `json:"url,omitempty"`             // Matching result URL
+	Position       int    `json:"position"`                  // Absolute organic position (0 if not found)
+	VisualPosition int    `json:"visual_position,omitempty"` // Absolute position counting ads and SERP features
+	PageNumber     int    `json:"page_number"`               // Page number where the competitor was found
+}
+
+// Rank scan outcomes recorded in TaskStats.Outcome
+const (
+	OutcomeFound    = "found"
+	OutcomeNotInTop = "not_in_top"
+)
+
 // KeywordStats represents aggregated statistics for a keyword
 type KeywordStats struct {
 	Keyword       string    `json:"keyword"`
 	TargetURL     string    `json:"target_url"`
+	Locale        string    `json:"locale,omitempty"` // Search context the ranks were measured in
 	TotalAttempts int       `json:"total_attempts"`
 	SuccessCount  int       `json:"success_count"`
 	FailureCount  int       `json:"failure_count"`
+	RankedCount   int       `json:"ranked_count"`     // Attempts where a position was found
+	NotInTopCount int       `json:"not_in_top_count"` // Attempts where the target was not in the scanned results
 	AvgPosition   float64   `json:"avg_position"`
 	AvgDuration   float64   `json:"avg_duration_ms"`
 	LastSeen      time.Time `json:"last_seen"`
-	BestPosition  int       `json:"best_position"`  // Best (lowest) position seen
-	WorstPosition int       `json:"worst_position"` // Worst (highest) position seen
+	BestPosition  int       `json:"best_position"`          // Best (lowest) position seen
+	WorstPosition int       `json:"worst_position"`         // Worst (highest) position seen
+	LastPosition  int       `json:"last_position"`          // Position of the latest rank check (0 if not in top)
+	LastOutcome   string    `json:"last_outcome,omitempty"` // Outcome of the latest rank check (empty until one succeeds)
+	Competitor    bool      `json:"competitor,omitempty"`   // TargetURL is a competitor, not our own domain
 }
 
 // Statistics represents the complete statistics collection
@@ -47,13 +77,18 @@ type Statistics struct {
 	SuccessTasks int                     `json:"success_tasks"`
 	FailedTasks  int                     `json:"failed_tasks"`
 	TaskHistory  []TaskStats             `json:"task_history"`
-	KeywordStats map[string]KeywordStats `json:"keyword_stats"` // key: keyword-targeturl
+	KeywordStats map[string]KeywordStats `json:"keyword_stats"` // key: keyword-targeturl[@locale]
 }
 
+// maxRecentTasks caps the in-memory task history when a Store keeps the full history
+const maxRecentTasks = 1000
+
 // StatsCollector manages statistics collection
 type StatsCollector struct {
 	stats    *Statistics
 	filePath string
+	store    Store // Rank history store (optional)
+	storeErr error // First store error since the last Save
 	mu       sync.RWMutex
 }
 
@@ -74,6 +109,28 @@ func NewStatsCollector(filePath string) *StatsCollector {
 	}
 }
 
+// NewStatsCollectorWithStore creates a statistics collector that also appends
+// every recorded task to a rank history store. The full history lives in the
+// store, so only the most recent tasks are kept in memory and in the JSON file.
+//
+// Example:
+//
+//	store, err := stats.OpenBoltStore("data/history.db")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	collector := stats.NewStatsCollectorWithStore("data/stats.json", store)
+func NewStatsCollectorWithStore(filePath string, store Store) *StatsCollector {
+	collector := NewStatsCollector(filePath)
+	collector.store = store
+	return collector
+}
+
+// Store returns the rank history store (nil if none is attached)
+func (sc *StatsCollector) Store() Store {
+	return sc.store
+}
+
 // RecordTask records a task execution result
 //
 // Example:
@@ -95,6 +152,14 @@ func (sc *StatsCollector) RecordTask(taskStats TaskStats) {
 
 	// Add to task history
 	sc.stats.TaskHistory = append(sc.stats.TaskHistory, taskStats)
+	if sc.store != nil {
+		if err := sc.store.Append(taskStats); err != nil && sc.storeErr == nil {
+			sc.storeErr = err
+		}
+		if n := len(sc.stats.TaskHistory); n > maxRecentTasks {
+			sc.stats.TaskHistory = append([]TaskStats(nil), sc.stats.TaskHistory[n-maxRecentTasks:]...)
+		}
+	}
 
 	// Update overall stats
 	sc.stats.TotalTasks++
@@ -107,21 +172,75 @@ func (sc *StatsCollector) RecordTask(taskStats TaskStats) {
 
 	// Update keyword stats
 	if taskStats.Keyword != "" && taskStats.TargetURL != "" {
-		sc.updateKeywordStats(taskStats)
+		sc.updateKeywordStats(taskStats, false)
+
+		for _, competitor := range taskStats.CompetitorTasks() {
+			sc.updateKeywordStats(competitor, true)
+		}
+	}
+}
+
+// Rank returns the position measured by a task: 0 when the target was not in
+// the scanned results. ok is false when the task did not produce a rank.
+func (ts TaskStats) Rank() (position int, ok bool) {
+	switch {
+	case ts.Success && ts.Position > 0:
+		return ts.Position, true
+	case ts.Outcome == OutcomeNotInTop:
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// CompetitorTasks returns the task stats of each competitor ranked by a task,
+// in the same form as the task's own stats
+func (ts TaskStats) CompetitorTasks() []TaskStats {
+	competitors := make([]TaskStats, len(ts.Competitors))
+	for i, competitor := range ts.Competitors {
+		competitors[i] = competitorTaskStats(ts, competitor)
+	}
+	return competitors
+}
+
+// competitorTaskStats derives the execution stats of a competitor from the
+// task that ranked it, so competitors are aggregated like our own targets
+func competitorTaskStats(taskStats TaskStats, competitor CompetitorStats) TaskStats {
+	competitorStats := TaskStats{
+		TaskID:         taskStats.TaskID,
+		Keyword:        taskStats.Keyword,
+		TargetURL:      competitor.TargetURL,
+		Locale:         taskStats.Locale,
+		Success:        taskStats.Success,
+		Position:       competitor.Position,
+		VisualPosition: competitor.VisualPosition,
+		PageNumber:     competitor.PageNumber,
+		Depth:          taskStats.Depth,
+		Duration:       taskStats.Duration,
+		Timestamp:      taskStats.Timestamp,
+	}
+	if taskStats.Success {
+		competitorStats.Outcome = OutcomeNotInTop
+		if competitor.Position > 0 {
+			competitorStats.Outcome = OutcomeFound
+		}
 	}
+	return competitorStats
 }
 
 // updateKeywordStats updates aggregated keyword statistics
-func (sc *StatsCollector) updateKeywordStats(taskStats TaskStats) {
-	key := fmt.Sprintf("%s-%s", taskStats.Keyword, taskStats.TargetURL)
+func (sc *StatsCollector) updateKeywordStats(taskStats TaskStats, competitor bool) {
+	key := keywordKey(taskStats.Keyword, taskStats.TargetURL, taskStats.Locale)
 
 	kwStats, exists := sc.stats.KeywordStats[key]
 	if !exists {
 		kwStats = KeywordStats{
 			Keyword:       taskStats.Keyword,
 			TargetURL:     taskStats.TargetURL,
+			Locale:        taskStats.Locale,
 			BestPosition:  999999,
 			WorstPosition: 0,
+			Competitor:    competitor,
 		}
 	}
 
@@ -133,8 +252,13 @@ func (sc *StatsCollector) updateKeywordStats(taskStats TaskStats) {
 		kwStats.FailureCount++
 	}
 
+	if taskStats.Outcome == OutcomeNotInTop {
+		kwStats.NotInTopCount++
+	}
+
 	// Update positions (only for successful tasks)
 	if taskStats.Success && taskStats.Position > 0 {
+		kwStats.RankedCount++
 		if taskStats.Position < kwStats.BestPosition {
 			kwStats.BestPosition = taskStats.Position
 		}
@@ -143,8 +267,17 @@ func (sc *StatsCollector) updateKeywordStats(taskStats TaskStats) {
 		}
 
 		// Calculate average position
-		totalPositions := float64(kwStats.AvgPosition) * float64(kwStats.SuccessCount-1)
-		kwStats.AvgPosition = (totalPositions + float64(taskStats.Position)) / float64(kwStats.SuccessCount)
+		totalPositions := kwStats.AvgPosition * float64(kwStats.RankedCount-1)
+		kwStats.AvgPosition = (totalPositions + float64(taskStats.Position)) / float64(kwStats.RankedCount)
+	}
+
+	// Remember the latest known rank; failed checks leave it unchanged
+	if rank, ok := taskStats.Rank(); ok {
+		kwStats.LastPosition = rank
+		kwStats.LastOutcome = OutcomeNotInTop
+		if rank > 0 {
+			kwStats.LastOutcome = OutcomeFound
+		}
 	}
 
 	// Calculate average duration
@@ -201,10 +334,11 @@ func (sc *StatsCollector) GetSummary() map[string]interface{} {
 	}
 }
 
-// Save saves statistics to a JSON file
+// Save saves statistics to a JSON file.
+// It also reports the first error the history store returned since the last Save.
 func (sc *StatsCollector) Save() error {
-	sc.mu.RLock()
-	defer sc.mu.RUnlock()
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
 
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(sc.filePath)
@@ -223,6 +357,11 @@ func (sc *StatsCollector) Save() error {
 		return fmt.Errorf("failed to write stats file: %w", err)
 	}
 
+	if err := sc.storeErr; err != nil {
+		sc.storeErr = nil
+		return fmt.Errorf("failed to append to history store: %w", err)
+	}
+
 	return nil
 }
 
@@ -274,15 +413,35 @@ func (sc *StatsCollector) Reset() {
 }
 
 // GetKeywordStats returns statistics for a specific keyword-target combination
+// searched without a locale
 func (sc *StatsCollector) GetKeywordStats(keyword, targetURL string) (KeywordStats, bool) {
+	return sc.GetKeywordStatsForLocale(keyword, targetURL, "")
+}
+
+// GetKeywordStatsForLocale returns statistics for a keyword-target combination
+// searched in the given locale (see TaskStats.Locale)
+//
+// Example:
+//
+//	kwStats, ok := collector.GetKeywordStatsForLocale("golang", "example.com", "hl=tr,gl=TR")
+func (sc *StatsCollector) GetKeywordStatsForLocale(keyword, targetURL, locale string) (KeywordStats, bool) {
 	sc.mu.RLock()
 	defer sc.mu.RUnlock()
 
-	key := fmt.Sprintf("%s-%s", keyword, targetURL)
-	stats, exists := sc.stats.KeywordStats[key]
+	stats, exists := sc.stats.KeywordStats[keywordKey(keyword, targetURL, locale)]
 	return stats, exists
 }
 
+// keywordKey returns the KeywordStats key of a keyword-target combination.
+// Ranks measured in different locales are kept apart.
+func keywordKey(keyword, targetURL, locale string) string {
+	key := fmt.Sprintf("%s-%s", keyword, targetURL)
+	if locale != "" {
+		key += "@" + locale
+	}
+	return key
+}
+
 // GetRecentTasks returns the N most recent tasks
 func (sc *StatsCollector) GetRecentTasks(n int) []TaskStats {
 	sc.mu.RLock()
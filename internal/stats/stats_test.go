@@ -164,6 +164,125 @@ func TestKeywordStats_MultipleKeywords(t *testing.T) {
 	}
 }
 
+func TestKeywordStats_NotInTop(t *testing.T) {
+	collector := NewStatsCollector("test/stats.json")
+
+	collector.RecordTask(TaskStats{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+		Success:   true,
+		Position:  14,
+		Outcome:   OutcomeFound,
+	})
+	collector.RecordTask(TaskStats{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+		Success:   true,
+		Outcome:   OutcomeNotInTop,
+		Depth:     50,
+	})
+	collector.RecordTask(TaskStats{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+		Success:   true,
+		Position:  6,
+		Outcome:   OutcomeFound,
+	})
+
+	kwStats, exists := collector.GetKeywordStats("golang", "example.com")
+	require.True(t, exists)
+	assert.Equal(t, 3, kwStats.SuccessCount)
+	assert.Equal(t, 2, kwStats.RankedCount)
+	assert.Equal(t, 1, kwStats.NotInTopCount)
+	assert.InDelta(t, 10.0, kwStats.AvgPosition, 0.1) // not-in-top checks do not count towards the average
+	assert.Equal(t, 6, kwStats.BestPosition)
+	assert.Equal(t, 14, kwStats.WorstPosition)
+	assert.Equal(t, 6, kwStats.LastPosition)
+	assert.Equal(t, OutcomeFound, kwStats.LastOutcome)
+
+	// A failed check keeps the last known rank
+	collector.RecordTask(TaskStats{Keyword: "golang", TargetURL: "example.com", Error: "timeout"})
+	kwStats, _ = collector.GetKeywordStats("golang", "example.com")
+	assert.Equal(t, 6, kwStats.LastPosition)
+
+	collector.RecordTask(TaskStats{Keyword: "golang", TargetURL: "example.com", Success: true, Outcome: OutcomeNotInTop})
+	kwStats, _ = collector.GetKeywordStats("golang", "example.com")
+	assert.Equal(t, 0, kwStats.LastPosition)
+	assert.Equal(t, OutcomeNotInTop, kwStats.LastOutcome)
+}
+
+func TestKeywordStats_Competitors(t *testing.T) {
+	collector := NewStatsCollector("test/stats.json")
+
+	collector.RecordTask(TaskStats{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+		Success:   true,
+		Position:  4,
+		Outcome:   OutcomeFound,
+		Depth:     20,
+		Competitors: []CompetitorStats{
+			{TargetURL: "rival.com", Position: 2, PageNumber: 1},
+			{TargetURL: "other.io"},
+		},
+	})
+
+	stats := collector.GetStats()
+	assert.Equal(t, 1, stats.TotalTasks)
+	assert.Len(t, stats.KeywordStats, 3)
+	assert.Len(t, stats.TaskHistory[0].Competitors, 2)
+
+	own, exists := collector.GetKeywordStats("golang", "example.com")
+	require.True(t, exists)
+	assert.False(t, own.Competitor)
+
+	rival, exists := collector.GetKeywordStats("golang", "rival.com")
+	require.True(t, exists)
+	assert.True(t, rival.Competitor)
+	assert.Equal(t, 1, rival.RankedCount)
+	assert.Equal(t, 2, rival.BestPosition)
+
+	other, exists := collector.GetKeywordStats("golang", "other.io")
+	require.True(t, exists)
+	assert.True(t, other.Competitor)
+	assert.Equal(t, 1, other.NotInTopCount)
+	assert.Equal(t, 0, other.RankedCount)
+}
+
+func TestKeywordStats_Locale(t *testing.T) {
+	collector := NewStatsCollector("test/stats.json")
+
+	collector.RecordTask(TaskStats{Keyword: "golang", TargetURL: "example.com", Success: true, Position: 3})
+	collector.RecordTask(TaskStats{
+		Keyword:     "golang",
+		TargetURL:   "example.com",
+		Locale:      "hl=tr,gl=TR,device=mobile",
+		Success:     true,
+		Position:    8,
+		Competitors: []CompetitorStats{{TargetURL: "rival.com", Position: 1}},
+	})
+
+	stats := collector.GetStats()
+	assert.Len(t, stats.KeywordStats, 3)
+
+	global, exists := collector.GetKeywordStats("golang", "example.com")
+	require.True(t, exists)
+	assert.Equal(t, 3, global.BestPosition)
+	assert.Empty(t, global.Locale)
+
+	tr, exists := collector.GetKeywordStatsForLocale("golang", "example.com", "hl=tr,gl=TR,device=mobile")
+	require.True(t, exists)
+	assert.Equal(t, 8, tr.BestPosition)
+	assert.Equal(t, "hl=tr,gl=TR,device=mobile", tr.Locale)
+
+	rival, exists := collector.GetKeywordStatsForLocale("golang", "rival.com", "hl=tr,gl=TR,device=mobile")
+	require.True(t, exists)
+	assert.True(t, rival.Competitor)
+
+	_, exists = collector.GetKeywordStats("golang", "rival.com")
+	assert.False(t, exists)
+}
+
 func TestKeywordStats_NonExistent(t *testing.T) {
 	collector := NewStatsCollector("test/stats.json")
 
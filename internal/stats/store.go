@@ -0,0 +1,252 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Store is an append-only rank history.
+// Unlike StatsCollector, which keeps aggregates and recent tasks in memory,
+// a Store keeps every measured rank on disk and answers time-series queries.
+type Store interface {
+	// Append records the ranks measured by a task: the target and every competitor
+	Append(taskStats TaskStats) error
+
+	// Query returns the rank history of one series, bucketed by query.Granularity
+	Query(query RankQuery) ([]RankBucket, error)
+
+	// Series lists the keyword/target/locale combinations that have history
+	Series() ([]SeriesKey, error)
+
+	// Compact rolls up and deletes old samples according to the retention policy
+	Compact(policy RetentionPolicy, now time.Time) (CompactResult, error)
+
+	// Close releases the underlying storage
+	Close() error
+}
+
+// SeriesKey identifies one rank time series
+type SeriesKey struct {
+	Keyword   string `json:"keyword"`
+	TargetURL string `json:"target_url"`
+	Locale    string `json:"locale,omitempty"`
+}
+
+// String returns the key in the same form as the KeywordStats map keys
+func (k SeriesKey) String() string {
+	return keywordKey(k.Keyword, k.TargetURL, k.Locale)
+}
+
+// RankSample is a single rank measurement of a series
+type RankSample struct {
+	Time           time.Time `json:"time"`
+	Success        bool      `json:"success"`
+	Position       int       `json:"position"`                  // Absolute organic position (0 if not found)
+	VisualPosition int       `json:"visual_position,omitempty"` // Absolute position counting ads and SERP features
+	PageNumber     int       `json:"page_number,omitempty"`     // Page the target was found on
+	Outcome        string    `json:"outcome,omitempty"`         // found or not_in_top
+	Depth          int       `json:"depth,omitempty"`           // Number of organic results scanned
+}
+
+// Granularity is the width of the time buckets returned by Store.Query
+type Granularity string
+
+const (
+	// GranularityHour buckets samples by hour
+	GranularityHour Granularity = "hour"
+	// GranularityDay buckets samples by calendar day (UTC)
+	GranularityDay Granularity = "day"
+	// GranularityWeek buckets samples by week, starting on Monday (UTC)
+	GranularityWeek Granularity = "week"
+)
+
+// ParseGranularity converts a command line or API value into a Granularity
+func ParseGranularity(s string) (Granularity, error) {
+	switch g := Granularity(strings.ToLower(strings.TrimSpace(s))); g {
+	case GranularityHour, GranularityDay, GranularityWeek:
+		return g, nil
+	case "":
+		return GranularityDay, nil
+	default:
+		return "", fmt.Errorf("unknown granularity: %s", s)
+	}
+}
+
+// truncate returns the start of the bucket that contains t
+func (g Granularity) truncate(t time.Time) time.Time {
+	t = t.UTC()
+	switch g {
+	case GranularityHour:
+		return t.Truncate(time.Hour)
+	case GranularityWeek:
+		day := startOfDay(t)
+		offset := (int(day.Weekday()) + 6) % 7 // Days since Monday
+		return day.AddDate(0, 0, -offset)
+	default:
+		return startOfDay(t)
+	}
+}
+
+// RankQuery selects the history of one series over a time range
+type RankQuery struct {
+	Keyword     string
+	TargetURL   string
+	Locale      string      // Search context (empty = searches without a locale)
+	From        time.Time   // Inclusive start (zero = beginning of history)
+	To          time.Time   // Inclusive end (zero = now)
+	Granularity Granularity // Bucket width (default: day)
+}
+
+// Series returns the series the query reads
+func (q RankQuery) Series() SeriesKey {
+	return SeriesKey{Keyword: q.Keyword, TargetURL: q.TargetURL, Locale: q.Locale}
+}
+
+// normalize validates the query and fills in defaults
+func (q RankQuery) normalize(now time.Time) (RankQuery, error) {
+	if q.Keyword == "" || q.TargetURL == "" {
+		return q, fmt.Errorf("keyword and target URL are required")
+	}
+	if q.Granularity == "" {
+		q.Granularity = GranularityDay
+	}
+	if _, err := ParseGranularity(string(q.Granularity)); err != nil {
+		return q, err
+	}
+	if q.To.IsZero() {
+		q.To = now
+	}
+	if q.To.Before(q.From) {
+		return q, fmt.Errorf("query end %s is before start %s", q.To.Format(time.RFC3339), q.From.Format(time.RFC3339))
+	}
+	return q, nil
+}
+
+// RankBucket aggregates the samples of a series within one time bucket
+type RankBucket struct {
+	Start         time.Time `json:"start"`         // Bucket start (UTC)
+	Samples       int       `json:"samples"`       // Measurements in the bucket
+	Ranked        int       `json:"ranked"`        // Measurements where a position was found
+	NotInTop      int       `json:"not_in_top"`    // Measurements where the target was not in the scanned results
+	Failed        int       `json:"failed"`        // Measurements that failed before a rank was known
+	AvgPosition   float64   `json:"avg_position"`  // Average position over the ranked measurements
+	BestPosition  int       `json:"best_position"` // Best (lowest) position (0 if never ranked)
+	WorstPosition int       `json:"worst_position"`
+	LastPosition  int       `json:"last_position"` // Position of the latest ranked measurement
+}
+
+// add adds a sample to the bucket; samples must be added in time order
+func (b *RankBucket) add(sample RankSample) {
+	b.Samples++
+	switch {
+	case sample.Position > 0:
+		b.addRank(1, float64(sample.Position), sample.Position, sample.Position)
+		b.LastPosition = sample.Position
+	case sample.Outcome == OutcomeNotInTop:
+		b.NotInTop++
+	case !sample.Success:
+		b.Failed++
+	}
+}
+
+// merge adds a later bucket (e.g. a daily rollup) to the bucket
+func (b *RankBucket) merge(other RankBucket) {
+	b.Samples += other.Samples
+	b.NotInTop += other.NotInTop
+	b.Failed += other.Failed
+	if other.Ranked > 0 {
+		b.addRank(other.Ranked, other.AvgPosition, other.BestPosition, other.WorstPosition)
+		b.LastPosition = other.LastPosition
+	}
+}
+
+// addRank folds n ranked measurements with the given average and range into the bucket
+func (b *RankBucket) addRank(n int, avg float64, best, worst int) {
+	total := b.AvgPosition*float64(b.Ranked) + avg*float64(n)
+	b.Ranked += n
+	b.AvgPosition = total / float64(b.Ranked)
+	if b.BestPosition == 0 || best < b.BestPosition {
+		b.BestPosition = best
+	}
+	if worst > b.WorstPosition {
+		b.WorstPosition = worst
+	}
+}
+
+// RetentionPolicy controls how long rank history is kept
+type RetentionPolicy struct {
+	RawFor  time.Duration // Keep individual samples this long, then roll them up into daily buckets (0 = never roll up)
+	KeepFor time.Duration // Delete history older than this (0 = keep forever)
+}
+
+// CompactResult reports what a compaction changed
+type CompactResult struct {
+	RolledUp int // Samples merged into daily buckets
+	Deleted  int // Samples and daily buckets removed by KeepFor
+}
+
+// bucketer collects buckets keyed by their start time
+type bucketer struct {
+	granularity Granularity
+	buckets     map[time.Time]*RankBucket
+}
+
+func newBucketer(granularity Granularity) *bucketer {
+	return &bucketer{granularity: granularity, buckets: make(map[time.Time]*RankBucket)}
+}
+
+// bucket returns the bucket that contains t
+func (b *bucketer) bucket(t time.Time) *RankBucket {
+	start := b.granularity.truncate(t)
+	bucket, ok := b.buckets[start]
+	if !ok {
+		bucket = &RankBucket{Start: start}
+		b.buckets[start] = bucket
+	}
+	return bucket
+}
+
+// sorted returns the buckets in time order
+func (b *bucketer) sorted() []RankBucket {
+	buckets := make([]RankBucket, 0, len(b.buckets))
+	for _, bucket := range b.buckets {
+		buckets = append(buckets, *bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Start.Before(buckets[j].Start)
+	})
+	return buckets
+}
+
+// rankSamples splits a task into one sample per series: the target and each competitor
+func rankSamples(taskStats TaskStats) map[SeriesKey]RankSample {
+	if taskStats.Timestamp.IsZero() {
+		taskStats.Timestamp = time.Now()
+	}
+
+	samples := make(map[SeriesKey]RankSample, len(taskStats.Competitors)+1)
+	for _, ts := range append([]TaskStats{taskStats}, taskStats.CompetitorTasks()...) {
+		if ts.Keyword == "" || ts.TargetURL == "" {
+			continue
+		}
+		key := SeriesKey{Keyword: ts.Keyword, TargetURL: ts.TargetURL, Locale: ts.Locale}
+		samples[key] = RankSample{
+			Time:           ts.Timestamp.UTC(),
+			Success:        ts.Success,
+			Position:       ts.Position,
+			VisualPosition: ts.VisualPosition,
+			PageNumber:     ts.PageNumber,
+			Outcome:        ts.Outcome,
+			Depth:          ts.Depth,
+		}
+	}
+	return samples
+}
+
+// startOfDay returns midnight (UTC) of the day that contains t
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
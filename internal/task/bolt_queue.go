@@ -0,0 +1,397 @@
+package task
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Bucket layout of a BoltQueue:
+//
+//	tasks/<sequence> -> queueRecord
+//
+// Keys are big-endian sequence numbers so cursors walk tasks in FIFO order.
+// The queue indexes the records in memory, so Dequeue reads only the record
+// it leases.
+var queueBucket = []byte("tasks")
+
+// maxQueuePoll bounds how long an idle Dequeue sleeps before it looks for
+// expired leases again
+const maxQueuePoll = time.Second
+
+// queueRecord is a task stored in a BoltQueue
+type queueRecord struct {
+	Task        *Task     `json:"task"`
+	LeasedUntil time.Time `json:"leased_until"` // Zero while the task waits for a worker
+	Attempts    int       `json:"attempts"`     // Number of times the task was leased
+}
+
+// BoltQueueConfig holds configuration for opening a BoltQueue
+type BoltQueueConfig struct {
+	LeaseTimeout time.Duration // Time a worker has to Ack a task before it is delivered again (default: 10m)
+}
+
+// BoltQueue is a durable Queue backed by an embedded bbolt database file.
+// Tasks survive crashes and restarts: tasks that were leased when the
+// previous process stopped are requeued when the file is opened again.
+type BoltQueue struct {
+	db           *bolt.DB
+	leaseTimeout time.Duration
+	now          func() time.Time
+	mu           sync.Mutex
+	keys         map[string][]byte    // Task ID -> record key
+	waiting      []string             // Keys of the records waiting for a worker, in FIFO order
+	leases       map[string]time.Time // Record key -> lease expiry of the leased records
+	recovered    int
+	unfinished   []*Task // Tasks stored when the queue was opened
+	wake         chan struct{}
+	closed       chan struct{}
+	closeOnce    sync.Once
+}
+
+// OpenBoltQueue opens (or creates) the queue database at path and requeues
+// the tasks that were still leased by the previous process.
+// The file is locked while open; a second process waits up to one second
+// for the lock before giving up.
+//
+// Example:
+//
+//	queue, err := task.OpenBoltQueue("data/queue.db", task.BoltQueueConfig{})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer queue.Close()
+//
+//	pool := task.NewWorkerPool(task.WorkerPoolConfig{
+//	    Workers: 5,
+//	    Queue:   queue,
+//	    Logger:  log,
+//	})
+func OpenBoltQueue(path string, config BoltQueueConfig) (*BoltQueue, error) {
+	// Set defaults
+	if config.LeaseTimeout <= 0 {
+		config.LeaseTimeout = 10 * time.Minute
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open queue database: %w", err)
+	}
+
+	q := &BoltQueue{
+		db:           db,
+		leaseTimeout: config.LeaseTimeout,
+		now:          time.Now,
+		keys:         make(map[string][]byte),
+		leases:       make(map[string]time.Time),
+		wake:         make(chan struct{}, 1),
+		closed:       make(chan struct{}),
+	}
+	if err := q.recover(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize queue database: %w", err)
+	}
+	return q, nil
+}
+
+// recover indexes the stored tasks and requeues the leased ones
+func (q *BoltQueue) recover() error {
+	return q.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(queueBucket)
+		if err != nil {
+			return err
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var record queueRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				return fmt.Errorf("failed to decode task %x: %w", k, err)
+			}
+			q.keys[record.Task.ID] = append([]byte(nil), k...)
+			q.waiting = append(q.waiting, string(k))
+			q.unfinished = append(q.unfinished, record.Task)
+
+			if record.LeasedUntil.IsZero() {
+				return nil
+			}
+			record.LeasedUntil = time.Time{}
+			q.recovered++
+			return putRecord(bucket, k, &record)
+		})
+	})
+}
+
+// Recovered returns the number of leased tasks requeued when the queue was opened
+func (q *BoltQueue) Recovered() int {
+	return q.recovered
+}
+
+// Unfinished returns the tasks the previous process left in the queue,
+// waiting or running, when it was opened
+func (q *BoltQueue) Unfinished() []*Task {
+	return q.unfinished
+}
+
+// Enqueue stores a task at the end of the queue
+func (q *BoltQueue) Enqueue(ctx context.Context, task *Task) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if q.isClosed() {
+		return ErrQueueClosed
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.keys[task.ID]; ok {
+		return fmt.Errorf("task %s is already queued", task.ID)
+	}
+
+	var key []byte
+	err := q.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(queueBucket)
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		key = make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		return putRecord(bucket, key, &queueRecord{Task: task})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	q.keys[task.ID] = key
+	q.waiting = append(q.waiting, string(key))
+	q.notify()
+	return nil
+}
+
+// Dequeue waits for the oldest waiting task, or a task whose lease expired,
+// and leases it for the lease timeout
+func (q *BoltQueue) Dequeue(ctx context.Context) (*Task, error) {
+	for {
+		task, nextExpiry, err := q.lease()
+		if err != nil {
+			return nil, err
+		}
+		if task != nil {
+			if q.Len() > 0 {
+				// Let another waiting worker pick up the rest
+				q.notify()
+			}
+			return task, nil
+		}
+
+		wait := maxQueuePoll
+		if !nextExpiry.IsZero() {
+			wait = min(wait, nextExpiry.Sub(q.now()))
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-q.closed:
+			timer.Stop()
+			return nil, ErrQueueClosed
+		case <-q.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// lease leases the first available task. Without one, it returns the time
+// the earliest lease expires (zero if nothing is leased).
+func (q *BoltQueue) lease() (task *Task, nextExpiry time.Time, err error) {
+	if q.isClosed() {
+		return nil, time.Time{}, ErrQueueClosed
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	key, ok := q.nextKey(now)
+	if !ok {
+		for _, until := range q.leases {
+			if nextExpiry.IsZero() || until.Before(nextExpiry) {
+				nextExpiry = until
+			}
+		}
+		return nil, nextExpiry, nil
+	}
+
+	leasedUntil := now.Add(q.leaseTimeout)
+	err = q.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(queueBucket)
+		var record queueRecord
+		if err := json.Unmarshal(bucket.Get([]byte(key)), &record); err != nil {
+			return fmt.Errorf("failed to decode task %x: %w", key, err)
+		}
+		record.LeasedUntil = leasedUntil
+		record.Attempts++
+		task = record.Task
+		return putRecord(bucket, []byte(key), &record)
+	})
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to dequeue task: %w", err)
+	}
+
+	q.removeWaiting(key)
+	q.leases[key] = leasedUntil
+	return task, time.Time{}, nil
+}
+
+// nextKey returns the key of the oldest record that is waiting or whose
+// lease expired at now; q.mu must be held
+func (q *BoltQueue) nextKey(now time.Time) (string, bool) {
+	key, ok := "", false
+	if len(q.waiting) > 0 {
+		key, ok = q.waiting[0], true
+	}
+	for leased, until := range q.leases {
+		if !until.After(now) && (!ok || leased < key) {
+			key, ok = leased, true
+		}
+	}
+	return key, ok
+}
+
+// insertWaiting adds a key to the waiting records in FIFO order; q.mu must be held
+func (q *BoltQueue) insertWaiting(key string) {
+	i := sort.SearchStrings(q.waiting, key)
+	if i < len(q.waiting) && q.waiting[i] == key {
+		return
+	}
+	q.waiting = append(q.waiting, "")
+	copy(q.waiting[i+1:], q.waiting[i:])
+	q.waiting[i] = key
+}
+
+// removeWaiting removes a key from the waiting records; q.mu must be held
+func (q *BoltQueue) removeWaiting(key string) {
+	i := sort.SearchStrings(q.waiting, key)
+	if i < len(q.waiting) && q.waiting[i] == key {
+		q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+	}
+}
+
+// Ack removes a task from the queue
+func (q *BoltQueue) Ack(taskID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key, ok := q.keys[taskID]
+	if !ok {
+		return fmt.Errorf("task %s is not queued", taskID)
+	}
+	err := q.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(queueBucket).Delete(key)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to ack task: %w", err)
+	}
+
+	delete(q.keys, taskID)
+	delete(q.leases, string(key))
+	q.removeWaiting(string(key))
+	return nil
+}
+
+// Nack ends the lease of a task so it is delivered again
+func (q *BoltQueue) Nack(taskID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key, ok := q.keys[taskID]
+	if !ok {
+		return fmt.Errorf("task %s is not queued", taskID)
+	}
+	err := q.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(queueBucket)
+		var record queueRecord
+		if err := json.Unmarshal(bucket.Get(key), &record); err != nil {
+			return fmt.Errorf("failed to decode task %s: %w", taskID, err)
+		}
+		record.LeasedUntil = time.Time{}
+		return putRecord(bucket, key, &record)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to nack task: %w", err)
+	}
+
+	delete(q.leases, string(key))
+	q.insertWaiting(string(key))
+	q.notify()
+	return nil
+}
+
+// Len returns the number of tasks waiting for a worker. Tasks whose lease
+// expired are not counted; they are delivered again by the next Dequeue.
+func (q *BoltQueue) Len() int {
+	if q.isClosed() {
+		return 0
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.waiting)
+}
+
+// Close wakes waiting Dequeue calls and closes the database.
+// Waiting and leased tasks stay in the file for the next process.
+func (q *BoltQueue) Close() error {
+	var err error
+	q.closeOnce.Do(func() {
+		close(q.closed)
+
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		err = q.db.Close()
+	})
+	return err
+}
+
+// isClosed reports whether Close was called
+func (q *BoltQueue) isClosed() bool {
+	select {
+	case <-q.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// notify wakes one waiting Dequeue call
+func (q *BoltQueue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// putRecord stores a queue record
+func putRecord(bucket *bolt.Bucket, key []byte, record *queueRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+	return bucket.Put(key, data)
+}
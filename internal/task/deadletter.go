@@ -0,0 +1,224 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/omer/go-bot/internal/metrics"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Bucket layout of a DeadLetterStore:
+//
+//	failures/<task id> -> DeadLetter
+var deadLetterBucket = []byte("failures")
+
+// ErrDeadLetterNotFound is returned for task IDs without a dead letter
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// DeadLetter is a failed task kept for triage
+type DeadLetter struct {
+	Task       *Task      `json:"task"`
+	FailedAt   time.Time  `json:"failed_at"`
+	Error      string     `json:"error"`
+	ErrorType  string     `json:"error_type"`            // Error category, e.g. timeout, captcha or selector
+	Page       *PageState `json:"page,omitempty"`        // Page the browser was on when the task failed
+	Attempts   []Attempt  `json:"attempts,omitempty"`    // Every execution, including earlier runs before a requeue
+	Requeued   int        `json:"requeued,omitempty"`    // Number of times the task was requeued
+	RequeuedAt *time.Time `json:"requeued_at,omitempty"` // Last requeue (nil if never requeued)
+}
+
+// NewDeadLetter creates the dead letter of a failed task result
+func NewDeadLetter(result *TaskResult) *DeadLetter {
+	letter := &DeadLetter{
+		Task:      result.Task,
+		FailedAt:  time.Now(),
+		ErrorType: metrics.ErrorType(result.Error).String(),
+		Page:      result.Page,
+		Attempts:  result.History,
+	}
+	if result.Task.CompletedAt != nil {
+		letter.FailedAt = *result.Task.CompletedAt
+	}
+	if result.Error != nil {
+		letter.Error = result.Error.Error()
+	}
+	return letter
+}
+
+// DeadLetterStore keeps failed tasks in an embedded bbolt database file.
+// A task that fails again replaces its previous dead letter and keeps its
+// attempt history.
+type DeadLetterStore struct {
+	db *bolt.DB
+}
+
+// OpenDeadLetterStore opens (or creates) the dead-letter database at path.
+// The file is locked while open; a second process waits up to one second
+// for the lock before giving up.
+//
+// Example:
+//
+//	store, err := task.OpenDeadLetterStore("data/failures.db")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer store.Close()
+//
+//	if !result.Success {
+//	    store.Add(task.NewDeadLetter(result))
+//	}
+func OpenDeadLetterStore(path string) (*DeadLetterStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dead-letter database: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(deadLetterBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize dead-letter database: %w", err)
+	}
+
+	return &DeadLetterStore{db: db}, nil
+}
+
+// Add stores a dead letter. The attempts of a previous dead letter of the
+// same task are kept in front of the new ones.
+func (s *DeadLetterStore) Add(letter *DeadLetter) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(deadLetterBucket)
+		key := []byte(letter.Task.ID)
+
+		if data := bucket.Get(key); data != nil {
+			var previous DeadLetter
+			if err := json.Unmarshal(data, &previous); err != nil {
+				return fmt.Errorf("failed to decode dead letter %s: %w", letter.Task.ID, err)
+			}
+			letter.Attempts = append(previous.Attempts, letter.Attempts...)
+			letter.Requeued = previous.Requeued
+			letter.RequeuedAt = previous.RequeuedAt
+		}
+
+		data, err := json.Marshal(letter)
+		if err != nil {
+			return fmt.Errorf("failed to marshal dead letter: %w", err)
+		}
+		return bucket.Put(key, data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add dead letter: %w", err)
+	}
+	return nil
+}
+
+// Get returns the dead letter of a task
+func (s *DeadLetterStore) Get(taskID string) (*DeadLetter, error) {
+	var letter DeadLetter
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(deadLetterBucket).Get([]byte(taskID))
+		if data == nil {
+			return fmt.Errorf("%w: %s", ErrDeadLetterNotFound, taskID)
+		}
+		return json.Unmarshal(data, &letter)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &letter, nil
+}
+
+// List returns all dead letters, most recent failure first
+func (s *DeadLetterStore) List() ([]*DeadLetter, error) {
+	var letters []*DeadLetter
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(deadLetterBucket).ForEach(func(k, v []byte) error {
+			var letter DeadLetter
+			if err := json.Unmarshal(v, &letter); err != nil {
+				return fmt.Errorf("failed to decode dead letter %s: %w", k, err)
+			}
+			letters = append(letters, &letter)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(letters, func(i, j int) bool {
+		return letters[i].FailedAt.After(letters[j].FailedAt)
+	})
+	return letters, nil
+}
+
+// Requeue resets the task of a dead letter and adds it to queue. The dead
+// letter is kept, marked as requeued, until the task succeeds (see Resolve);
+// a new failure extends its attempt history.
+func (s *DeadLetterStore) Requeue(ctx context.Context, taskID string, queue Queue) error {
+	letter, err := s.Get(taskID)
+	if err != nil {
+		return err
+	}
+
+	letter.Task.Status = TaskStatusPending
+	letter.Task.StartedAt = nil
+	letter.Task.CompletedAt = nil
+	if err := queue.Enqueue(ctx, letter.Task); err != nil {
+		return fmt.Errorf("failed to requeue task %s: %w", taskID, err)
+	}
+
+	now := time.Now()
+	letter.Requeued++
+	letter.RequeuedAt = &now
+	return s.put(letter)
+}
+
+// Resolve removes the dead letter of a task that succeeded after a requeue.
+// It does nothing for tasks without a dead letter.
+func (s *DeadLetterStore) Resolve(taskID string) error {
+	if _, err := s.Get(taskID); errors.Is(err, ErrDeadLetterNotFound) {
+		return nil
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(deadLetterBucket).Delete([]byte(taskID))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to resolve dead letter: %w", err)
+	}
+	return nil
+}
+
+// put stores a dead letter as is
+func (s *DeadLetterStore) put(letter *DeadLetter) error {
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(deadLetterBucket).Put([]byte(letter.Task.ID), data)
+	})
+}
+
+// Close closes the database
+func (s *DeadLetterStore) Close() error {
+	return s.db.Close()
+}
+
+// Pending reports whether the task was requeued and has not failed since
+func (l *DeadLetter) Pending() bool {
+	return l.RequeuedAt != nil && l.RequeuedAt.After(l.FailedAt)
+}
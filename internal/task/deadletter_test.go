@@ -0,0 +1,126 @@
+package task
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apperrors "github.com/omer/go-bot/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFailedResult creates the result of a task that failed after two attempts
+func newFailedResult(t *testing.T, keyword string, err error) *TaskResult {
+	t.Helper()
+	task := newQueueTask(t, keyword)
+	task.MarkRunning()
+	task.MarkFailed()
+
+	result := NewTaskResult(task, false, err)
+	result.Page = &PageState{URL: "https://www.google.com/sorry/index", Title: "Sorry"}
+	result.History = []Attempt{
+		newAttempt(time.Now(), err),
+		newAttempt(time.Now(), err),
+	}
+	result.Attempts = len(result.History)
+	return result
+}
+
+// openTestDeadLetters opens a DeadLetterStore in a test directory
+func openTestDeadLetters(t *testing.T) *DeadLetterStore {
+	t.Helper()
+	store, err := OpenDeadLetterStore(filepath.Join(t.TempDir(), "failures.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// ===== DeadLetterStore tests =====
+
+func TestNewDeadLetter(t *testing.T) {
+	result := newFailedResult(t, "golang", apperrors.NewCaptchaError("CAPTCHA detected"))
+
+	letter := NewDeadLetter(result)
+	assert.Equal(t, result.Task, letter.Task)
+	assert.Equal(t, "captcha", letter.ErrorType)
+	assert.Equal(t, "[captcha] CAPTCHA detected", letter.Error)
+	assert.Equal(t, "Sorry", letter.Page.Title)
+	assert.Len(t, letter.Attempts, 2)
+	assert.Equal(t, "captcha", letter.Attempts[0].ErrorType)
+	assert.Equal(t, *result.Task.CompletedAt, letter.FailedAt)
+}
+
+func TestDeadLetterStore_AddGetList(t *testing.T) {
+	store := openTestDeadLetters(t)
+
+	older := NewDeadLetter(newFailedResult(t, "golang", context.DeadlineExceeded))
+	older.FailedAt = time.Now().Add(-time.Hour)
+	newer := NewDeadLetter(newFailedResult(t, "rust", apperrors.NewSelectorError("no results found", nil)))
+	require.NoError(t, store.Add(older))
+	require.NoError(t, store.Add(newer))
+
+	got, err := store.Get(older.Task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "golang", got.Task.Keyword)
+	assert.Equal(t, "timeout", got.ErrorType)
+	assert.Equal(t, "https://www.google.com/sorry/index", got.Page.URL)
+
+	_, err = store.Get("task-unknown")
+	assert.ErrorIs(t, err, ErrDeadLetterNotFound)
+
+	letters, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, letters, 2)
+	assert.Equal(t, newer.Task.ID, letters[0].Task.ID, "most recent first")
+	assert.Equal(t, older.Task.ID, letters[1].Task.ID)
+}
+
+func TestDeadLetterStore_RequeueAndFailAgain(t *testing.T) {
+	store := openTestDeadLetters(t)
+	queue := NewMemoryQueue(1)
+
+	result := newFailedResult(t, "golang", apperrors.NewNetworkError("request failed", nil))
+	require.NoError(t, store.Add(NewDeadLetter(result)))
+	require.NoError(t, store.Requeue(context.Background(), result.Task.ID, queue))
+
+	requeued, err := dequeue(t, queue)
+	require.NoError(t, err)
+	assert.Equal(t, result.Task.ID, requeued.ID)
+	assert.Equal(t, TaskStatusPending, requeued.Status)
+	assert.Nil(t, requeued.StartedAt)
+
+	letter, err := store.Get(result.Task.ID)
+	require.NoError(t, err)
+	assert.True(t, letter.Pending())
+	assert.Equal(t, 1, letter.Requeued)
+
+	// The requeued task fails again; its attempt history grows
+	requeued.MarkRunning()
+	requeued.MarkFailed()
+	again := NewTaskResult(requeued, false, apperrors.NewNetworkError("request failed", nil))
+	again.History = []Attempt{newAttempt(time.Now(), again.Error)}
+	require.NoError(t, store.Add(NewDeadLetter(again)))
+
+	letter, err = store.Get(result.Task.ID)
+	require.NoError(t, err)
+	assert.False(t, letter.Pending())
+	assert.Equal(t, 1, letter.Requeued)
+	assert.Len(t, letter.Attempts, 3)
+
+	assert.ErrorIs(t, store.Requeue(context.Background(), "task-unknown", queue), ErrDeadLetterNotFound)
+}
+
+func TestDeadLetterStore_Resolve(t *testing.T) {
+	store := openTestDeadLetters(t)
+
+	result := newFailedResult(t, "golang", context.DeadlineExceeded)
+	require.NoError(t, store.Add(NewDeadLetter(result)))
+
+	require.NoError(t, store.Resolve(result.Task.ID))
+	_, err := store.Get(result.Task.ID)
+	assert.ErrorIs(t, err, ErrDeadLetterNotFound)
+
+	assert.NoError(t, store.Resolve("task-unknown"), "tasks that never failed")
+}
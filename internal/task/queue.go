@@ -0,0 +1,126 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrQueueClosed is returned by Dequeue after the queue is closed and empty
+var ErrQueueClosed = errors.New("queue is closed")
+
+// Queue holds tasks waiting for a worker.
+//
+// Dequeue leases a task to one worker. A leased task is delivered again when
+// it is not acknowledged in time: after its lease expires or, for durable
+// queues, after the process restarts (at-least-once delivery). Workers must
+// therefore Ack every task once its result is delivered.
+type Queue interface {
+	// Enqueue adds a task; bounded queues block while they are full
+	Enqueue(ctx context.Context, task *Task) error
+
+	// Dequeue waits for the next task and leases it to the caller
+	Dequeue(ctx context.Context) (*Task, error)
+
+	// Ack removes a leased task from the queue
+	Ack(taskID string) error
+
+	// Nack ends the lease of a task so it is delivered again
+	Nack(taskID string) error
+
+	// Len returns the number of tasks waiting to be dequeued
+	Len() int
+
+	// Close releases the queue; leased and waiting tasks of durable queues
+	// are kept for the next process
+	Close() error
+}
+
+// MemoryQueue is a bounded in-memory Queue. Tasks are lost when the process
+// exits, and leases never expire because a worker of the same process holds them.
+type MemoryQueue struct {
+	tasks  chan *Task
+	mu     sync.Mutex
+	leased map[string]*Task
+}
+
+// NewMemoryQueue creates an in-memory queue holding up to size tasks
+// (0 = Enqueue waits for a worker to Dequeue)
+func NewMemoryQueue(size int) *MemoryQueue {
+	if size < 0 {
+		size = 0
+	}
+	return &MemoryQueue{
+		tasks:  make(chan *Task, size),
+		leased: make(map[string]*Task),
+	}
+}
+
+// Enqueue adds a task, waiting while the queue is full
+func (q *MemoryQueue) Enqueue(ctx context.Context, task *Task) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case q.tasks <- task:
+		return nil
+	}
+}
+
+// Dequeue waits for the next task
+func (q *MemoryQueue) Dequeue(ctx context.Context) (*Task, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case task := <-q.tasks:
+		q.mu.Lock()
+		q.leased[task.ID] = task
+		q.mu.Unlock()
+		return task, nil
+	}
+}
+
+// Ack forgets a leased task
+func (q *MemoryQueue) Ack(taskID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.leased[taskID]; !ok {
+		return fmt.Errorf("task %s is not leased", taskID)
+	}
+	delete(q.leased, taskID)
+	return nil
+}
+
+// Nack puts a leased task back; it fails if the queue is full
+func (q *MemoryQueue) Nack(taskID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	task, ok := q.leased[taskID]
+	if !ok {
+		return fmt.Errorf("task %s is not leased", taskID)
+	}
+	select {
+	case q.tasks <- task:
+		delete(q.leased, taskID)
+		return nil
+	default:
+		return fmt.Errorf("queue is full")
+	}
+}
+
+// Len returns the number of waiting tasks
+func (q *MemoryQueue) Len() int {
+	return len(q.tasks)
+}
+
+// Cap returns the maximum number of waiting tasks
+func (q *MemoryQueue) Cap() int {
+	return cap(q.tasks)
+}
+
+// Close does nothing; in-memory tasks do not outlive the process
+func (q *MemoryQueue) Close() error {
+	return nil
+}
@@ -0,0 +1,420 @@
+package task
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apperrors "github.com/omer/go-bot/internal/errors"
+	"github.com/omer/go-bot/internal/logger"
+	"github.com/omer/go-bot/internal/serp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newQueueTask creates a task for queue tests
+func newQueueTask(t *testing.T, keyword string) *Task {
+	t.Helper()
+	task, err := NewTask(TaskConfig{
+		Keyword:   keyword,
+		TargetURL: "example.com",
+		Locale:    serp.Locale{HL: "tr", GL: "TR"},
+		Type:      TaskTypeRankCheck,
+	})
+	require.NoError(t, err)
+	return task
+}
+
+// openTestQueue opens a BoltQueue in a test directory
+func openTestQueue(t *testing.T, path string, leaseTimeout time.Duration) *BoltQueue {
+	t.Helper()
+	queue, err := OpenBoltQueue(path, BoltQueueConfig{LeaseTimeout: leaseTimeout})
+	require.NoError(t, err)
+	return queue
+}
+
+// dequeue dequeues with a short timeout
+func dequeue(t *testing.T, queue Queue) (*Task, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	return queue.Dequeue(ctx)
+}
+
+// ===== MemoryQueue tests =====
+
+func TestMemoryQueue(t *testing.T) {
+	queue := NewMemoryQueue(2)
+	ctx := context.Background()
+	assert.Equal(t, 2, queue.Cap())
+
+	first, second := newQueueTask(t, "golang"), newQueueTask(t, "rust")
+	require.NoError(t, queue.Enqueue(ctx, first))
+	require.NoError(t, queue.Enqueue(ctx, second))
+	assert.Equal(t, 2, queue.Len())
+
+	// A full queue blocks until the context ends
+	full, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, queue.Enqueue(full, newQueueTask(t, "zig")), context.DeadlineExceeded)
+
+	got, err := dequeue(t, queue)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, got.ID)
+
+	require.NoError(t, queue.Nack(first.ID))
+	assert.Error(t, queue.Ack(first.ID), "no longer leased")
+
+	got, err = dequeue(t, queue)
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, got.ID)
+	require.NoError(t, queue.Ack(second.ID))
+
+	got, err = dequeue(t, queue)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, got.ID, "nacked tasks are delivered again")
+	require.NoError(t, queue.Ack(first.ID))
+
+	_, err = dequeue(t, queue)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// ===== BoltQueue tests =====
+
+func TestBoltQueue_FIFOAndAck(t *testing.T) {
+	queue := openTestQueue(t, filepath.Join(t.TempDir(), "queue.db"), time.Minute)
+	defer queue.Close()
+	ctx := context.Background()
+
+	tasks := []*Task{newQueueTask(t, "golang"), newQueueTask(t, "rust"), newQueueTask(t, "zig")}
+	for _, task := range tasks {
+		require.NoError(t, queue.Enqueue(ctx, task))
+	}
+	assert.Error(t, queue.Enqueue(ctx, tasks[0]), "already queued")
+	assert.Equal(t, 3, queue.Len())
+
+	for _, want := range tasks {
+		got, err := dequeue(t, queue)
+		require.NoError(t, err)
+		assert.Equal(t, want.ID, got.ID)
+		assert.Equal(t, want.Keyword, got.Keyword)
+		assert.Equal(t, want.Locale, got.Locale)
+		assert.Equal(t, TaskTypeRankCheck, got.Type)
+		require.NoError(t, queue.Ack(got.ID))
+	}
+
+	assert.Equal(t, 0, queue.Len())
+	assert.Error(t, queue.Ack(tasks[0].ID), "already acked")
+	_, err := dequeue(t, queue)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestBoltQueue_LeaseExpires(t *testing.T) {
+	queue := openTestQueue(t, filepath.Join(t.TempDir(), "queue.db"), 50*time.Millisecond)
+	defer queue.Close()
+
+	task := newQueueTask(t, "golang")
+	require.NoError(t, queue.Enqueue(context.Background(), task))
+
+	got, err := dequeue(t, queue)
+	require.NoError(t, err)
+	assert.Equal(t, 0, queue.Len(), "leased")
+
+	// The worker never acks; the task is delivered again after the lease
+	start := time.Now()
+	again, err := dequeue(t, queue)
+	require.NoError(t, err)
+	assert.Equal(t, got.ID, again.ID)
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+	// Only the second worker's ack is left to count
+	require.NoError(t, queue.Ack(again.ID))
+	assert.Equal(t, 0, queue.Len())
+}
+
+func TestBoltQueue_Nack(t *testing.T) {
+	queue := openTestQueue(t, filepath.Join(t.TempDir(), "queue.db"), time.Hour)
+	defer queue.Close()
+
+	task := newQueueTask(t, "golang")
+	require.NoError(t, queue.Enqueue(context.Background(), task))
+	_, err := dequeue(t, queue)
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, queue.Len(), "leased")
+
+	require.NoError(t, queue.Nack(task.ID))
+	require.NoError(t, queue.Nack(task.ID))
+	assert.Equal(t, 1, queue.Len(), "nacking a waiting task does not count it twice")
+	again, err := dequeue(t, queue)
+	require.NoError(t, err)
+	assert.Equal(t, task.ID, again.ID)
+	assert.Equal(t, 0, queue.Len())
+
+	// Acking a task that is not leased removes it from the waiting tasks
+	require.NoError(t, queue.Nack(task.ID))
+	require.NoError(t, queue.Ack(task.ID))
+	assert.Equal(t, 0, queue.Len())
+}
+
+func TestBoltQueue_RedeliveryKeepsOrder(t *testing.T) {
+	queue := openTestQueue(t, filepath.Join(t.TempDir(), "queue.db"), time.Hour)
+	defer queue.Close()
+
+	clock := time.Now()
+	queue.now = func() time.Time { return clock }
+
+	tasks := []*Task{newQueueTask(t, "golang"), newQueueTask(t, "rust"), newQueueTask(t, "zig")}
+	for _, task := range tasks {
+		require.NoError(t, queue.Enqueue(context.Background(), task))
+	}
+	for range tasks[:2] {
+		_, err := dequeue(t, queue)
+		require.NoError(t, err)
+	}
+
+	// A nacked task and a task whose lease expired go before newer tasks
+	require.NoError(t, queue.Nack(tasks[1].ID))
+	clock = clock.Add(2 * time.Hour)
+	for _, want := range tasks {
+		got, err := dequeue(t, queue)
+		require.NoError(t, err)
+		assert.Equal(t, want.ID, got.ID)
+	}
+	assert.Equal(t, 0, queue.Len())
+}
+
+func TestBoltQueue_RequeueOnRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.db")
+	queue := openTestQueue(t, path, time.Hour)
+
+	running, waiting := newQueueTask(t, "golang"), newQueueTask(t, "rust")
+	require.NoError(t, queue.Enqueue(context.Background(), running))
+	require.NoError(t, queue.Enqueue(context.Background(), waiting))
+	_, err := dequeue(t, queue)
+	require.NoError(t, err)
+
+	// The process stops while the first task is running
+	require.NoError(t, queue.Close())
+
+	reopened := openTestQueue(t, path, time.Hour)
+	defer reopened.Close()
+	assert.Equal(t, 1, reopened.Recovered())
+	assert.Equal(t, 2, reopened.Len())
+
+	got, err := dequeue(t, reopened)
+	require.NoError(t, err)
+	assert.Equal(t, running.ID, got.ID, "the running task is requeued in its place")
+}
+
+func TestBoltQueue_CloseWakesDequeue(t *testing.T) {
+	queue := openTestQueue(t, filepath.Join(t.TempDir(), "queue.db"), time.Minute)
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := queue.Dequeue(context.Background())
+		errs <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, queue.Close())
+
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, ErrQueueClosed)
+	case <-time.After(time.Second):
+		t.Fatal("Dequeue did not return after Close")
+	}
+	assert.ErrorIs(t, queue.Enqueue(context.Background(), newQueueTask(t, "golang")), ErrQueueClosed)
+}
+
+// ===== WorkerPool with a durable queue =====
+
+func TestWorkerPool_DurableQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.db")
+	log, _ := logger.New(logger.Config{Level: logger.ErrorLevel})
+
+	// The first pool is stopped before it picks up any task
+	queue := openTestQueue(t, path, time.Hour)
+	blocked := NewWorkerPool(WorkerPoolConfig{Workers: 1, Queue: queue, Logger: log})
+	require.NoError(t, blocked.Start())
+	require.NoError(t, blocked.Stop())
+	for _, keyword := range []string{"golang", "rust"} {
+		require.NoError(t, queue.Enqueue(context.Background(), newQueueTask(t, keyword)))
+	}
+	require.NoError(t, queue.Close())
+
+	// After a restart a new pool runs the queued tasks
+	queue = openTestQueue(t, path, time.Hour)
+	defer queue.Close()
+	pool := NewWorkerPool(WorkerPoolConfig{
+		Workers: 2,
+		Queue:   queue,
+		Logger:  log,
+		Executor: func(_ context.Context, task *Task) *TaskResult {
+			task.MarkCompleted()
+			return NewTaskResult(task, true, nil)
+		},
+	})
+	require.NoError(t, pool.Start())
+
+	keywords := make([]string, 0, 2)
+	for len(keywords) < 2 {
+		select {
+		case result := <-pool.GetResults():
+			keywords = append(keywords, result.Task.Keyword)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting for queued tasks")
+		}
+	}
+	require.NoError(t, pool.Stop())
+
+	assert.ElementsMatch(t, []string{"golang", "rust"}, keywords)
+	assert.Equal(t, 0, queue.Len(), "delivered tasks are acked")
+}
+
+func TestWorkerPoolConfig_LeaseTimeout(t *testing.T) {
+	// Four attempts of 10 pages, 15s each, plus the target page and the page
+	// capture, backoffs of 5s, 10s and 20s and the margin for delivering the
+	// result
+	config := WorkerPoolConfig{
+		MaxPages:      10,
+		SearchTimeout: 15 * time.Second,
+		MaxRetries:    3,
+		RetryDelay:    5 * time.Second,
+	}
+	assert.Equal(t, 4*(180*time.Second+captureTimeout)+35*time.Second+leaseMargin, config.LeaseTimeout())
+
+	// Defaults: one attempt of 5 pages, 15s each, plus the 30s target page
+	assert.Equal(t, 105*time.Second+captureTimeout+leaseMargin, WorkerPoolConfig{}.LeaseTimeout())
+}
+
+func TestWorkerPool_DurableQueueLongTask(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: logger.ErrorLevel})
+	config := WorkerPoolConfig{
+		Workers:       2,
+		Logger:        log,
+		MaxPages:      1,
+		SearchTimeout: 8 * time.Minute,
+		MaxRetries:    1,
+		RetryDelay:    time.Millisecond,
+	}
+	queue := openTestQueue(t, filepath.Join(t.TempDir(), "queue.db"), config.LeaseTimeout())
+	defer queue.Close()
+
+	// The queue clock jumps by the task deadline on every attempt, so the
+	// task runs longer than the default lease of 10 minutes
+	var clockMu sync.Mutex
+	now := time.Now()
+	queue.now = func() time.Time {
+		clockMu.Lock()
+		defer clockMu.Unlock()
+		return now
+	}
+
+	var runs atomic.Int32
+	config.Queue = queue
+	config.Executor = func(_ context.Context, task *Task) *TaskResult {
+		clockMu.Lock()
+		now = now.Add(8 * time.Minute)
+		clockMu.Unlock()
+
+		// Let the idle worker look for expired leases while the task runs
+		queue.notify()
+		time.Sleep(50 * time.Millisecond)
+
+		if runs.Add(1) == 1 {
+			return NewTaskResult(task, false, apperrors.NewTimeoutError("search timed out", context.DeadlineExceeded))
+		}
+		task.MarkCompleted()
+		return NewTaskResult(task, true, nil)
+	}
+	pool := NewWorkerPool(config)
+	require.NoError(t, pool.Start())
+
+	task := newQueueTask(t, "golang")
+	require.NoError(t, queue.Enqueue(context.Background(), task))
+
+	select {
+	case result := <-pool.GetResults():
+		assert.True(t, result.Success)
+		assert.Equal(t, 2, result.Attempts)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for the task")
+	}
+
+	// No second worker picked up the task while it was running
+	select {
+	case result := <-pool.GetResults():
+		t.Fatalf("task delivered twice, second result: %+v", result)
+	case <-time.After(200 * time.Millisecond):
+	}
+	require.NoError(t, pool.Stop())
+	assert.Equal(t, int32(2), runs.Load())
+}
+
+func TestScheduler_AdoptsUnfinishedTasks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.db")
+	log, _ := logger.New(logger.Config{Level: logger.ErrorLevel})
+
+	// The previous run left a check of a configured keyword and another task
+	unfinished, err := NewTask(TaskConfig{Keyword: "golang", TargetURL: "example.com", Type: TaskTypeSearch})
+	require.NoError(t, err)
+	queue := openTestQueue(t, path, time.Hour)
+	require.NoError(t, queue.Enqueue(context.Background(), unfinished))
+	require.NoError(t, queue.Enqueue(context.Background(), newQueueTask(t, "zig")))
+	require.NoError(t, queue.Close())
+
+	queue = openTestQueue(t, path, time.Hour)
+	defer queue.Close()
+	require.Len(t, queue.Unfinished(), 2)
+
+	var mu sync.Mutex
+	runs := make(map[string]int)
+	pool := NewWorkerPool(WorkerPoolConfig{
+		Workers: 2,
+		Queue:   queue,
+		Logger:  log,
+		Executor: func(_ context.Context, task *Task) *TaskResult {
+			mu.Lock()
+			runs[task.Keyword]++
+			mu.Unlock()
+			task.MarkCompleted()
+			return NewTaskResult(task, true, nil)
+		},
+	})
+
+	var results []*TaskResult
+	scheduler := NewScheduler(SchedulerConfig{
+		Config:     createTestConfig(),
+		WorkerPool: pool,
+		Logger:     log,
+		OnResult: func(result *TaskResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			results = append(results, result)
+		},
+	})
+	require.NoError(t, scheduler.Start(false))
+	select {
+	case <-scheduler.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for the cycle")
+	}
+	require.NoError(t, scheduler.Stop())
+	require.NoError(t, pool.Stop())
+
+	// The unfinished task stands in for the cycle's check of its keyword
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, runs["golang"])
+	assert.Equal(t, 1, runs["go programming"])
+	ids := make(map[string]string)
+	for _, result := range results {
+		ids[result.Task.Keyword] = result.Task.ID
+	}
+	assert.Equal(t, unfinished.ID, ids["golang"])
+}
@@ -9,16 +9,23 @@ import (
 
 	"github.com/omer/go-bot/internal/config"
 	"github.com/omer/go-bot/internal/logger"
+	"github.com/omer/go-bot/internal/metrics"
+	"github.com/omer/go-bot/internal/schedule"
+	"github.com/omer/go-bot/internal/serp"
 	"github.com/omer/go-bot/internal/stats"
+	"github.com/omer/go-bot/internal/urlmatch"
 )
 
-// Scheduler manages continuous task execution with configurable intervals
+// Scheduler manages continuous task execution. Every keyword runs on its own
+// schedule (see config.ScheduleConfig), by default every interval; a cycle
+// runs all keywords that are due.
 type Scheduler struct {
 	config         *config.Config
 	workerPool     *WorkerPool
 	statsCollector *stats.StatsCollector
 	logger         *logger.Logger
 	interval       time.Duration
+	taskType       TaskType
 	running        bool
 	mu             sync.RWMutex
 	ctx            context.Context
@@ -26,6 +33,33 @@ type Scheduler struct {
 	wg             sync.WaitGroup
 	cyclesRun      int
 	lastCycleAt    time.Time
+	metrics        *metrics.Metrics
+	onResult       func(*TaskResult)
+	onCycle        func(cycle int, err error)
+	done           chan struct{}
+	keywords       []scheduledKeyword
+	state          *schedule.State
+	unfinished     map[taskKey]*Task // Tasks of a previous run whose result is still to come
+}
+
+// taskKey identifies the check a task runs
+type taskKey struct {
+	taskType  TaskType
+	keyword   string
+	targetURL string
+	locale    serp.Locale
+}
+
+// keyOf returns the key of a task
+func keyOf(task *Task) taskKey {
+	return taskKey{taskType: task.Type, keyword: task.Keyword, targetURL: task.TargetURL, locale: task.Locale}
+}
+
+// scheduledKeyword is a configured keyword with its schedule
+type scheduledKeyword struct {
+	keyword config.Keyword
+	key     string // Key of the next run in the state
+	plan    *schedule.Plan
 }
 
 // SchedulerConfig holds configuration for creating a scheduler
@@ -35,6 +69,18 @@ type SchedulerConfig struct {
 	StatsCollector *stats.StatsCollector // Stats collector
 	Logger         *logger.Logger        // Logger instance
 	Interval       time.Duration         // Interval between cycles (0 = run once)
+	TaskType       TaskType              // Type of tasks to create (default: search)
+	Metrics        *metrics.Metrics      // Optional metrics for cycle durations
+	StatePath      string                // File persisting the next run of every keyword (empty = not persisted)
+
+	// OnResult is called for every result of a cycle. The scheduler is the
+	// only reader of the worker pool results, so callers that need results
+	// must use this hook. When nil, results are logged and recorded in the
+	// stats collector.
+	OnResult func(result *TaskResult)
+
+	// OnCycle is called after every cycle with its number and error, if any
+	OnCycle func(cycle int, err error)
 }
 
 // NewScheduler creates a new scheduler instance
@@ -46,30 +92,111 @@ type SchedulerConfig struct {
 //	    WorkerPool: pool,
 //	    Logger:     log,
 //	    Interval:   5 * time.Minute,
+//	    OnCycle: func(cycle int, err error) {
+//	        fmt.Printf("cycle %d finished\n", cycle)
+//	    },
 //	})
 func NewScheduler(config SchedulerConfig) *Scheduler {
 	if config.Interval == 0 {
 		config.Interval = 5 * time.Minute
 	}
+	if config.TaskType == "" {
+		config.TaskType = TaskTypeSearch
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &Scheduler{
+	s := &Scheduler{
 		config:         config.Config,
 		workerPool:     config.WorkerPool,
 		statsCollector: config.StatsCollector,
 		logger:         config.Logger,
 		interval:       config.Interval,
+		taskType:       config.TaskType,
 		running:        false,
 		ctx:            ctx,
 		cancel:         cancel,
 		cyclesRun:      0,
+		metrics:        config.Metrics,
+		onResult:       config.OnResult,
+		onCycle:        config.OnCycle,
+		done:           make(chan struct{}),
+	}
+	s.keywords = s.scheduleKeywords()
+	s.state = s.loadState(config.StatePath)
+
+	// Tasks a durable queue carried over from a previous run stand in for
+	// the same checks of the first cycle instead of running twice
+	s.unfinished = make(map[taskKey]*Task)
+	if s.workerPool != nil {
+		for _, task := range s.workerPool.unfinished() {
+			s.unfinished[keyOf(task)] = task
+		}
 	}
+	return s
+}
+
+// scheduleKeywords builds the schedule of every configured keyword.
+// Invalid schedules, which config validation rejects, fall back to the interval.
+func (s *Scheduler) scheduleKeywords() []scheduledKeyword {
+	keywords := make([]scheduledKeyword, 0, len(s.config.Keywords))
+	for _, kw := range s.config.Keywords {
+		sched := s.config.KeywordSchedule(kw)
+		plan, err := s.newPlan(sched)
+		if err != nil {
+			s.logger.Error("Invalid keyword schedule, using the interval", map[string]interface{}{
+				"error":   err,
+				"keyword": kw.Term,
+			})
+			plan = &schedule.Plan{Schedule: schedule.Every(s.interval)}
+		}
+
+		keywords = append(keywords, scheduledKeyword{
+			keyword: kw,
+			key:     stats.SeriesKey{Keyword: kw.Term, TargetURL: kw.TargetURL, Locale: kw.Locale().String()}.String(),
+			plan:    plan,
+		})
+	}
+	return keywords
+}
+
+// newPlan parses a schedule; keywords without a spec run every interval
+func (s *Scheduler) newPlan(sched config.ScheduleConfig) (*schedule.Plan, error) {
+	jitter := time.Duration(sched.Jitter) * time.Second
+	if sched.Spec != "" {
+		return schedule.NewPlan(sched.Spec, jitter, sched.QuietHours)
+	}
+
+	quiet, err := schedule.ParseQuietHours(sched.QuietHours)
+	if err != nil {
+		return nil, err
+	}
+	return &schedule.Plan{Schedule: schedule.Every(s.interval), Jitter: jitter, Quiet: quiet}, nil
+}
+
+// loadState loads the next runs persisted by a previous process.
+// Keywords without a stored next run are due immediately.
+func (s *Scheduler) loadState(path string) *schedule.State {
+	state, err := schedule.LoadState(path)
+	if err != nil {
+		s.logger.Warn("Failed to load schedule state, running all keywords", map[string]interface{}{
+			"error": err,
+		})
+		state, _ = schedule.LoadState("")
+		return state
+	}
+
+	keys := make([]string, len(s.keywords))
+	for i, kw := range s.keywords {
+		keys[i] = kw.key
+	}
+	state.Retain(keys)
+	return state
 }
 
 // Start starts the scheduler
-// If continuous is true, it runs in an infinite loop with interval delays
-// If continuous is false, it runs once and stops
+// If continuous is true, it runs in an infinite loop, running keywords as they become due
+// If continuous is false, it runs all keywords once and stops
 func (s *Scheduler) Start(continuous bool) error {
 	s.mu.Lock()
 	if s.running {
@@ -119,6 +246,12 @@ func (s *Scheduler) Stop() error {
 	return nil
 }
 
+// Done returns a channel that is closed when the scheduler loop exits,
+// i.e. after the cycle in single-cycle mode or after Stop
+func (s *Scheduler) Done() <-chan struct{} {
+	return s.done
+}
+
 // IsRunning returns whether the scheduler is currently running
 func (s *Scheduler) IsRunning() bool {
 	s.mu.RLock()
@@ -136,12 +269,14 @@ func (s *Scheduler) Stats() map[string]interface{} {
 		"cycles_run": s.cyclesRun,
 		"last_cycle": s.lastCycleAt,
 		"interval":   s.interval,
+		"next_run":   s.nextRun(),
 	}
 }
 
 // run is the main scheduler loop
 func (s *Scheduler) run(continuous bool) {
 	defer s.wg.Done()
+	defer close(s.done)
 
 	for {
 		// Check if context is cancelled
@@ -152,26 +287,13 @@ func (s *Scheduler) run(continuous bool) {
 		default:
 		}
 
-		// Run one cycle
-		s.logger.Info("Starting scheduler cycle", map[string]interface{}{
-			"cycle": s.cyclesRun + 1,
-		})
-
-		err := s.runCycle()
-		if err != nil {
-			s.logger.Error("Scheduler cycle failed", map[string]interface{}{
-				"error": err,
-				"cycle": s.cyclesRun + 1,
-			})
-		} else {
-			s.mu.Lock()
-			s.cyclesRun++
-			s.lastCycleAt = time.Now()
-			s.mu.Unlock()
-
-			s.logger.Info("Scheduler cycle completed", map[string]interface{}{
-				"cycle": s.cyclesRun,
-			})
+		// Run one cycle with the due keywords, or all of them in single-cycle mode
+		due := s.keywords
+		if continuous {
+			due = s.dueKeywords(time.Now())
+		}
+		if len(due) > 0 {
+			s.cycle(due)
 		}
 
 		// If not continuous, stop after one cycle
@@ -180,49 +302,135 @@ func (s *Scheduler) run(continuous bool) {
 			return
 		}
 
-		// Wait for interval before next cycle
+		// Wait for the next keyword to become due
+		next := s.nextRun()
 		s.logger.Info("Waiting before next cycle", map[string]interface{}{
-			"interval": s.interval,
+			"next_run": next,
+			"wait":     time.Until(next).Round(time.Second),
 		})
 
-		select {
-		case <-s.ctx.Done():
+		if !s.wait(time.Until(next)) {
 			s.logger.Info("Scheduler loop stopping (context cancelled during wait)", nil)
 			return
-		case <-time.After(s.interval):
-			// Continue to next cycle
 		}
 	}
 }
 
-// runCycle executes one full cycle of tasks
-func (s *Scheduler) runCycle() error {
-	// Start worker pool if not running
-	if !s.workerPool.IsRunning() {
-		err := s.workerPool.Start()
-		if err != nil {
-			return fmt.Errorf("failed to start worker pool: %w", err)
+// wait waits between cycles and returns false when the scheduler is stopped.
+// Results that arrive meanwhile, e.g. of tasks a durable queue recovered from
+// a previous run, are handled so workers are never blocked on them.
+func (s *Scheduler) wait(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	results := s.workerPool.GetResults()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return false
+		case <-timer.C:
+			return true
+		case result, ok := <-results:
+			if !ok {
+				results = nil
+				continue
+			}
+			s.handleResult(result)
 		}
 	}
+}
 
-	// Create tasks for all keywords
-	tasks := make([]*Task, 0, len(s.config.Keywords))
-	for _, kw := range s.config.Keywords {
-		task, err := NewTask(TaskConfig{
-			Keyword:   kw.Term,
-			TargetURL: kw.TargetURL,
+// cycle runs one cycle with the given keywords and schedules their next runs
+func (s *Scheduler) cycle(keywords []scheduledKeyword) {
+	cycle := s.cyclesRun + 1
+	s.logger.Info("Starting scheduler cycle", map[string]interface{}{
+		"cycle":    cycle,
+		"keywords": len(keywords),
+	})
+
+	cycleStart := time.Now()
+	err := s.runCycle(keywords)
+	s.metrics.ObserveCycle(err, time.Since(cycleStart))
+	if err != nil {
+		s.logger.Error("Scheduler cycle failed", map[string]interface{}{
+			"error": err,
+			"cycle": cycle,
+		})
+	} else {
+		s.mu.Lock()
+		s.cyclesRun++
+		s.lastCycleAt = time.Now()
+		s.mu.Unlock()
+
+		s.logger.Info("Scheduler cycle completed", map[string]interface{}{
+			"cycle": cycle,
+		})
+	}
+
+	// Keywords of an interrupted cycle stay due, so they run after a restart
+	if s.ctx.Err() == nil {
+		s.advance(keywords, time.Now())
+	}
+
+	if s.onCycle != nil {
+		s.onCycle(cycle, err)
+	}
+}
+
+// dueKeywords returns the keywords whose next run is not after now
+func (s *Scheduler) dueKeywords(now time.Time) []scheduledKeyword {
+	var due []scheduledKeyword
+	for _, kw := range s.keywords {
+		if next, ok := s.state.Next(kw.key); !ok || !next.After(now) {
+			due = append(due, kw)
+		}
+	}
+	return due
+}
+
+// advance schedules the next run of keywords that ran and persists the state
+func (s *Scheduler) advance(keywords []scheduledKeyword, now time.Time) {
+	for _, kw := range keywords {
+		s.state.Set(kw.key, kw.plan.Next(now))
+	}
+	if err := s.state.Save(); err != nil {
+		s.logger.Error("Failed to save schedule state", map[string]interface{}{
+			"error": err,
 		})
+	}
+}
+
+// nextRun returns the earliest next run of all keywords
+func (s *Scheduler) nextRun() time.Time {
+	var next time.Time
+	for _, kw := range s.keywords {
+		run, ok := s.state.Next(kw.key)
+		if !ok {
+			return time.Now()
+		}
+		if next.IsZero() || run.Before(next) {
+			next = run
+		}
+	}
+	if next.IsZero() {
+		// No keywords; idle for one interval
+		next = time.Now().Add(s.interval)
+	}
+	return next
+}
+
+// runCycle executes one cycle of tasks for the given keywords
+func (s *Scheduler) runCycle(keywords []scheduledKeyword) error {
+	// Start worker pool if not running
+	if !s.workerPool.IsRunning() {
+		err := s.workerPool.Start()
 		if err != nil {
-			s.logger.Error("Failed to create task", map[string]interface{}{
-				"error":   err,
-				"keyword": kw.Term,
-			})
-			continue
+			return fmt.Errorf("failed to start worker pool: %w", err)
 		}
-		tasks = append(tasks, task)
 	}
 
-	if len(tasks) == 0 {
+	tasks, adopted := s.newTasks(keywords)
+	if len(tasks) == 0 && len(adopted) == 0 {
 		return fmt.Errorf("no tasks created")
 	}
 
@@ -231,6 +439,14 @@ func (s *Scheduler) runCycle() error {
 		"count": len(tasks),
 	})
 
+	pending := make(map[string]bool, len(tasks)+len(adopted))
+	for _, task := range adopted {
+		s.logger.Info("Waiting for unfinished task of the previous run", map[string]interface{}{
+			"task_id": task.ID,
+			"keyword": task.Keyword,
+		})
+		pending[task.ID] = true
+	}
 	for _, task := range tasks {
 		err := s.workerPool.Submit(task)
 		if err != nil {
@@ -238,56 +454,97 @@ func (s *Scheduler) runCycle() error {
 				"error":   err,
 				"task_id": task.ID,
 			})
+			continue
 		}
+		pending[task.ID] = true
+	}
+
+	if len(pending) == 0 {
+		return fmt.Errorf("no tasks submitted")
 	}
 
-	// Collect results
+	// Collect one result per submitted task. Results of other tasks, such as
+	// tasks a durable queue recovered from a previous run, are handled too
+	// but do not complete the cycle.
 	resultsCollected := 0
-	for resultsCollected < len(tasks) {
+	for len(pending) > 0 {
 		select {
 		case <-s.ctx.Done():
 			return fmt.Errorf("context cancelled while collecting results")
-		case result := <-s.workerPool.GetResults():
+		case result, ok := <-s.workerPool.GetResults():
+			if !ok {
+				return fmt.Errorf("worker pool stopped while collecting results")
+			}
 			resultsCollected++
-
-			s.logger.Info("Task completed", map[string]interface{}{
-				"task_id":  result.Task.ID,
-				"keyword":  result.Task.Keyword,
-				"success":  result.Success,
-				"duration": result.Duration,
-				"position": result.Position,
-			})
-
-			// Record stats if collector is available
-			if s.statsCollector != nil {
-				taskStats := stats.TaskStats{
-					TaskID:     result.Task.ID,
-					Keyword:    result.Task.Keyword,
-					TargetURL:  result.Task.TargetURL,
-					Success:    result.Success,
-					Position:   result.Position,
-					PageNumber: result.PageNumber,
-					Duration:   float64(result.Duration.Milliseconds()),
-					Timestamp:  time.Now(),
-				}
-
-				if result.Error != nil {
-					taskStats.Error = result.Error.Error()
-				}
-
-				s.statsCollector.RecordTask(taskStats)
+			delete(pending, result.Task.ID)
+			if carried, ok := s.unfinished[keyOf(result.Task)]; ok && carried.ID == result.Task.ID {
+				delete(s.unfinished, keyOf(result.Task))
 			}
+			s.handleResult(result)
 		}
 	}
 
 	s.logger.Info("All tasks completed", map[string]interface{}{
-		"total":     len(tasks),
+		"total":     len(tasks) + len(adopted),
 		"collected": resultsCollected,
 	})
 
 	return nil
 }
 
+// newTasks creates the tasks of a cycle. Checks that an unfinished task of
+// the previous run still covers are returned as adopted instead.
+func (s *Scheduler) newTasks(keywords []scheduledKeyword) (tasks, adopted []*Task) {
+	tasks = make([]*Task, 0, len(keywords))
+	for _, scheduled := range keywords {
+		kw := scheduled.keyword
+		task, err := NewTask(TaskConfig{
+			Keyword:     kw.Term,
+			TargetURL:   kw.TargetURL,
+			Match:       urlmatch.Mode(kw.Match),
+			Competitors: kw.Competitors,
+			Locale:      kw.Locale(),
+			Type:        s.taskType,
+		})
+		if err != nil {
+			s.logger.Error("Failed to create task", map[string]interface{}{
+				"error":   err,
+				"keyword": kw.Term,
+			})
+			continue
+		}
+		if carried, ok := s.unfinished[keyOf(task)]; ok {
+			delete(s.unfinished, keyOf(task))
+			adopted = append(adopted, carried)
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, adopted
+}
+
+// handleResult passes a result to the OnResult hook, or logs it and records
+// it in the stats collector when there is no hook
+func (s *Scheduler) handleResult(result *TaskResult) {
+	if s.onResult != nil {
+		s.onResult(result)
+		return
+	}
+
+	s.logger.Info("Task completed", map[string]interface{}{
+		"task_id":  result.Task.ID,
+		"keyword":  result.Task.Keyword,
+		"success":  result.Success,
+		"duration": result.Duration,
+		"position": result.Position,
+	})
+
+	// Record stats if collector is available
+	if s.statsCollector != nil {
+		s.statsCollector.RecordTask(result.ToStats())
+	}
+}
+
 // RetryWithBackoff executes a function with exponential backoff retry logic
 func RetryWithBackoff(ctx context.Context, maxRetries int, initialDelay time.Duration, fn func() error) error {
 	var lastErr error
@@ -313,17 +570,8 @@ func RetryWithBackoff(ctx context.Context, maxRetries int, initialDelay time.Dur
 			break
 		}
 
-		// Calculate backoff delay: initialDelay * 2^attempt
-		backoff := time.Duration(float64(initialDelay) * math.Pow(2, float64(attempt)))
-
-		// Cap backoff at 5 minutes
-		maxBackoff := 5 * time.Minute
-		if backoff > maxBackoff {
-			backoff = maxBackoff
-		}
-
 		// Wait before retry
-		timer := time.NewTimer(backoff)
+		timer := time.NewTimer(backoffDelay(initialDelay, attempt))
 		select {
 		case <-timer.C:
 			// Continue to next attempt
@@ -336,6 +584,18 @@ func RetryWithBackoff(ctx context.Context, maxRetries int, initialDelay time.Dur
 	return fmt.Errorf("operation failed after %d attempts: %w", maxRetries, lastErr)
 }
 
+// backoffDelay returns the delay before retry attempt+1:
+// initialDelay * 2^attempt, capped at 5 minutes
+func backoffDelay(initialDelay time.Duration, attempt int) time.Duration {
+	backoff := time.Duration(float64(initialDelay) * math.Pow(2, float64(attempt)))
+
+	maxBackoff := 5 * time.Minute
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
 // RunWithPanicRecovery wraps a function with panic recovery
 func RunWithPanicRecovery(fn func(), logger *logger.Logger) {
 	defer func() {
@@ -3,11 +3,14 @@ package task
 import (
 	"context"
 	"errors"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/omer/go-bot/internal/config"
 	"github.com/omer/go-bot/internal/logger"
+	"github.com/omer/go-bot/internal/serp"
 	"github.com/omer/go-bot/internal/stats"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -27,8 +30,8 @@ func createTestConfig() *config.Config {
 		MaxRetries:    3,
 		RetryDelay:    5,
 		Selectors: config.SelectorConfig{
-			SearchBox:  "input[name='q']",
-			ResultItem: "div.result",
+			SearchBox:  serp.SelectorList{"input[name='q']"},
+			ResultItem: serp.SelectorList{"div.result"},
 		},
 	}
 }
@@ -80,7 +83,7 @@ func TestScheduler_StartStop(t *testing.T) {
 	log, _ := logger.New(logger.Config{Level: logger.ErrorLevel})
 
 	// Mock executor that completes immediately
-	mockExecutor := func(task *Task) *TaskResult {
+	mockExecutor := func(_ context.Context, task *Task) *TaskResult {
 		task.MarkRunning()
 		time.Sleep(10 * time.Millisecond)
 		task.MarkCompleted()
@@ -122,7 +125,7 @@ func TestScheduler_StartAlreadyRunning(t *testing.T) {
 	pool := NewWorkerPool(WorkerPoolConfig{
 		Workers: 2,
 		Logger:  log,
-		Executor: func(task *Task) *TaskResult {
+		Executor: func(_ context.Context, task *Task) *TaskResult {
 			time.Sleep(100 * time.Millisecond)
 			task.MarkCompleted()
 			return NewTaskResult(task, true, nil)
@@ -173,7 +176,7 @@ func TestScheduler_SingleCycle(t *testing.T) {
 	log, _ := logger.New(logger.Config{Level: logger.ErrorLevel})
 
 	tasksExecuted := 0
-	mockExecutor := func(task *Task) *TaskResult {
+	mockExecutor := func(_ context.Context, task *Task) *TaskResult {
 		tasksExecuted++
 		task.MarkRunning()
 		task.MarkCompleted()
@@ -213,7 +216,7 @@ func TestScheduler_ContinuousMode(t *testing.T) {
 	cfg := createTestConfig()
 	log, _ := logger.New(logger.Config{Level: logger.ErrorLevel})
 
-	mockExecutor := func(task *Task) *TaskResult {
+	mockExecutor := func(_ context.Context, task *Task) *TaskResult {
 		task.MarkRunning()
 		task.MarkCompleted()
 		return NewTaskResult(task, true, nil)
@@ -256,7 +259,7 @@ func TestScheduler_WithStatsCollector(t *testing.T) {
 
 	statsCollector := stats.NewStatsCollector("test_stats.json")
 
-	mockExecutor := func(task *Task) *TaskResult {
+	mockExecutor := func(_ context.Context, task *Task) *TaskResult {
 		task.MarkRunning()
 		time.Sleep(10 * time.Millisecond)
 		task.MarkCompleted()
@@ -298,7 +301,7 @@ func TestScheduler_TaskFailure(t *testing.T) {
 	cfg := createTestConfig()
 	log, _ := logger.New(logger.Config{Level: logger.ErrorLevel})
 
-	mockExecutor := func(task *Task) *TaskResult {
+	mockExecutor := func(_ context.Context, task *Task) *TaskResult {
 		task.MarkRunning()
 		task.MarkFailed()
 		return NewTaskResult(task, false, errors.New("simulated failure"))
@@ -335,7 +338,7 @@ func TestScheduler_ContextCancellation(t *testing.T) {
 	cfg := createTestConfig()
 	log, _ := logger.New(logger.Config{Level: logger.ErrorLevel})
 
-	mockExecutor := func(task *Task) *TaskResult {
+	mockExecutor := func(_ context.Context, task *Task) *TaskResult {
 		time.Sleep(50 * time.Millisecond)
 		task.MarkCompleted()
 		return NewTaskResult(task, true, nil)
@@ -367,6 +370,172 @@ func TestScheduler_ContextCancellation(t *testing.T) {
 	assert.False(t, scheduler.IsRunning())
 }
 
+func TestScheduler_Hooks(t *testing.T) {
+	cfg := createTestConfig()
+	log, _ := logger.New(logger.Config{Level: logger.ErrorLevel})
+	statsCollector := stats.NewStatsCollector("test_stats.json")
+
+	pool := NewWorkerPool(WorkerPoolConfig{
+		Workers:   2,
+		QueueSize: 10,
+		Logger:    log,
+		Executor: func(_ context.Context, task *Task) *TaskResult {
+			task.MarkCompleted()
+			return NewTaskResult(task, true, nil)
+		},
+	})
+
+	var mu sync.Mutex
+	var keywords []string
+	var cycles []int
+	scheduler := NewScheduler(SchedulerConfig{
+		Config:         cfg,
+		WorkerPool:     pool,
+		StatsCollector: statsCollector,
+		Logger:         log,
+		Interval:       50 * time.Millisecond,
+		OnResult: func(result *TaskResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			keywords = append(keywords, result.Task.Keyword)
+		},
+		OnCycle: func(cycle int, err error) {
+			assert.NoError(t, err)
+			mu.Lock()
+			defer mu.Unlock()
+			cycles = append(cycles, cycle)
+		},
+	})
+
+	require.NoError(t, scheduler.Start(true))
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(cycles) >= 2
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, scheduler.Stop())
+	pool.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []int{1, 2}, cycles[:2])
+	assert.ElementsMatch(t, []string{"golang", "go programming"}, keywords[:2])
+	assert.Equal(t, 0, statsCollector.GetSummary()["total_tasks"], "OnResult replaces the built-in recording")
+}
+
+func TestScheduler_Done(t *testing.T) {
+	cfg := createTestConfig()
+	log, _ := logger.New(logger.Config{Level: logger.ErrorLevel})
+
+	pool := NewWorkerPool(WorkerPoolConfig{
+		Workers: 2,
+		Logger:  log,
+		Executor: func(_ context.Context, task *Task) *TaskResult {
+			task.MarkCompleted()
+			return NewTaskResult(task, true, nil)
+		},
+	})
+
+	scheduler := NewScheduler(SchedulerConfig{Config: cfg, WorkerPool: pool, Logger: log})
+	require.NoError(t, scheduler.Start(false))
+
+	select {
+	case <-scheduler.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("single cycle did not finish")
+	}
+	assert.Equal(t, 1, scheduler.Stats()["cycles_run"])
+
+	require.NoError(t, scheduler.Stop())
+	pool.Stop()
+}
+
+func TestScheduler_KeywordSchedules(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Keywords[0].Schedule = "every 1h"
+	log, _ := logger.New(logger.Config{Level: logger.ErrorLevel})
+
+	var mu sync.Mutex
+	runs := make(map[string]int)
+	pool := NewWorkerPool(WorkerPoolConfig{
+		Workers:   2,
+		QueueSize: 10,
+		Logger:    log,
+		Executor: func(_ context.Context, task *Task) *TaskResult {
+			mu.Lock()
+			runs[task.Keyword]++
+			mu.Unlock()
+			task.MarkCompleted()
+			return NewTaskResult(task, true, nil)
+		},
+	})
+
+	scheduler := NewScheduler(SchedulerConfig{
+		Config:     cfg,
+		WorkerPool: pool,
+		Logger:     log,
+		Interval:   50 * time.Millisecond,
+	})
+
+	require.NoError(t, scheduler.Start(true))
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return runs["go programming"] >= 3
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, scheduler.Stop())
+	pool.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, runs["golang"], "the hourly keyword runs once")
+}
+
+func TestScheduler_StatePersisted(t *testing.T) {
+	cfg := createTestConfig()
+	log, _ := logger.New(logger.Config{Level: logger.ErrorLevel})
+	statePath := filepath.Join(t.TempDir(), "schedule.json")
+
+	var mu sync.Mutex
+	executed := 0
+	newPool := func() *WorkerPool {
+		return NewWorkerPool(WorkerPoolConfig{
+			Workers: 2,
+			Logger:  log,
+			Executor: func(_ context.Context, task *Task) *TaskResult {
+				mu.Lock()
+				executed++
+				mu.Unlock()
+				task.MarkCompleted()
+				return NewTaskResult(task, true, nil)
+			},
+		})
+	}
+
+	// The first process runs every keyword and stores their next runs
+	pool := newPool()
+	first := NewScheduler(SchedulerConfig{Config: cfg, WorkerPool: pool, Logger: log, Interval: time.Hour, StatePath: statePath})
+	require.NoError(t, first.Start(false))
+	<-first.Done()
+	require.NoError(t, first.Stop())
+	pool.Stop()
+	require.FileExists(t, statePath)
+
+	// After a restart nothing is due before the stored next runs
+	pool = newPool()
+	second := NewScheduler(SchedulerConfig{Config: cfg, WorkerPool: pool, Logger: log, Interval: time.Hour, StatePath: statePath})
+	require.NoError(t, second.Start(true))
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, second.Stop())
+	pool.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, executed)
+	assert.Equal(t, 0, second.Stats()["cycles_run"])
+	assert.True(t, second.Stats()["next_run"].(time.Time).After(time.Now().Add(50*time.Minute)))
+}
+
 func TestRetryWithBackoff_Success(t *testing.T) {
 	ctx := context.Background()
 	attempts := 0
@@ -543,7 +712,7 @@ func TestScheduler_MultipleStarts(t *testing.T) {
 		Workers:   2,
 		QueueSize: 10,
 		Logger:    log,
-		Executor: func(task *Task) *TaskResult {
+		Executor: func(_ context.Context, task *Task) *TaskResult {
 			time.Sleep(10 * time.Millisecond)
 			task.MarkCompleted()
 			return NewTaskResult(task, true, nil)
@@ -578,7 +747,7 @@ func TestScheduler_LongInterval(t *testing.T) {
 		Workers:   2,
 		QueueSize: 10,
 		Logger:    log,
-		Executor: func(task *Task) *TaskResult {
+		Executor: func(_ context.Context, task *Task) *TaskResult {
 			task.MarkCompleted()
 			return NewTaskResult(task, true, nil)
 		},
@@ -613,7 +782,7 @@ func TestScheduler_WorkerPoolNotRunning(t *testing.T) {
 		Workers:   2,
 		QueueSize: 10,
 		Logger:    log,
-		Executor: func(task *Task) *TaskResult {
+		Executor: func(_ context.Context, task *Task) *TaskResult {
 			tasksExecuted++
 			task.MarkCompleted()
 			return NewTaskResult(task, true, nil)
@@ -665,7 +834,7 @@ func TestScheduler_ResultCollection(t *testing.T) {
 		Workers:   2,
 		QueueSize: 10,
 		Logger:    log,
-		Executor: func(task *Task) *TaskResult {
+		Executor: func(_ context.Context, task *Task) *TaskResult {
 			task.MarkRunning()
 			task.MarkCompleted()
 			result := NewTaskResult(task, true, nil)
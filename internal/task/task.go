@@ -5,6 +5,10 @@ package task
 import (
 	"fmt"
 	"time"
+
+	"github.com/omer/go-bot/internal/serp"
+	"github.com/omer/go-bot/internal/stats"
+	"github.com/omer/go-bot/internal/urlmatch"
 )
 
 // TaskType represents the type of task to execute
@@ -15,6 +19,8 @@ const (
 	TaskTypeSearch TaskType = "search"
 	// TaskTypeClick represents a click task
 	TaskTypeClick TaskType = "click"
+	// TaskTypeRankCheck represents a read-only rank lookup that never clicks results
+	TaskTypeRankCheck TaskType = "rank_check"
 )
 
 // TaskStatus represents the current status of a task
@@ -37,6 +43,9 @@ type Task struct {
 	Type        TaskType               // Type of task (search, click, etc.)
 	Keyword     string                 // Search keyword
 	TargetURL   string                 // Target URL to find and click
+	Match       urlmatch.Mode          // How TargetURL is matched against results
+	Competitors []string               // Competitor domains ranked from the same results
+	Locale      serp.Locale            // Language, country, domain and device of the search
 	ProxyURL    string                 // Proxy URL to use (optional)
 	Status      TaskStatus             // Current task status
 	CreatedAt   time.Time              // Task creation time
@@ -47,21 +56,59 @@ type Task struct {
 
 // TaskResult represents the result of an executed task
 type TaskResult struct {
-	Task       *Task         // Reference to the original task
-	Success    bool          // Whether the task succeeded
-	Error      error         // Error if task failed
-	Position   int           // Position where target was found (0 if not found)
-	PageNumber int           // Page number where target was found
-	Duration   time.Duration // Task execution duration
-	Message    string        // Additional message or details
+	Task            *Task              // Reference to the original task
+	Success         bool               // Whether the task succeeded
+	Error           error              // Error if task failed
+	Position        int                // Absolute organic position across result pages (0 if not found)
+	VisualPosition  int                // Absolute position counting ads and SERP features (0 if not found)
+	PageNumber      int                // Page number where target was found
+	Outcome         string             // Rank scan outcome: found or not_in_top (empty if no scan ran)
+	Depth           int                // Number of organic results scanned before the scan ended
+	Features        []string           // SERP features (ads, featured snippets, ...) that showed the target
+	Competitors     []CompetitorResult // Competitor ranks from the same results
+	Duration        time.Duration      // Task execution duration
+	Attempts        int                // Number of times the task was executed, including retries
+	History         []Attempt          // Every execution of the task, in order
+	Page            *PageState         // Page the browser was on when the task failed (nil if unknown)
+	SelectorProfile string             // Selector profile of the browser search, e.g. "google@2" (empty without a browser)
+	Fallbacks       []serp.Fallback    // Page elements found only with a fallback selector
+	Message         string             // Additional message or details
+}
+
+// Attempt records one execution of a task
+type Attempt struct {
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	Error     string        `json:"error,omitempty"`      // Empty if the attempt succeeded
+	ErrorType string        `json:"error_type,omitempty"` // Error category, e.g. timeout, captcha or selector
+}
+
+// PageState describes the browser page a task ended on
+type PageState struct {
+	URL       string `json:"url,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Artifacts string `json:"artifacts,omitempty"` // Directory with the saved debug artifacts (empty if none)
+}
+
+// CompetitorResult holds the rank of a competitor domain found by the same task
+type CompetitorResult struct {
+	TargetURL      string // Competitor domain as configured
+	URL            string // Matching result URL (empty if not found)
+	Position       int    // Absolute organic position (0 if not found)
+	VisualPosition int    // Absolute position counting ads and SERP features (0 if not found)
+	PageNumber     int    // Page the competitor was found on (0 if not found)
 }
 
 // TaskConfig holds configuration for creating a new task
 type TaskConfig struct {
-	Keyword   string                 // Required: Search keyword
-	TargetURL string                 // Required: Target URL
-	ProxyURL  string                 // Optional: Proxy URL
-	Metadata  map[string]interface{} // Optional: Additional metadata
+	Keyword     string                 // Required: Search keyword
+	TargetURL   string                 // Required: Target URL
+	Match       urlmatch.Mode          // Optional: URL match mode (default: urlmatch.DefaultMode)
+	Competitors []string               // Optional: Competitor domains to rank alongside the target
+	Locale      serp.Locale            // Optional: Search context (default: google.com, desktop)
+	Type        TaskType               // Optional: Task type (default: search)
+	ProxyURL    string                 // Optional: Proxy URL
+	Metadata    map[string]interface{} // Optional: Additional metadata
 }
 
 // NewTask creates a new task with the given configuration
@@ -81,18 +128,43 @@ func NewTask(config TaskConfig) (*Task, error) {
 		return nil, fmt.Errorf("target URL is required")
 	}
 
+	// Default to a search task
+	switch config.Type {
+	case "":
+		config.Type = TaskTypeSearch
+	case TaskTypeSearch, TaskTypeClick, TaskTypeRankCheck:
+	default:
+		return nil, fmt.Errorf("unknown task type: %s", config.Type)
+	}
+
+	if _, err := urlmatch.New(config.Match, config.TargetURL); err != nil {
+		return nil, fmt.Errorf("invalid target URL: %w", err)
+	}
+	for _, competitor := range config.Competitors {
+		if _, err := urlmatch.New("", competitor); err != nil {
+			return nil, fmt.Errorf("invalid competitor %q: %w", competitor, err)
+		}
+	}
+
+	if err := config.Locale.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid locale: %w", err)
+	}
+
 	// Generate task ID
 	taskID := generateTaskID()
 
 	task := &Task{
-		ID:        taskID,
-		Type:      TaskTypeSearch,
-		Keyword:   config.Keyword,
-		TargetURL: config.TargetURL,
-		ProxyURL:  config.ProxyURL,
-		Status:    TaskStatusPending,
-		CreatedAt: time.Now(),
-		Metadata:  config.Metadata,
+		ID:          taskID,
+		Type:        config.Type,
+		Keyword:     config.Keyword,
+		TargetURL:   config.TargetURL,
+		Match:       config.Match,
+		Competitors: config.Competitors,
+		Locale:      config.Locale,
+		ProxyURL:    config.ProxyURL,
+		Status:      TaskStatusPending,
+		CreatedAt:   time.Now(),
+		Metadata:    config.Metadata,
 	}
 
 	return task, nil
@@ -148,6 +220,44 @@ func NewTaskResult(task *Task, success bool, err error) *TaskResult {
 	}
 }
 
+// ToStats converts the result into the record kept by the statistics collector
+func (r *TaskResult) ToStats() stats.TaskStats {
+	taskStats := stats.TaskStats{
+		TaskID:         r.Task.ID,
+		Keyword:        r.Task.Keyword,
+		TargetURL:      r.Task.TargetURL,
+		Locale:         r.Task.Locale.String(),
+		Success:        r.Success,
+		Position:       r.Position,
+		VisualPosition: r.VisualPosition,
+		PageNumber:     r.PageNumber,
+		Outcome:        r.Outcome,
+		Depth:          r.Depth,
+		Features:       r.Features,
+		Duration:       float64(r.Duration.Milliseconds()),
+		ProxyUsed:      r.Task.ProxyURL,
+		Attempts:       r.Attempts,
+		Selectors:      r.SelectorProfile,
+		Timestamp:      time.Now(),
+	}
+	for _, fallback := range r.Fallbacks {
+		taskStats.Fallbacks = append(taskStats.Fallbacks, fallback.String())
+	}
+	if r.Error != nil {
+		taskStats.Error = r.Error.Error()
+	}
+	for _, competitor := range r.Competitors {
+		taskStats.Competitors = append(taskStats.Competitors, stats.CompetitorStats{
+			TargetURL:      competitor.TargetURL,
+			URL:            competitor.URL,
+			Position:       competitor.Position,
+			VisualPosition: competitor.VisualPosition,
+			PageNumber:     competitor.PageNumber,
+		})
+	}
+	return taskStats
+}
+
 // generateTaskID generates a unique task ID
 func generateTaskID() string {
 	// Simple ID generation based on timestamp
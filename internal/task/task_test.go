@@ -1,12 +1,22 @@
 package task
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/omer/go-bot/internal/artifact"
+	"github.com/omer/go-bot/internal/browser"
+	"github.com/omer/go-bot/internal/browser/browsertest"
+	apperrors "github.com/omer/go-bot/internal/errors"
 	"github.com/omer/go-bot/internal/logger"
 	"github.com/omer/go-bot/internal/proxy"
+	"github.com/omer/go-bot/internal/serp"
+	"github.com/omer/go-bot/internal/urlmatch"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -73,6 +83,64 @@ func TestNewTask_MissingTargetURL(t *testing.T) {
 	assert.Contains(t, err.Error(), "target URL is required")
 }
 
+func TestNewTask_RankCheckType(t *testing.T) {
+	task, err := NewTask(TaskConfig{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+		Type:      TaskTypeRankCheck,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, TaskTypeRankCheck, task.Type)
+}
+
+func TestNewTask_MatchMode(t *testing.T) {
+	task, err := NewTask(TaskConfig{
+		Keyword:   "golang",
+		TargetURL: "*.example.com",
+		Match:     urlmatch.ModeSubdomain,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, urlmatch.ModeSubdomain, task.Match)
+
+	_, err = NewTask(TaskConfig{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+		Match:     "contains",
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid target URL")
+}
+
+func TestNewTask_Locale(t *testing.T) {
+	locale := serp.Locale{HL: "tr", GL: "TR", Domain: "google.com.tr", Device: "mobile"}
+	task, err := NewTask(TaskConfig{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+		Locale:    locale,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, locale, task.Locale)
+
+	_, err = NewTask(TaskConfig{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+		Locale:    serp.Locale{Domain: "bing.com"},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid locale")
+}
+
+func TestNewTask_UnknownType(t *testing.T) {
+	task, err := NewTask(TaskConfig{
+		Keyword:   "golang",
+		TargetURL: "example.com",
+		Type:      TaskType("scrape"),
+	})
+	assert.Error(t, err)
+	assert.Nil(t, task)
+	assert.Contains(t, err.Error(), "unknown task type")
+}
+
 // ===== Task state management tests =====
 
 func TestTask_MarkRunning(t *testing.T) {
@@ -224,8 +292,12 @@ func TestNewWorkerPool_Defaults(t *testing.T) {
 
 	assert.NotNil(t, pool)
 	assert.Equal(t, 1, pool.workers)
-	assert.NotNil(t, pool.taskQueue)
+	assert.NotNil(t, pool.queue)
 	assert.NotNil(t, pool.resultQueue)
+	assert.Equal(t, 5, pool.maxPages)
+	assert.Equal(t, 30*time.Second, pool.pageTimeout)
+	assert.Equal(t, 15*time.Second, pool.searchTimeout)
+	assert.Equal(t, serp.DefaultProfile(), pool.selectors)
 	assert.False(t, pool.IsRunning())
 }
 
@@ -243,7 +315,7 @@ func TestNewWorkerPool_CustomConfig(t *testing.T) {
 	})
 
 	assert.Equal(t, 5, pool.workers)
-	assert.Equal(t, 100, cap(pool.taskQueue))
+	assert.Equal(t, 100, pool.queue.(*MemoryQueue).Cap())
 	assert.False(t, pool.IsRunning())
 }
 
@@ -331,7 +403,7 @@ func TestWorkerPool_ExecuteTask(t *testing.T) {
 	require.NoError(t, err)
 
 	// Create a mock executor
-	mockExecutor := func(task *Task) *TaskResult {
+	mockExecutor := func(_ context.Context, task *Task) *TaskResult {
 		task.MarkRunning()
 		time.Sleep(10 * time.Millisecond)
 		task.MarkCompleted()
@@ -380,7 +452,7 @@ func TestWorkerPool_MultipleTasks(t *testing.T) {
 
 	// Create a mock executor
 	executedTasks := 0
-	mockExecutor := func(task *Task) *TaskResult {
+	mockExecutor := func(_ context.Context, task *Task) *TaskResult {
 		task.MarkRunning()
 		time.Sleep(5 * time.Millisecond)
 		task.MarkCompleted()
@@ -437,7 +509,7 @@ func TestWorkerPool_Stats(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	mockExecutor := func(task *Task) *TaskResult {
+	mockExecutor := func(_ context.Context, task *Task) *TaskResult {
 		task.MarkRunning()
 		task.MarkCompleted()
 		return NewTaskResult(task, true, nil)
@@ -479,7 +551,7 @@ func TestWorkerPool_WithProxyPool(t *testing.T) {
 	proxyPool, err := proxy.NewProxyPool(proxyURLs, proxy.RotationStrategyRoundRobin)
 	require.NoError(t, err)
 
-	mockExecutor := func(task *Task) *TaskResult {
+	mockExecutor := func(_ context.Context, task *Task) *TaskResult {
 		task.MarkRunning()
 		task.MarkCompleted()
 		return NewTaskResult(task, true, nil)
@@ -512,6 +584,578 @@ func TestWorkerPool_WithProxyPool(t *testing.T) {
 	}
 }
 
+// ===== Rank check tests =====
+
+// fakeProvider serves canned result pages keyed by page number
+type fakeProvider struct {
+	pages map[int][]serp.SearchResult
+	calls []int
+}
+
+func (p *fakeProvider) Search(_ context.Context, _ string, page int) ([]serp.SearchResult, error) {
+	p.calls = append(p.calls, page)
+	results, ok := p.pages[page]
+	if !ok {
+		return nil, serp.ErrNoMorePages
+	}
+	return results, nil
+}
+
+// failingProvider fails every request
+type failingProvider struct{}
+
+func (failingProvider) Search(_ context.Context, _ string, _ int) ([]serp.SearchResult, error) {
+	return nil, errors.New("connection refused")
+}
+
+func runRankCheck(t *testing.T, provider serp.Provider, maxPages int) *TaskResult {
+	t.Helper()
+
+	log, err := logger.New(logger.Config{
+		Level:      logger.ErrorLevel,
+		EnableFile: false,
+	})
+	require.NoError(t, err)
+
+	pool := NewWorkerPool(WorkerPoolConfig{
+		Workers:  1,
+		Logger:   log,
+		MaxPages: maxPages,
+		Provider: provider,
+	})
+	require.NoError(t, pool.Start())
+	defer pool.Stop()
+
+	task, err := NewTask(TaskConfig{
+		Keyword:   "golang tutorial",
+		TargetURL: "example.com",
+		Type:      TaskTypeRankCheck,
+	})
+	require.NoError(t, err)
+	require.NoError(t, pool.Submit(task))
+
+	select {
+	case result := <-pool.GetResults():
+		return result
+	case <-time.After(1 * time.Second):
+		t.Fatal("Timeout waiting for result")
+		return nil
+	}
+}
+
+func TestWorkerPool_RankCheckFound(t *testing.T) {
+	provider := &fakeProvider{pages: map[int][]serp.SearchResult{
+		1: {{URL: "https://go.dev/", Position: 1}},
+		2: {{URL: "https://other.com/", Position: 1}, {URL: "https://www.example.com/", Position: 2}},
+	}}
+
+	result := runRankCheck(t, provider, 5)
+	require.NotNil(t, result)
+	assert.True(t, result.Success)
+	assert.Equal(t, "found", result.Outcome)
+	assert.Equal(t, 3, result.Position) // one result on page 1, second slot on page 2
+	assert.Equal(t, 2, result.PageNumber)
+	assert.Equal(t, []int{1, 2}, provider.calls)
+}
+
+func TestTaskResult_ToStats(t *testing.T) {
+	task, err := NewTask(TaskConfig{
+		Keyword:     "golang",
+		TargetURL:   "example.com",
+		Competitors: []string{"rival.com"},
+		Locale:      serp.Locale{HL: "de", GL: "DE"},
+		ProxyURL:    "http://proxy:8080",
+	})
+	require.NoError(t, err)
+
+	result := NewTaskResult(task, true, nil)
+	result.Position = 14
+	result.PageNumber = 2
+	result.Outcome = "found"
+	result.Features = []string{"featured_snippet"}
+	result.Competitors = []CompetitorResult{{TargetURL: "rival.com", Position: 3, PageNumber: 1}}
+	result.SelectorProfile = "google@2"
+	result.Fallbacks = []serp.Fallback{{Field: "result_item", Primary: "div.g", Selector: "div.MjjYud"}}
+
+	taskStats := result.ToStats()
+	assert.Equal(t, task.ID, taskStats.TaskID)
+	assert.Equal(t, 14, taskStats.Position)
+	assert.Equal(t, 2, taskStats.PageNumber)
+	assert.Equal(t, "found", taskStats.Outcome)
+	assert.Equal(t, []string{"featured_snippet"}, taskStats.Features)
+	assert.Equal(t, "http://proxy:8080", taskStats.ProxyUsed)
+	assert.Equal(t, "hl=de,gl=DE", taskStats.Locale)
+	assert.Equal(t, "google@2", taskStats.Selectors)
+	assert.Equal(t, []string{"result_item: div.g -> div.MjjYud"}, taskStats.Fallbacks)
+	assert.Empty(t, taskStats.Error)
+	require.Len(t, taskStats.Competitors, 1)
+	assert.Equal(t, "rival.com", taskStats.Competitors[0].TargetURL)
+	assert.Equal(t, 3, taskStats.Competitors[0].Position)
+}
+
+func TestWorkerPool_RankCheckNotFound(t *testing.T) {
+	provider := &fakeProvider{pages: map[int][]serp.SearchResult{
+		1: {{URL: "https://go.dev/", Position: 1}},
+		2: {{URL: "https://other.com/", Position: 1}},
+		3: {{URL: "https://example.com/", Position: 1}},
+	}}
+
+	result := runRankCheck(t, provider, 2)
+	require.NotNil(t, result)
+	assert.True(t, result.Success)
+	assert.NoError(t, result.Error)
+	assert.Equal(t, "not_in_top", result.Outcome)
+	assert.Equal(t, 2, result.Depth)
+	assert.Equal(t, 0, result.Position)
+	assert.Equal(t, []int{1, 2}, provider.calls)
+}
+
+func TestWorkerPool_RankCheckStopsAtLastPage(t *testing.T) {
+	provider := &fakeProvider{pages: map[int][]serp.SearchResult{
+		1: {{URL: "https://go.dev/", Position: 1}},
+	}}
+
+	result := runRankCheck(t, provider, 5)
+	require.NotNil(t, result)
+	assert.Equal(t, "not_in_top", result.Outcome)
+	assert.Equal(t, 1, result.Depth)
+	assert.Equal(t, []int{1, 2}, provider.calls)
+}
+
+func TestWorkerPool_RankCheckProviderError(t *testing.T) {
+	result := runRankCheck(t, failingProvider{}, 5)
+	require.NotNil(t, result)
+	assert.False(t, result.Success)
+	assert.Contains(t, result.Error.Error(), "search failed on page 1")
+}
+
+// ===== Browser driver tests =====
+
+// fakeGoogle returns a fake driver serving a Google home page and two result pages
+func fakeGoogle() *browsertest.Driver {
+	return browsertest.NewDriver().
+		AddPage("https://www.google.com/", `<html><body>
+			<form action="/search"><textarea name="q"></textarea></form>
+		</body></html>`).
+		AddPage("https://www.google.com/search?q=golang+tutorial", `<html><body>
+			<div class="g"><a href="https://go.dev/"><h3>Go</h3></a></div>
+			<div class="g"><a href="https://rival.com/"><h3>Rival</h3></a></div>
+			<a id="pnnext" href="/search?q=golang+tutorial&amp;start=10">Next</a>
+		</body></html>`).
+		AddPage("https://www.google.com/search?q=golang+tutorial&start=10", `<html><body>
+			<div class="g"><a href="https://other.com/"><h3>Other</h3></a></div>
+			<div class="g"><a href="https://www.example.com/go"><h3>Example</h3></a></div>
+		</body></html>`)
+}
+
+// runBrowserTask runs a rank check in a pool whose browsers come from factory
+func runBrowserTask(t *testing.T, factory browser.DriverFactory, artifacts *artifact.Store) *TaskResult {
+	t.Helper()
+
+	log, err := logger.New(logger.Config{Level: logger.ErrorLevel})
+	require.NoError(t, err)
+
+	pool := NewWorkerPool(WorkerPoolConfig{
+		Workers:       1,
+		Logger:        log,
+		MaxPages:      3,
+		DriverFactory: factory,
+		Artifacts:     artifacts,
+	})
+	require.NoError(t, pool.Start())
+	defer pool.Stop()
+
+	task, err := NewTask(TaskConfig{
+		Keyword:     "golang tutorial",
+		TargetURL:   "example.com",
+		Competitors: []string{"rival.com"},
+		Type:        TaskTypeRankCheck,
+	})
+	require.NoError(t, err)
+	require.NoError(t, pool.Submit(task))
+
+	select {
+	case result := <-pool.GetResults():
+		return result
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for result")
+		return nil
+	}
+}
+
+func TestWorkerPool_DriverRankCheck(t *testing.T) {
+	driver := fakeGoogle()
+
+	result := runBrowserTask(t, browsertest.Factory(driver), nil)
+	require.NotNil(t, result)
+	require.NoError(t, result.Error)
+	assert.True(t, result.Success)
+	assert.Equal(t, 4, result.Position)
+	assert.Equal(t, 2, result.PageNumber)
+	require.Len(t, result.Competitors, 1)
+	assert.Equal(t, 2, result.Competitors[0].Position)
+	assert.Equal(t, "google@2", result.SelectorProfile)
+	assert.Nil(t, result.Page)
+	assert.True(t, driver.Closed(), "the browser is closed after the task")
+}
+
+func TestWorkerPool_DriverCaptchaSavesArtifacts(t *testing.T) {
+	driver := fakeGoogle().
+		AddPage("https://www.google.com/search?q=golang+tutorial", `<html><head><title>Sorry</title></head><body>
+			<iframe title="reCAPTCHA" src="https://www.google.com/recaptcha/api2/anchor"></iframe>
+		</body></html>`).
+		SetConsoleLogs("[error] blocked")
+	store := artifact.NewStore(artifact.StoreConfig{Dir: t.TempDir()})
+
+	result := runBrowserTask(t, browsertest.Factory(driver), store)
+	require.NotNil(t, result)
+	assert.False(t, result.Success)
+	assert.True(t, apperrors.Is(result.Error, apperrors.ErrorTypeCaptcha), "got %v", result.Error)
+
+	require.NotNil(t, result.Page)
+	assert.Equal(t, "https://www.google.com/search?q=golang+tutorial", result.Page.URL)
+	assert.Equal(t, "Sorry", result.Page.Title)
+	require.NotEmpty(t, result.Page.Artifacts)
+
+	saved, err := store.Load(result.Task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "captcha", saved.ErrorType)
+	console, err := os.ReadFile(filepath.Join(result.Page.Artifacts, artifact.ConsoleFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(console), "[error] blocked")
+	assert.FileExists(t, filepath.Join(result.Page.Artifacts, artifact.ScreenshotFile))
+}
+
+func TestWorkerPool_DriverTimeoutSavesArtifacts(t *testing.T) {
+	// The search box never shows up, and the wait runs into the task deadline
+	driver := fakeGoogle().Hang("WaitVisible")
+	store := artifact.NewStore(artifact.StoreConfig{Dir: t.TempDir()})
+	log, err := logger.New(logger.Config{Level: logger.ErrorLevel})
+	require.NoError(t, err)
+
+	pool := NewWorkerPool(WorkerPoolConfig{
+		Workers:       1,
+		Logger:        log,
+		MaxPages:      1,
+		SearchTimeout: 20 * time.Millisecond,
+		DriverFactory: browsertest.Factory(driver),
+		Artifacts:     store,
+	})
+	require.NoError(t, pool.Start())
+	defer pool.Stop()
+
+	task, err := NewTask(TaskConfig{Keyword: "golang tutorial", TargetURL: "example.com", Type: TaskTypeRankCheck})
+	require.NoError(t, err)
+	require.NoError(t, pool.Submit(task))
+
+	var result *TaskResult
+	select {
+	case result = <-pool.GetResults():
+	case <-time.After(5 * time.Second):
+		driver.Close() // Let the hanging worker stop
+		t.Fatal("Timeout waiting for result")
+	}
+	assert.False(t, result.Success)
+	assert.True(t, apperrors.Is(result.Error, apperrors.ErrorTypeSelector), "got %v", result.Error)
+
+	// The browser is still open to record the page
+	require.NotNil(t, result.Page)
+	assert.Equal(t, "https://www.google.com/", result.Page.URL)
+	require.NotEmpty(t, result.Page.Artifacts)
+	saved, err := store.Load(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "selector", saved.ErrorType)
+	assert.FileExists(t, filepath.Join(result.Page.Artifacts, artifact.ScreenshotFile))
+}
+
+func TestWorkerPool_DriverLaunchFailure(t *testing.T) {
+	result := runBrowserTask(t, browsertest.Factory(), nil)
+	require.NotNil(t, result)
+	assert.False(t, result.Success)
+	assert.Contains(t, result.Error.Error(), "failed to create browser")
+}
+
+func TestWorkerPool_DriverSearchTimeout(t *testing.T) {
+	// The search box never shows up
+	driver := fakeGoogle().Hang("WaitVisible")
+	log, err := logger.New(logger.Config{Level: logger.ErrorLevel})
+	require.NoError(t, err)
+
+	pool := NewWorkerPool(WorkerPoolConfig{
+		Workers:       1,
+		Logger:        log,
+		MaxPages:      50,
+		SearchTimeout: 20 * time.Millisecond,
+		DriverFactory: browsertest.Factory(driver),
+	})
+	require.NoError(t, pool.Start())
+	defer pool.Stop()
+
+	task, err := NewTask(TaskConfig{Keyword: "golang tutorial", TargetURL: "example.com", Type: TaskTypeRankCheck})
+	require.NoError(t, err)
+	require.NoError(t, pool.Submit(task))
+
+	// The page fails after its own timeout, long before the 1s task deadline
+	select {
+	case result := <-pool.GetResults():
+		assert.False(t, result.Success)
+		assert.ErrorIs(t, result.Error, context.DeadlineExceeded)
+		assert.Less(t, result.Duration, 500*time.Millisecond)
+	case <-time.After(5 * time.Second):
+		driver.Close() // Let the hanging worker stop
+		t.Fatal("Timeout waiting for result")
+	}
+}
+
+func TestWorkerPool_DriverClickAfterCompetitorPages(t *testing.T) {
+	for name, competitor := range map[string]string{
+		"competitor_on_later_page": "other.com",
+		"competitor_missing":       "missing.com",
+	} {
+		t.Run(name, func(t *testing.T) {
+			driver := fakeGoogle()
+			log, err := logger.New(logger.Config{Level: logger.ErrorLevel})
+			require.NoError(t, err)
+
+			pool := NewWorkerPool(WorkerPoolConfig{
+				Workers:       1,
+				Logger:        log,
+				MaxPages:      3,
+				DriverFactory: browsertest.Factory(driver),
+			})
+			pool.dwell = time.Millisecond
+			require.NoError(t, pool.Start())
+			defer pool.Stop()
+
+			// The target ranks on page 1, the scan goes on to page 2 for the competitor
+			task, err := NewTask(TaskConfig{
+				Keyword:     "golang tutorial",
+				TargetURL:   "go.dev",
+				Competitors: []string{competitor},
+				Type:        TaskTypeClick,
+			})
+			require.NoError(t, err)
+			require.NoError(t, pool.Submit(task))
+
+			var result *TaskResult
+			select {
+			case result = <-pool.GetResults():
+			case <-time.After(5 * time.Second):
+				t.Fatal("Timeout waiting for result")
+			}
+			require.NoError(t, result.Error)
+			assert.True(t, result.Success)
+			assert.Equal(t, 1, result.PageNumber)
+
+			// The browser went back to page 1 before clicking
+			navigations := driver.CallsTo("Navigate")
+			require.NotEmpty(t, navigations)
+			assert.Equal(t, "Navigate(https://www.google.com/search?q=golang+tutorial)", navigations[len(navigations)-1].String())
+		})
+	}
+}
+
+// ===== Task deadline and cancellation tests =====
+
+func TestWorkerPool_TaskTimeout(t *testing.T) {
+	pool := NewWorkerPool(WorkerPoolConfig{
+		Logger:        logger.NewDefault(),
+		MaxPages:      3,
+		PageTimeout:   20 * time.Second,
+		SearchTimeout: 10 * time.Second,
+	})
+
+	rankCheck, err := NewTask(TaskConfig{Keyword: "golang", TargetURL: "example.com", Type: TaskTypeRankCheck})
+	require.NoError(t, err)
+	visit, err := NewTask(TaskConfig{Keyword: "golang", TargetURL: "example.com"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 30*time.Second, pool.taskTimeout(rankCheck))
+	assert.Equal(t, 50*time.Second, pool.taskTimeout(visit), "visits add the page timeout")
+}
+
+func TestWorkerPool_TaskDeadline(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: logger.ErrorLevel})
+	require.NoError(t, err)
+
+	pool := NewWorkerPool(WorkerPoolConfig{
+		Workers:       1,
+		Logger:        log,
+		MaxPages:      1,
+		SearchTimeout: 50 * time.Millisecond,
+		Executor: func(ctx context.Context, task *Task) *TaskResult {
+			<-ctx.Done()
+			task.MarkFailed()
+			return NewTaskResult(task, false, ctx.Err())
+		},
+	})
+	require.NoError(t, pool.Start())
+	defer pool.Stop()
+
+	task, err := NewTask(TaskConfig{Keyword: "golang", TargetURL: "example.com", Type: TaskTypeRankCheck})
+	require.NoError(t, err)
+	require.NoError(t, pool.Submit(task))
+
+	select {
+	case result := <-pool.GetResults():
+		assert.False(t, result.Success)
+		assert.ErrorIs(t, result.Error, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("Task deadline was not applied")
+	}
+}
+
+func TestWorkerPool_StopCancelsRunningTasks(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: logger.ErrorLevel})
+	require.NoError(t, err)
+
+	queue := NewMemoryQueue(1)
+	started := make(chan struct{})
+	pool := NewWorkerPool(WorkerPoolConfig{
+		Workers: 1,
+		Queue:   queue,
+		Logger:  log,
+		Executor: func(ctx context.Context, task *Task) *TaskResult {
+			close(started)
+			<-ctx.Done()
+			return NewTaskResult(task, false, ctx.Err())
+		},
+	})
+	require.NoError(t, pool.Start())
+
+	task, err := NewTask(TaskConfig{Keyword: "golang", TargetURL: "example.com"})
+	require.NoError(t, err)
+	require.NoError(t, pool.Submit(task))
+	<-started
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- pool.Stop() }()
+
+	select {
+	case err := <-stopped:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Stop waited for the running task")
+	}
+
+	_, ok := <-pool.GetResults()
+	assert.False(t, ok, "aborted tasks do not report a result")
+	assert.NoError(t, queue.Nack(task.ID), "the aborted task is still leased")
+}
+
+// ===== Retry tests =====
+
+// runWithExecutor runs one task through a pool with retries and returns its result
+func runWithExecutor(t *testing.T, maxRetries int, executor TaskExecutor) *TaskResult {
+	t.Helper()
+
+	log, err := logger.New(logger.Config{Level: logger.ErrorLevel})
+	require.NoError(t, err)
+
+	pool := NewWorkerPool(WorkerPoolConfig{
+		Workers:    1,
+		Logger:     log,
+		Executor:   executor,
+		MaxRetries: maxRetries,
+		RetryDelay: time.Millisecond,
+	})
+	require.NoError(t, pool.Start())
+	defer pool.Stop()
+
+	task, err := NewTask(TaskConfig{Keyword: "golang", TargetURL: "example.com", Type: TaskTypeRankCheck})
+	require.NoError(t, err)
+	require.NoError(t, pool.Submit(task))
+
+	select {
+	case result := <-pool.GetResults():
+		return result
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for result")
+		return nil
+	}
+}
+
+// failingExecutor fails with err until it ran failures times
+func failingExecutor(failures int, err error, calls *int) TaskExecutor {
+	return func(_ context.Context, task *Task) *TaskResult {
+		*calls++
+		task.MarkRunning()
+		if *calls <= failures {
+			task.MarkFailed()
+			return NewTaskResult(task, false, err)
+		}
+		task.MarkCompleted()
+		return NewTaskResult(task, true, nil)
+	}
+}
+
+func TestWorkerPool_RetriesRetryableErrors(t *testing.T) {
+	calls := 0
+	result := runWithExecutor(t, 3, failingExecutor(2, apperrors.NewNetworkError("request failed", nil), &calls))
+
+	assert.True(t, result.Success)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 3, result.Attempts)
+	assert.Equal(t, 3, result.ToStats().Attempts)
+	require.Len(t, result.History, 3)
+	assert.Equal(t, "network", result.History[0].ErrorType)
+	assert.Empty(t, result.History[2].Error, "the last attempt succeeded")
+}
+
+func TestWorkerPool_DoesNotRetryPermanentErrors(t *testing.T) {
+	calls := 0
+	result := runWithExecutor(t, 3, failingExecutor(5, apperrors.NewCaptchaError("CAPTCHA detected"), &calls))
+
+	assert.False(t, result.Success)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 1, result.Attempts)
+}
+
+func TestWorkerPool_GivesUpAfterMaxRetries(t *testing.T) {
+	calls := 0
+	result := runWithExecutor(t, 2, failingExecutor(5, context.DeadlineExceeded, &calls))
+
+	assert.False(t, result.Success)
+	assert.ErrorIs(t, result.Error, context.DeadlineExceeded)
+	assert.Equal(t, 3, calls, "the first attempt and two retries")
+	assert.Equal(t, 3, result.Attempts)
+}
+
+func TestWorkerPool_NoRetriesByDefault(t *testing.T) {
+	calls := 0
+	result := runWithExecutor(t, 0, failingExecutor(1, apperrors.NewTimeoutError("timed out", nil), &calls))
+
+	assert.False(t, result.Success)
+	assert.Equal(t, 1, result.Attempts)
+}
+
+func TestBackoffDelay(t *testing.T) {
+	assert.Equal(t, time.Second, backoffDelay(time.Second, 0))
+	assert.Equal(t, 4*time.Second, backoffDelay(time.Second, 2))
+	assert.Equal(t, 5*time.Minute, backoffDelay(time.Second, 20), "capped")
+}
+
+// ===== Debug artifact tests =====
+
+func TestSaveArtifacts(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"selector", fmt.Errorf("search failed: %w", apperrors.NewSelectorError("no results found", nil)), true},
+		{"captcha", apperrors.NewCaptchaError("CAPTCHA detected"), true},
+		{"timeout", apperrors.NewTimeoutError("page load timed out", context.DeadlineExceeded), false},
+		{"not_found", fmt.Errorf("target not found: %w", serp.ErrTargetNotFound), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, saveArtifacts(tt.err))
+		})
+	}
+}
+
 // ===== generateTaskID test =====
 
 func TestGenerateTaskID(t *testing.T) {
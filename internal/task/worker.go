@@ -6,39 +6,71 @@ import (
 	"sync"
 	"time"
 
+	"github.com/omer/go-bot/internal/artifact"
 	"github.com/omer/go-bot/internal/browser"
+	apperrors "github.com/omer/go-bot/internal/errors"
 	"github.com/omer/go-bot/internal/logger"
+	"github.com/omer/go-bot/internal/metrics"
 	"github.com/omer/go-bot/internal/proxy"
 	"github.com/omer/go-bot/internal/serp"
+	"github.com/omer/go-bot/pkg/utils"
 )
 
-// TaskExecutor is a function type that executes a task
-type TaskExecutor func(task *Task) *TaskResult
+// TaskExecutor is a function type that executes a task.
+// The context carries the task deadline and is cancelled when the pool
+// stops; executors should return as soon as it is done.
+type TaskExecutor func(ctx context.Context, task *Task) *TaskResult
 
 // WorkerPool manages a pool of workers for concurrent task execution
 type WorkerPool struct {
-	workers      int                // Number of worker goroutines
-	taskQueue    chan *Task         // Channel for incoming tasks
-	resultQueue  chan *TaskResult   // Channel for task results
-	wg           sync.WaitGroup     // WaitGroup for worker synchronization
-	ctx          context.Context    // Context for cancellation
-	cancel       context.CancelFunc // Cancel function
-	proxyPool    *proxy.ProxyPool   // Proxy pool
-	logger       *logger.Logger     // Logger
-	executor     TaskExecutor       // Custom task executor (for testing)
-	running      bool               // Whether the pool is running
-	mu           sync.RWMutex       // Mutex for concurrent access
-	tasksStarted int                // Number of tasks started
-	tasksDone    int                // Number of tasks completed
+	workers       int                   // Number of worker goroutines
+	queue         Queue                 // Tasks waiting for a worker
+	resultQueue   chan *TaskResult      // Channel for task results
+	wg            sync.WaitGroup        // WaitGroup for worker synchronization
+	ctx           context.Context       // Context of running tasks, cancelled by Stop
+	cancel        context.CancelFunc    // Cancel function
+	stopCtx       context.Context       // Cancelled by Stop so idle workers stop dequeuing
+	stop          context.CancelFunc    // Cancel function of stopCtx
+	proxyPool     *proxy.ProxyPool      // Proxy pool
+	logger        *logger.Logger        // Logger
+	executor      TaskExecutor          // Custom task executor (for testing)
+	running       bool                  // Whether the pool is running
+	mu            sync.RWMutex          // Mutex for concurrent access
+	tasksStarted  int                   // Number of tasks started
+	tasksDone     int                   // Number of tasks completed
+	maxPages      int                   // Maximum result pages scanned by rank checks
+	pageTimeout   time.Duration         // Time allowed to open and stay on the target page
+	searchTimeout time.Duration         // Time allowed per result page
+	maxRetries    int                   // Retries of a task after a retryable failure
+	retryDelay    time.Duration         // Delay before the first retry; doubles with every retry
+	artifacts     *artifact.Store       // Debug artifacts of failed tasks (nil = none)
+	selectors     serp.SelectorProfile  // Selectors the browser searches with
+	searchURL     string                // Search engine root the browser opens (empty = Google)
+	newDriver     browser.DriverFactory // Creates the browser of a task
+	dwell         time.Duration         // Time spent on the target page after the click; tests shorten it
+	provider      serp.Provider         // Result provider for rank checks (nil = browser)
+	metrics       *metrics.Metrics      // Metrics (nil = disabled)
 }
 
 // WorkerPoolConfig holds configuration for creating a worker pool
 type WorkerPoolConfig struct {
-	Workers   int              // Number of worker goroutines
-	QueueSize int              // Size of task queue (0 for unbuffered)
-	ProxyPool *proxy.ProxyPool // Proxy pool for rotation
-	Logger    *logger.Logger   // Logger instance
-	Executor  TaskExecutor     // Optional custom executor (for testing)
+	Workers       int                   // Number of worker goroutines
+	QueueSize     int                   // Size of the in-memory task queue (0 for unbuffered)
+	Queue         Queue                 // Optional task queue, e.g. a durable BoltQueue (default: in-memory queue of QueueSize)
+	ProxyPool     *proxy.ProxyPool      // Proxy pool for rotation
+	Logger        *logger.Logger        // Logger instance
+	Executor      TaskExecutor          // Optional custom executor (for testing)
+	MaxPages      int                   // Maximum result pages scanned by rank checks (default: 5)
+	PageTimeout   time.Duration         // Time allowed to open and stay on the target page (default: 30s)
+	SearchTimeout time.Duration         // Time allowed per result page (default: 15s)
+	MaxRetries    int                   // Retries of a task after a retryable failure (default: 0 = no retries)
+	RetryDelay    time.Duration         // Delay before the first retry; doubles with every retry (default: 5s)
+	Artifacts     *artifact.Store       // Optional store for debug artifacts of tasks failing on a selector or CAPTCHA
+	Selectors     serp.SelectorProfile  // Selector profile the browser searches with (default: serp.DefaultProfile())
+	SearchURL     string                // Optional search engine root, e.g. a local mock server (default: Google on the task locale domain)
+	DriverFactory browser.DriverFactory // Optional factory of task browsers, e.g. a fake driver in tests (default: browser.NewDriver)
+	Provider      serp.Provider         // Optional result provider for rank checks (default: browser)
+	Metrics       *metrics.Metrics      // Optional metrics for tasks, queue depth and browser launches
 }
 
 // NewWorkerPool creates a new worker pool
@@ -52,28 +84,105 @@ type WorkerPoolConfig struct {
 //	})
 func NewWorkerPool(config WorkerPoolConfig) *WorkerPool {
 	// Set defaults
-	if config.Workers <= 0 {
-		config.Workers = 1
+	config.setDefaults()
+
+	if config.Selectors.Name == "" {
+		config.Selectors = serp.DefaultProfile()
+	}
+	if config.DriverFactory == nil {
+		config.DriverFactory = browser.NewDriver
 	}
-	if config.QueueSize < 0 {
-		config.QueueSize = 0
+
+	if config.Queue == nil {
+		config.Queue = NewMemoryQueue(config.QueueSize)
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
+	stopCtx, stop := context.WithCancel(ctx)
+
+	config.Metrics.WatchQueue(config.Queue.Len)
 
 	return &WorkerPool{
-		workers:     config.Workers,
-		taskQueue:   make(chan *Task, config.QueueSize),
-		resultQueue: make(chan *TaskResult, config.Workers),
-		ctx:         ctx,
-		cancel:      cancel,
-		proxyPool:   config.ProxyPool,
-		logger:      config.Logger,
-		executor:    config.Executor,
-		running:     false,
+		workers:       config.Workers,
+		queue:         config.Queue,
+		resultQueue:   make(chan *TaskResult, config.Workers),
+		ctx:           ctx,
+		cancel:        cancel,
+		stopCtx:       stopCtx,
+		stop:          stop,
+		proxyPool:     config.ProxyPool,
+		logger:        config.Logger,
+		executor:      config.Executor,
+		running:       false,
+		maxPages:      config.MaxPages,
+		pageTimeout:   config.PageTimeout,
+		searchTimeout: config.SearchTimeout,
+		maxRetries:    config.MaxRetries,
+		retryDelay:    config.RetryDelay,
+		artifacts:     config.Artifacts,
+		selectors:     config.Selectors,
+		searchURL:     config.SearchURL,
+		newDriver:     config.DriverFactory,
+		dwell:         5 * time.Second,
+		provider:      config.Provider,
+		metrics:       config.Metrics,
 	}
 }
 
+// leaseMargin is added to the worst-case task time for closing the browser,
+// saving debug artifacts and delivering the result
+const leaseMargin = time.Minute
+
+// captureTimeout is the time the browser of a task stays open after the task
+// deadline, to record the page a failed task ended on
+const captureTimeout = 10 * time.Second
+
+// setDefaults fills in the defaults of unset options, except the queue
+func (c *WorkerPoolConfig) setDefaults() {
+	if c.Workers <= 0 {
+		c.Workers = 1
+	}
+	if c.QueueSize < 0 {
+		c.QueueSize = 0
+	}
+	if c.MaxPages <= 0 {
+		c.MaxPages = 5
+	}
+	if c.PageTimeout <= 0 {
+		c.PageTimeout = 30 * time.Second
+	}
+	if c.SearchTimeout <= 0 {
+		c.SearchTimeout = 15 * time.Second
+	}
+	if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	}
+	if c.RetryDelay <= 0 {
+		c.RetryDelay = 5 * time.Second
+	}
+}
+
+// LeaseTimeout returns the longest time a worker of a pool with this
+// configuration holds a task: every attempt runs until its deadline and
+// every retry waits its full backoff. Durable queues must lease tasks at
+// least this long, or a running task is delivered to a second worker.
+//
+// Example:
+//
+//	queue, err := task.OpenBoltQueue("data/queue.db", task.BoltQueueConfig{
+//	    LeaseTimeout: config.LeaseTimeout(),
+//	})
+func (c WorkerPoolConfig) LeaseTimeout() time.Duration {
+	c.setDefaults()
+
+	attempt := time.Duration(c.MaxPages)*c.SearchTimeout + c.PageTimeout + captureTimeout
+	lease := time.Duration(c.MaxRetries+1)*attempt + leaseMargin
+	for retry := 0; retry < c.MaxRetries; retry++ {
+		lease += backoffDelay(c.RetryDelay, retry)
+	}
+	return lease
+}
+
 // Start starts the worker pool
 // It spawns worker goroutines and starts processing tasks
 func (wp *WorkerPool) Start() error {
@@ -98,8 +207,10 @@ func (wp *WorkerPool) Start() error {
 	return nil
 }
 
-// Stop stops the worker pool gracefully
-// It waits for all workers to finish their current tasks
+// Stop stops the worker pool
+// It cancels the context of running tasks and waits for the workers to
+// return. Aborted tasks stay leased and tasks still waiting in the queue are
+// not started; a durable queue delivers both again to the next process.
 func (wp *WorkerPool) Stop() error {
 	wp.mu.Lock()
 	if !wp.running {
@@ -110,8 +221,9 @@ func (wp *WorkerPool) Stop() error {
 
 	wp.logger.Info("Stopping worker pool", nil)
 
-	// Close task queue to signal workers to stop
-	close(wp.taskQueue)
+	// Signal idle workers to stop dequeuing and abort running tasks
+	wp.stop()
+	wp.cancel()
 
 	// Wait for all workers to finish
 	wp.wg.Wait()
@@ -119,9 +231,6 @@ func (wp *WorkerPool) Stop() error {
 	// Close result queue
 	close(wp.resultQueue)
 
-	// Cancel context
-	wp.cancel()
-
 	wp.mu.Lock()
 	wp.running = false
 	wp.mu.Unlock()
@@ -144,16 +253,31 @@ func (wp *WorkerPool) Submit(task *Task) error {
 		return fmt.Errorf("worker pool is not running")
 	}
 
-	select {
-	case <-wp.ctx.Done():
-		return fmt.Errorf("worker pool is shutting down")
-	case wp.taskQueue <- task:
-		wp.logger.Debug("Task submitted", map[string]interface{}{
-			"task_id": task.ID,
-			"keyword": task.Keyword,
-		})
-		return nil
+	if err := wp.queue.Enqueue(wp.stopCtx, task); err != nil {
+		if wp.stopCtx.Err() != nil {
+			return fmt.Errorf("worker pool is shutting down")
+		}
+		return fmt.Errorf("failed to queue task: %w", err)
+	}
+
+	wp.logger.Debug("Task submitted", map[string]interface{}{
+		"task_id": task.ID,
+		"keyword": task.Keyword,
+	})
+	return nil
+}
+
+// unfinishedQueue is a durable queue holding tasks of a previous run
+type unfinishedQueue interface {
+	Unfinished() []*Task
+}
+
+// unfinished returns the tasks the queue carried over from a previous run
+func (wp *WorkerPool) unfinished() []*Task {
+	if queue, ok := wp.queue.(unfinishedQueue); ok {
+		return queue.Unfinished()
 	}
+	return nil
 }
 
 // GetResults returns the result channel
@@ -179,7 +303,7 @@ func (wp *WorkerPool) Stats() map[string]interface{} {
 		"running":       wp.running,
 		"tasks_started": wp.tasksStarted,
 		"tasks_done":    wp.tasksDone,
-		"queue_length":  len(wp.taskQueue),
+		"queue_length":  wp.queue.Len(),
 	}
 }
 
@@ -192,68 +316,143 @@ func (wp *WorkerPool) worker(id int) {
 	})
 
 	for {
-		select {
-		case <-wp.ctx.Done():
-			// Context cancelled, stop worker
-			wp.logger.Debug("Worker stopping (context done)", map[string]interface{}{
+		task, err := wp.queue.Dequeue(wp.stopCtx)
+		if err != nil {
+			// Pool stopping or queue closed, stop worker
+			wp.logger.Debug("Worker stopping", map[string]interface{}{
 				"worker_id": id,
+				"reason":    err.Error(),
 			})
 			return
+		}
 
-		case task, ok := <-wp.taskQueue:
-			if !ok {
-				// Task queue closed, stop worker
-				wp.logger.Debug("Worker stopping (queue closed)", map[string]interface{}{
-					"worker_id": id,
-				})
-				return
-			}
+		// Execute task
+		wp.mu.Lock()
+		wp.tasksStarted++
+		wp.mu.Unlock()
 
-			// Execute task
-			wp.mu.Lock()
-			wp.tasksStarted++
-			wp.mu.Unlock()
+		wp.logger.Info("Worker executing task", map[string]interface{}{
+			"worker_id": id,
+			"task_id":   task.ID,
+			"keyword":   task.Keyword,
+		})
 
-			wp.logger.Info("Worker executing task", map[string]interface{}{
-				"worker_id": id,
-				"task_id":   task.ID,
-				"keyword":   task.Keyword,
+		result := wp.execute(task)
+		if wp.ctx.Err() != nil {
+			// The pool stopped while the task was running; it stays leased
+			// and is delivered again
+			wp.logger.Warn("Task aborted by shutdown", map[string]interface{}{
+				"task_id": task.ID,
 			})
+			return
+		}
 
-			var result *TaskResult
-			if wp.executor != nil {
-				// Use custom executor (for testing)
-				result = wp.executor(task)
-			} else {
-				// Use default executor
-				result = wp.executeTask(task)
-			}
+		wp.mu.Lock()
+		wp.tasksDone++
+		wp.mu.Unlock()
+		wp.observe(result)
 
-			wp.mu.Lock()
-			wp.tasksDone++
-			wp.mu.Unlock()
-
-			// Send result
-			select {
-			case wp.resultQueue <- result:
-				wp.logger.Debug("Task result sent", map[string]interface{}{
-					"task_id": task.ID,
-					"success": result.Success,
-				})
-			case <-wp.ctx.Done():
-				wp.logger.Warn("Failed to send result (context done)", map[string]interface{}{
-					"task_id": task.ID,
-				})
-				return
-			}
+		// Send result
+		select {
+		case wp.resultQueue <- result:
+			wp.logger.Debug("Task result sent", map[string]interface{}{
+				"task_id": task.ID,
+				"success": result.Success,
+			})
+		case <-wp.ctx.Done():
+			// The task stays leased and is delivered again
+			wp.logger.Warn("Failed to send result (context done)", map[string]interface{}{
+				"task_id": task.ID,
+			})
+			return
+		}
+
+		// Remove the task from the queue only after its result was delivered
+		if err := wp.queue.Ack(task.ID); err != nil {
+			wp.logger.Warn("Failed to ack task", map[string]interface{}{
+				"task_id": task.ID,
+				"error":   err,
+			})
+		}
+	}
+}
+
+// execute runs a task and retries retryable failures (timeouts, network and
+// proxy errors) with exponential backoff and jitter
+func (wp *WorkerPool) execute(task *Task) *TaskResult {
+	var history []Attempt
+	for attempt := 1; ; attempt++ {
+		started := time.Now()
+		result := wp.attempt(task)
+		history = append(history, newAttempt(started, result.Error))
+		result.Attempts = attempt
+		result.History = history
+		if result.Success || attempt > wp.maxRetries || !apperrors.IsRetryable(result.Error) {
+			return result
+		}
+
+		// Equal jitter: wait between half and all of the backoff delay
+		backoff := backoffDelay(wp.retryDelay, attempt-1)
+		delay := utils.RandomDuration(backoff/2, backoff)
+		wp.logger.Warn("Task attempt failed, retrying", map[string]interface{}{
+			"task_id": task.ID,
+			"attempt": attempt,
+			"delay":   delay.String(),
+			"error":   result.Error.Error(),
+		})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-wp.ctx.Done():
+			timer.Stop()
+			return result
 		}
 	}
 }
 
+// newAttempt records an execution that started at started and ended now
+func newAttempt(started time.Time, err error) Attempt {
+	attempt := Attempt{StartedAt: started, Duration: time.Since(started)}
+	if err != nil {
+		attempt.Error = err.Error()
+		attempt.ErrorType = metrics.ErrorType(err).String()
+	}
+	return attempt
+}
+
+// attempt runs a task once under its deadline
+func (wp *WorkerPool) attempt(task *Task) *TaskResult {
+	ctx, cancel := context.WithTimeout(wp.ctx, wp.taskTimeout(task))
+	defer cancel()
+
+	if wp.executor != nil {
+		// Use custom executor (for testing)
+		return wp.executor(ctx, task)
+	}
+	// Use default executor
+	return wp.executeTask(ctx, task)
+}
+
+// taskTimeout returns the deadline of a task: the search timeout for every
+// scanned page, plus the page timeout for tasks that visit the target
+func (wp *WorkerPool) taskTimeout(task *Task) time.Duration {
+	timeout := time.Duration(wp.maxPages) * wp.searchTimeout
+	if task.Type != TaskTypeRankCheck {
+		timeout += wp.pageTimeout
+	}
+	return timeout
+}
+
 // executeTask executes a single task
-func (wp *WorkerPool) executeTask(task *Task) *TaskResult {
+func (wp *WorkerPool) executeTask(ctx context.Context, task *Task) *TaskResult {
 	task.MarkRunning()
 
+	// Rank checks can be served by a configured provider without a browser
+	if task.Type == TaskTypeRankCheck && wp.provider != nil {
+		return wp.checkRank(ctx, task, wp.provider)
+	}
+
 	// Get proxy if pool is available
 	var taskProxy *proxy.Proxy
 	var proxySuccess bool = false
@@ -271,54 +470,245 @@ func (wp *WorkerPool) executeTask(task *Task) *TaskResult {
 		}
 	}
 
-	// Create browser
+	// Create browser; it is closed captureTimeout after the task deadline or
+	// when the pool stops
 	browserOpts := browser.BrowserOptions{
 		Headless: true,
 		Proxy:    taskProxy,
-		Timeout:  60 * time.Second,
+		Device:   task.Locale.Device,
+		Timeout:  wp.taskTimeout(task) + captureTimeout,
+		Context:  wp.ctx,
 	}
 
-	b, err := browser.NewBrowser(browserOpts)
+	b, err := wp.newDriver(browserOpts)
+	wp.metrics.BrowserLaunched(err)
 	if err != nil {
 		task.MarkFailed()
 		return NewTaskResult(task, false, fmt.Errorf("failed to create browser: %w", err))
 	}
 	defer b.Close()
 
-	// Create searcher
-	searcher := serp.NewSearcher(b, wp.logger)
+	searcher := serp.NewSearcherWithProfile(b, wp.logger, wp.selectors)
+	if err := searcher.SetBaseURL(wp.searchURL); err != nil {
+		task.MarkFailed()
+		return NewTaskResult(task, false, err)
+	}
+
+	// Browser calls of the task end at the task deadline
+	release := b.Bind(ctx)
+	taskResult := wp.browse(ctx, task, b, searcher)
+	release()
+
+	taskResult.SelectorProfile = searcher.Profile().String()
+	taskResult.Fallbacks = searcher.Fallbacks()
+	if !taskResult.Success {
+		taskResult.Page = wp.capturePage(task, b, searcher, taskResult.Error)
+	}
+	proxySuccess = taskResult.Success
+	return taskResult
+}
+
+// browse runs a task in a browser
+func (wp *WorkerPool) browse(ctx context.Context, task *Task, b browser.Driver, searcher *serp.Searcher) *TaskResult {
+	// Rank checks only read the results and never click
+	if task.Type == TaskTypeRankCheck {
+		return wp.checkRank(ctx, task, serp.NewBrowserProvider(searcher))
+	}
 
-	// Perform search
-	err = searcher.Search(task.Keyword)
+	// Find the target across the result pages
+	scan, err := serp.Scan(ctx, serp.NewBrowserProvider(searcher), wp.searchOptions(task))
 	if err != nil {
 		task.MarkFailed()
 		return NewTaskResult(task, false, fmt.Errorf("search failed: %w", err))
 	}
-
-	// Find target
-	result, err := searcher.FindTarget(task.TargetURL)
-	if err != nil {
+	if !scan.Found() {
 		task.MarkFailed()
-		return NewTaskResult(task, false, fmt.Errorf("target not found: %w", err))
+		taskResult := NewTaskResult(task, false, fmt.Errorf("target not found: %w: not in top %d", serp.ErrTargetNotFound, scan.Depth))
+		applyScan(taskResult, scan)
+		return taskResult
+	}
+
+	// Open and visit the target within the page timeout
+	visitCtx, cancel := context.WithTimeout(ctx, wp.pageTimeout)
+	defer cancel()
+	release := b.Bind(visitCtx)
+	defer release()
+
+	// The scan pages on while competitors are missing, so the browser may be
+	// past the page the target was found on
+	if page, err := searcher.GetCurrentPage(); err != nil || page != scan.Page {
+		if err := searcher.GoToPage(task.Keyword, scan.Page); err != nil {
+			task.MarkFailed()
+			return NewTaskResult(task, false, fmt.Errorf("failed to open target page: %w", err))
+		}
 	}
 
-	// Click target
-	err = searcher.ClickTargetResult(task.TargetURL)
+	// Click target on the page it was found on
+	err = searcher.ClickTargetResult(scan.Result.URL)
 	if err != nil {
 		task.MarkFailed()
 		return NewTaskResult(task, false, fmt.Errorf("failed to click target: %w", err))
 	}
 
 	// Wait on target page
-	time.Sleep(5 * time.Second)
+	select {
+	case <-time.After(wp.dwell):
+	case <-visitCtx.Done():
+		task.MarkFailed()
+		return NewTaskResult(task, false, fmt.Errorf("visit aborted: %w", visitCtx.Err()))
+	}
 
 	task.MarkCompleted()
-	proxySuccess = true // Mark proxy as successful
 
 	taskResult := NewTaskResult(task, true, nil)
-	taskResult.Position = result.Position
-	taskResult.PageNumber = 1 // TODO: Get actual page number
-	taskResult.Message = fmt.Sprintf("Found and clicked target at position %d", result.Position)
+	applyScan(taskResult, scan)
+	taskResult.Message = fmt.Sprintf("Found and clicked target at position %d (page %d)", scan.Position, scan.Page)
+
+	return taskResult
+}
+
+// capturePage records the page a failed task ended on. It is best effort
+// and takes at most captureTimeout, the time the browser stays open after
+// the task deadline. Failures of selectors and CAPTCHAs also save debug
+// artifacts.
+func (wp *WorkerPool) capturePage(task *Task, b browser.Driver, searcher *serp.Searcher, taskErr error) *PageState {
+	ctx, cancel := context.WithTimeout(wp.ctx, captureTimeout)
+	defer cancel()
+	release := b.Bind(ctx)
+	defer release()
+
+	page := &PageState{}
+	page.URL, _ = b.GetCurrentURL()
+	page.Title, _ = b.GetTitle()
+	if page.URL == "" && page.Title == "" {
+		return nil
+	}
+
+	if wp.artifacts == nil || !saveArtifacts(taskErr) {
+		return page
+	}
+	dir, err := wp.artifacts.Save(wp.collectArtifacts(task, b, searcher, page, taskErr))
+	if err != nil {
+		wp.logger.Warn("Failed to save debug artifacts", map[string]interface{}{
+			"task_id": task.ID,
+			"error":   err.Error(),
+		})
+	}
+	if dir != "" {
+		page.Artifacts = dir
+	}
+	return page
+}
+
+// saveArtifacts reports whether a failure is worth debug artifacts: the
+// page did not look the way the selectors expect
+func saveArtifacts(err error) bool {
+	switch apperrors.GetType(err) {
+	case apperrors.ErrorTypeSelector, apperrors.ErrorTypeCaptcha:
+		return true
+	default:
+		return false
+	}
+}
+
+// collectArtifacts reads the debug artifacts of the current page
+func (wp *WorkerPool) collectArtifacts(task *Task, b browser.Driver, searcher *serp.Searcher, page *PageState, taskErr error) *artifact.Artifacts {
+	pageNumber, err := searcher.GetCurrentPage()
+	if err != nil {
+		pageNumber = 1
+	}
+
+	artifacts := &artifact.Artifacts{
+		TaskID:    task.ID,
+		Keyword:   task.Keyword,
+		Locale:    task.Locale,
+		Page:      pageNumber,
+		URL:       page.URL,
+		Title:     page.Title,
+		Error:     taskErr.Error(),
+		ErrorType: metrics.ErrorType(taskErr).String(),
+		Console:   b.ConsoleLogs(),
+	}
+	artifacts.Screenshot, _ = b.Screenshot()
+	artifacts.HTML, _ = b.GetHTML()
+	return artifacts
+}
+
+// checkRank scans the result pages for the target. It only reads the SERP
+// and never interacts with result links. A target outside the scanned pages
+// is a successful check with the not_in_top outcome.
+func (wp *WorkerPool) checkRank(ctx context.Context, task *Task, provider serp.Provider) *TaskResult {
+	scan, err := serp.Scan(ctx, provider, wp.searchOptions(task))
+	if err != nil {
+		task.MarkFailed()
+		return NewTaskResult(task, false, err)
+	}
+
+	task.MarkCompleted()
 
+	taskResult := NewTaskResult(task, true, nil)
+	applyScan(taskResult, scan)
+	if scan.Found() {
+		taskResult.Message = fmt.Sprintf("Target ranked at position %d on page %d", scan.Position, scan.Page)
+	} else {
+		taskResult.Message = fmt.Sprintf("Target not in top %d", scan.Depth)
+	}
 	return taskResult
 }
+
+// searchOptions builds the scan options for a task; every result page gets
+// its share of the task deadline
+func (wp *WorkerPool) searchOptions(task *Task) serp.SearchOptions {
+	return serp.SearchOptions{
+		Keyword:     task.Keyword,
+		TargetURL:   task.TargetURL,
+		Match:       task.Match,
+		Competitors: task.Competitors,
+		Locale:      task.Locale,
+		MaxPages:    wp.maxPages,
+		Timeout:     time.Duration(wp.maxPages) * wp.searchTimeout,
+		PageTimeout: wp.searchTimeout,
+	}
+}
+
+// observe records a finished task and the positions it measured in the metrics
+func (wp *WorkerPool) observe(result *TaskResult) {
+	if wp.metrics == nil {
+		return
+	}
+
+	wp.metrics.ObserveTask(string(result.Task.Type), result.Error, result.Duration)
+	for _, fallback := range result.Fallbacks {
+		wp.metrics.SelectorFallback(result.SelectorProfile, fallback.Field)
+	}
+	if result.Outcome == "" {
+		return
+	}
+
+	locale := result.Task.Locale.String()
+	wp.metrics.SetPosition(result.Task.Keyword, result.Task.TargetURL, locale, false, result.Position)
+	for _, competitor := range result.Competitors {
+		wp.metrics.SetPosition(result.Task.Keyword, competitor.TargetURL, locale, true, competitor.Position)
+	}
+}
+
+// applyScan copies the outcome of a rank scan into a task result
+func applyScan(taskResult *TaskResult, scan *serp.ScanResult) {
+	taskResult.Position = scan.Position
+	taskResult.VisualPosition = scan.VisualPosition
+	taskResult.PageNumber = scan.Page
+	taskResult.Outcome = string(scan.Outcome)
+	taskResult.Depth = scan.Depth
+	for _, feature := range scan.Features {
+		taskResult.Features = append(taskResult.Features, string(feature))
+	}
+	for _, competitor := range scan.Competitors {
+		taskResult.Competitors = append(taskResult.Competitors, CompetitorResult{
+			TargetURL:      competitor.Target,
+			URL:            competitor.URL,
+			Position:       competitor.Position,
+			VisualPosition: competitor.VisualPosition,
+			PageNumber:     competitor.Page,
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// Package urlmatch provides structured matching of result URLs against target patterns.
+// It replaces plain substring checks with host, path and regex rules that are
+// aware of internationalized domain names and public suffixes.
+package urlmatch
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Mode selects how a target pattern is compared with result URLs
+type Mode string
+
+const (
+	// ModeDomain matches the pattern host, with or without a "www." prefix
+	ModeDomain Mode = "domain"
+	// ModeSubdomain matches the pattern host and any of its subdomains ("*.example.com")
+	ModeSubdomain Mode = "subdomain"
+	// ModeExact matches one URL; scheme, default port, fragment and trailing slash are ignored
+	ModeExact Mode = "exact"
+	// ModePrefix matches the pattern host (as in ModeDomain) under a path prefix
+	ModePrefix Mode = "prefix"
+	// ModeRegex matches result URLs that contain a match of a regular
+	// expression; anchor it with ^ and $ to match the full URL
+	ModeRegex Mode = "regex"
+)
+
+// ParseMode converts a configuration value into a Mode
+func ParseMode(s string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case ModeDomain, ModeSubdomain, ModeExact, ModePrefix, ModeRegex:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown match mode: %s", s)
+	}
+}
+
+// DefaultMode returns the mode used when a target does not specify one:
+// "*.example.com" matches subdomains, targets with a path are matched as a
+// path prefix, and all others by domain.
+func DefaultMode(pattern string) Mode {
+	if strings.HasPrefix(strings.TrimSpace(pattern), "*.") {
+		return ModeSubdomain
+	}
+	u, err := parseURL(pattern)
+	if err == nil && strings.Trim(u.EscapedPath(), "/") != "" {
+		return ModePrefix
+	}
+	return ModeDomain
+}
+
+// Matcher matches result URLs against a compiled target pattern
+type Matcher struct {
+	mode    Mode
+	pattern string
+	host    string         // Normalized pattern host (domain, subdomain, prefix)
+	path    string         // Normalized path prefix (prefix)
+	exact   string         // Normalized URL (exact)
+	re      *regexp.Regexp // Compiled expression (regex)
+}
+
+// New compiles a target pattern for the given mode.
+// An empty mode selects DefaultMode(pattern).
+//
+// Example:
+//
+//	m, err := urlmatch.New(urlmatch.ModeSubdomain, "*.example.com")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	m.Match("https://blog.example.com/post") // true
+//	m.Match("https://notexample.com/")       // false
+func New(mode Mode, pattern string) (*Matcher, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil, fmt.Errorf("pattern cannot be empty")
+	}
+
+	if mode == "" {
+		mode = DefaultMode(pattern)
+	}
+	mode, err := ParseMode(string(mode))
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Matcher{mode: mode, pattern: pattern}
+
+	switch mode {
+	case ModeRegex:
+		if m.re, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		return m, nil
+
+	case ModeSubdomain:
+		pattern = strings.TrimPrefix(pattern, "*.")
+	}
+
+	u, err := parseURL(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", m.pattern, err)
+	}
+	if m.host, err = normalizeHost(u.Host); err != nil {
+		return nil, fmt.Errorf("invalid host in pattern %q: %w", m.pattern, err)
+	}
+
+	switch mode {
+	case ModeDomain, ModeSubdomain:
+		// A bare public suffix ("com", "co.uk") would match unrelated sites
+		if !strings.Contains(m.host, ".") || isPublicSuffix(m.host) {
+			return nil, fmt.Errorf("pattern %q is a public suffix, not a domain", m.pattern)
+		}
+	case ModePrefix:
+		m.path = normalizePath(u.EscapedPath())
+	case ModeExact:
+		m.exact = normalizeURL(u, m.host)
+	}
+
+	return m, nil
+}
+
+// Mode returns the matching mode
+func (m *Matcher) Mode() Mode {
+	return m.mode
+}
+
+// String returns the mode and the original pattern
+func (m *Matcher) String() string {
+	return fmt.Sprintf("%s:%s", m.mode, m.pattern)
+}
+
+// Match reports whether rawURL matches the pattern
+func (m *Matcher) Match(rawURL string) bool {
+	if m.mode == ModeRegex {
+		return m.re.MatchString(rawURL)
+	}
+
+	u, err := parseURL(rawURL)
+	if err != nil {
+		return false
+	}
+	host, err := normalizeHost(u.Host)
+	if err != nil {
+		return false
+	}
+
+	switch m.mode {
+	case ModeDomain:
+		return trimWWW(host) == trimWWW(m.host)
+	case ModeSubdomain:
+		return host == m.host || strings.HasSuffix(host, "."+m.host)
+	case ModePrefix:
+		if trimWWW(host) != trimWWW(m.host) {
+			return false
+		}
+		path := normalizePath(u.EscapedPath())
+		return m.path == "/" || path == m.path || strings.HasPrefix(path, m.path+"/")
+	case ModeExact:
+		return normalizeURL(u, host) == m.exact
+	}
+
+	return false
+}
+
+// parseURL parses a URL, accepting patterns without a scheme ("example.com/blog")
+func parseURL(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + strings.TrimPrefix(raw, "//")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing host")
+	}
+	return u, nil
+}
+
+// normalizeHost lowercases a host, drops the port and trailing dot and
+// converts internationalized names to their punycode (xn--) form
+func normalizeHost(host string) (string, error) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" {
+		return "", fmt.Errorf("empty host")
+	}
+	return idna.Lookup.ToASCII(host)
+}
+
+// normalizePath removes the trailing slash; the root path is "/"
+func normalizePath(path string) string {
+	path = strings.TrimRight(path, "/")
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// normalizeURL renders the parts of a URL that take part in exact matching
+func normalizeURL(u *url.URL, host string) string {
+	port := u.Port()
+	if port == "80" || port == "443" {
+		port = ""
+	}
+	if port != "" {
+		host = net.JoinHostPort(host, port)
+	}
+
+	normalized := host + normalizePath(u.EscapedPath())
+	if u.RawQuery != "" {
+		normalized += "?" + u.Query().Encode()
+	}
+	return normalized
+}
+
+// trimWWW removes a leading "www." label
+func trimWWW(host string) string {
+	return strings.TrimPrefix(host, "www.")
+}
+
+// isPublicSuffix reports whether host is itself a public suffix such as "co.uk"
+func isPublicSuffix(host string) bool {
+	suffix, _ := publicsuffix.PublicSuffix(host)
+	return suffix == host
+}
@@ -0,0 +1,176 @@
+package urlmatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ===== Mode tests =====
+
+func TestParseMode(t *testing.T) {
+	for _, s := range []string{"domain", "subdomain", "exact", "prefix", "regex", " Domain "} {
+		_, err := ParseMode(s)
+		assert.NoError(t, err, s)
+	}
+
+	_, err := ParseMode("contains")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown match mode")
+}
+
+func TestDefaultMode(t *testing.T) {
+	assert.Equal(t, ModeDomain, DefaultMode("example.com"))
+	assert.Equal(t, ModeDomain, DefaultMode("https://www.example.com/"))
+	assert.Equal(t, ModePrefix, DefaultMode("example.com/blog"))
+	assert.Equal(t, ModePrefix, DefaultMode("https://example.com/blog/"))
+	assert.Equal(t, ModeSubdomain, DefaultMode("*.example.com"))
+}
+
+// ===== New tests =====
+
+func TestNew_InvalidPatterns(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    Mode
+		pattern string
+		errMsg  string
+	}{
+		{"empty", ModeDomain, "", "pattern cannot be empty"},
+		{"unknown_mode", "contains", "example.com", "unknown match mode"},
+		{"bad_regex", ModeRegex, "example.(com", "invalid regex"},
+		{"tld", ModeDomain, "com", "public suffix"},
+		{"multi_label_suffix", ModeSubdomain, "*.co.uk", "public suffix"},
+		{"missing_host", ModeExact, "http:///path", "invalid pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.mode, tt.pattern)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestNew_DefaultMode(t *testing.T) {
+	m, err := New("", "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, ModeDomain, m.Mode())
+	assert.Equal(t, "domain:example.com", m.String())
+
+	m, err = New("", "example.com/blog")
+	require.NoError(t, err)
+	assert.Equal(t, ModePrefix, m.Mode())
+}
+
+// ===== Match tests =====
+
+type matchCase struct {
+	url      string
+	expected bool
+}
+
+func assertMatches(t *testing.T, mode Mode, pattern string, cases []matchCase) {
+	t.Helper()
+
+	m, err := New(mode, pattern)
+	require.NoError(t, err)
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, m.Match(c.url), "%s %q vs %q", mode, pattern, c.url)
+	}
+}
+
+func TestMatch_Domain(t *testing.T) {
+	assertMatches(t, ModeDomain, "example.com", []matchCase{
+		{"https://example.com/", true},
+		{"http://www.example.com/page?q=1", true},
+		{"https://EXAMPLE.com:443/", true},
+		{"https://example.com./", true},
+		{"https://blog.example.com/", false},
+		{"https://notexample.com/", false},
+		{"https://example.com.evil.io/", false},
+		{"https://evil.io/example.com", false},
+		{"not a url", false},
+	})
+}
+
+func TestMatch_Subdomain(t *testing.T) {
+	cases := []matchCase{
+		{"https://example.com/", true},
+		{"https://blog.example.com/post", true},
+		{"https://a.b.example.com/", true},
+		{"https://notexample.com/", false},
+		{"https://example.com.evil.io/", false},
+	}
+	assertMatches(t, ModeSubdomain, "*.example.com", cases)
+	assertMatches(t, ModeSubdomain, "example.com", cases)
+
+	assertMatches(t, ModeSubdomain, "example.co.uk", []matchCase{
+		{"https://shop.example.co.uk/", true},
+		{"https://other.co.uk/", false},
+	})
+}
+
+func TestMatch_Exact(t *testing.T) {
+	assertMatches(t, ModeExact, "https://example.com/docs/intro", []matchCase{
+		{"https://example.com/docs/intro", true},
+		{"http://example.com/docs/intro/", true},
+		{"https://example.com:443/docs/intro#setup", true},
+		{"https://www.example.com/docs/intro", false},
+		{"https://example.com/docs/intro/more", false},
+		{"https://example.com/docs/intro?page=2", false},
+	})
+
+	assertMatches(t, ModeExact, "example.com/search?b=2&a=1", []matchCase{
+		{"https://example.com/search?a=1&b=2", true},
+		{"https://example.com/search?a=1", false},
+	})
+}
+
+func TestMatch_Prefix(t *testing.T) {
+	assertMatches(t, ModePrefix, "example.com/blog", []matchCase{
+		{"https://example.com/blog", true},
+		{"https://www.example.com/blog/", true},
+		{"https://example.com/blog/2024/post", true},
+		{"https://example.com/blogger", false},
+		{"https://example.com/", false},
+		{"https://blog.example.com/blog", false},
+	})
+
+	assertMatches(t, ModePrefix, "example.com/", []matchCase{
+		{"https://example.com/anything", true},
+		{"https://other.com/anything", false},
+	})
+}
+
+func TestMatch_Regex(t *testing.T) {
+	assertMatches(t, ModeRegex, `^https://(www\.)?example\.com/(docs|blog)/`, []matchCase{
+		{"https://example.com/docs/intro", true},
+		{"https://www.example.com/blog/post", true},
+		{"https://example.com/about", false},
+		{"https://example.com.evil.io/docs/", false},
+	})
+
+	// Unanchored expressions match anywhere in the URL, query included
+	assertMatches(t, ModeRegex, `example\.com/blog`, []matchCase{
+		{"https://example.com/blog/post", true},
+		{"https://evil.test/?r=example.com/blog", true},
+	})
+	assertMatches(t, ModeRegex, `^https://example\.com/blog(/.*)?$`, []matchCase{
+		{"https://example.com/blog/post", true},
+		{"https://evil.test/?r=https://example.com/blog", false},
+	})
+}
+
+func TestMatch_IDN(t *testing.T) {
+	cases := []matchCase{
+		{"https://bücher.de/", true},
+		{"https://www.xn--bcher-kva.de/katalog", true},
+		{"https://buecher.de/", false},
+	}
+	assertMatches(t, ModeDomain, "bücher.de", cases)
+	assertMatches(t, ModeDomain, "xn--bcher-kva.de", cases)
+}
@@ -5,26 +5,50 @@ package integration
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/omer/go-bot/internal/browser"
 	"github.com/omer/go-bot/internal/config"
+	apperrors "github.com/omer/go-bot/internal/errors"
 	"github.com/omer/go-bot/internal/logger"
 	"github.com/omer/go-bot/internal/proxy"
 	"github.com/omer/go-bot/internal/serp"
+	"github.com/omer/go-bot/internal/serp/serptest"
 	"github.com/omer/go-bot/internal/stats"
 	"github.com/omer/go-bot/internal/task"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// newSearchEngine starts the local search engine the tests search on instead of Google
+func newSearchEngine(t *testing.T) *serptest.Server {
+	t.Helper()
+
+	server := serptest.NewServer(serptest.Config{
+		Results: map[string][]serptest.Result{
+			"golang": append(
+				serptest.GenerateResults("golang", 12),
+				serptest.Result{Title: "The Go Programming Language", URL: "https://go.dev/"},
+				serptest.Result{Title: "Go by Example", URL: "https://gobyexample.com/"},
+			),
+		},
+		Blocked: []string{"blocked keyword"},
+		Slow:    map[string]time.Duration{"slow keyword": time.Minute},
+	})
+	t.Cleanup(server.Close)
+	return server
+}
+
 // TestEndToEnd_SimpleSearch tests a simple search flow end-to-end
 func TestEndToEnd_SimpleSearch(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
 	}
 
+	engine := newSearchEngine(t)
+
 	log, err := logger.New(logger.Config{
 		Level: logger.ErrorLevel,
 	})
@@ -42,6 +66,7 @@ func TestEndToEnd_SimpleSearch(t *testing.T) {
 
 	// Create searcher
 	searcher := serp.NewSearcher(b, log)
+	require.NoError(t, searcher.SetBaseURL(engine.URL))
 
 	// Perform search
 	err = searcher.Search("golang tutorial")
@@ -49,12 +74,17 @@ func TestEndToEnd_SimpleSearch(t *testing.T) {
 
 	// Get results
 	results, err := searcher.GetResults()
+	require.NoError(t, err)
+	assert.Len(t, results, serptest.PerPage)
 
-	// Note: GetResults might fail with placeholder implementation
-	// This is expected and will be fixed in later phases
-	if err == nil {
-		assert.NotEmpty(t, results)
-	}
+	// Go to the next page
+	hasNext, err := searcher.NextPage()
+	require.NoError(t, err)
+	assert.True(t, hasNext)
+	assert.Equal(t, []serptest.Search{
+		{Query: "golang tutorial", Page: 1},
+		{Query: "golang tutorial", Page: 2},
+	}, engine.Searches())
 }
 
 // TestEndToEnd_TaskExecution tests full task execution with worker pool
@@ -63,6 +93,8 @@ func TestEndToEnd_TaskExecution(t *testing.T) {
 		t.Skip("Skipping integration test in short mode")
 	}
 
+	engine := newSearchEngine(t)
+
 	log, err := logger.New(logger.Config{
 		Level: logger.ErrorLevel,
 	})
@@ -75,6 +107,7 @@ func TestEndToEnd_TaskExecution(t *testing.T) {
 		QueueSize: 10,
 		ProxyPool: nil,
 		Logger:    log,
+		SearchURL: engine.URL,
 	})
 
 	err = pool.Start()
@@ -98,14 +131,99 @@ func TestEndToEnd_TaskExecution(t *testing.T) {
 
 	select {
 	case result := <-pool.GetResults():
-		assert.NotNil(t, result)
+		require.NotNil(t, result)
 		assert.Equal(t, testTask.ID, result.Task.ID)
-		// Success is not guaranteed as it depends on actual search
+		require.NoError(t, result.Error)
+		assert.True(t, result.Success)
+		assert.Equal(t, 13, result.Position)
+		assert.Equal(t, 2, result.PageNumber)
 	case <-ctx.Done():
 		t.Fatal("Timeout waiting for task result")
 	}
 }
 
+// TestEndToEnd_MockSearchEngine runs rank checks through the worker pool,
+// the browser searcher and the statistics collector against the local
+// search engine: a ranked keyword, a blocked keyword and a slow keyword
+func TestEndToEnd_MockSearchEngine(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	engine := newSearchEngine(t)
+
+	log, err := logger.New(logger.Config{
+		Level: logger.ErrorLevel,
+	})
+	require.NoError(t, err)
+	defer log.Close()
+
+	pool := task.NewWorkerPool(task.WorkerPoolConfig{
+		Workers:       3,
+		QueueSize:     3,
+		Logger:        log,
+		MaxPages:      3,
+		SearchTimeout: 10 * time.Second,
+		SearchURL:     engine.URL,
+	})
+	require.NoError(t, pool.Start())
+	defer pool.Stop()
+
+	keywords := []string{"golang", "blocked keyword", "slow keyword"}
+	for _, keyword := range keywords {
+		rankCheck, err := task.NewTask(task.TaskConfig{
+			Keyword:     keyword,
+			TargetURL:   "go.dev",
+			Competitors: []string{"gobyexample.com"},
+			Type:        task.TaskTypeRankCheck,
+		})
+		require.NoError(t, err)
+		require.NoError(t, pool.Submit(rankCheck))
+	}
+
+	collector := stats.NewStatsCollector(filepath.Join(t.TempDir(), "stats.json"))
+	results := make(map[string]*task.TaskResult)
+	timeout := time.After(2 * time.Minute)
+	for len(results) < len(keywords) {
+		select {
+		case result := <-pool.GetResults():
+			collector.RecordTask(result.ToStats())
+			results[result.Task.Keyword] = result
+		case <-timeout:
+			t.Fatalf("Timeout waiting for task results, got %d of %d", len(results), len(keywords))
+		}
+	}
+
+	// The target is on the second page, right before the competitor
+	ranked := results["golang"]
+	require.NoError(t, ranked.Error)
+	assert.Equal(t, 13, ranked.Position)
+	assert.Equal(t, 2, ranked.PageNumber)
+	require.Len(t, ranked.Competitors, 1)
+	assert.Equal(t, 14, ranked.Competitors[0].Position)
+	assert.Equal(t, serp.DefaultProfile().String(), ranked.SelectorProfile)
+
+	// The blocked keyword hits the challenge page
+	blocked := results["blocked keyword"]
+	assert.False(t, blocked.Success)
+	assert.True(t, apperrors.Is(blocked.Error, apperrors.ErrorTypeCaptcha), "got %v", blocked.Error)
+
+	// The slow keyword runs out of time
+	slow := results["slow keyword"]
+	assert.False(t, slow.Success)
+	assert.Error(t, slow.Error)
+
+	keywordStats, ok := collector.GetKeywordStats("golang", "go.dev")
+	require.True(t, ok)
+	assert.Equal(t, 13, keywordStats.LastPosition)
+	assert.Equal(t, stats.OutcomeFound, keywordStats.LastOutcome)
+
+	summary := collector.GetSummary()
+	assert.Equal(t, 3, summary["total_tasks"])
+	assert.Equal(t, 1, summary["success_tasks"])
+	assert.Equal(t, 2, summary["failed_tasks"])
+}
+
 // TestEndToEnd_ProxyRotation tests proxy rotation functionality
 func TestEndToEnd_ProxyRotation(t *testing.T) {
 	if testing.Short() {
@@ -182,6 +300,8 @@ func TestEndToEnd_SchedulerSingleCycle(t *testing.T) {
 		t.Skip("Skipping integration test in short mode")
 	}
 
+	engine := newSearchEngine(t)
+
 	cfg := &config.Config{
 		Keywords: []config.Keyword{
 			{Term: "golang", TargetURL: "go.dev"},
@@ -193,8 +313,8 @@ func TestEndToEnd_SchedulerSingleCycle(t *testing.T) {
 		MaxRetries:    3,
 		RetryDelay:    5,
 		Selectors: config.SelectorConfig{
-			SearchBox:  "textarea[name='q']",
-			ResultItem: "div.g",
+			SearchBox:  serp.SelectorList{"textarea[name='q']"},
+			ResultItem: serp.SelectorList{"div.g"},
 		},
 	}
 
@@ -210,6 +330,7 @@ func TestEndToEnd_SchedulerSingleCycle(t *testing.T) {
 		Workers:   cfg.Workers,
 		QueueSize: 10,
 		Logger:    log,
+		SearchURL: engine.URL,
 	})
 
 	scheduler := task.NewScheduler(task.SchedulerConfig{
@@ -262,6 +383,8 @@ func TestEndToEnd_BrowserOperations(t *testing.T) {
 		t.Skip("Skipping integration test in short mode")
 	}
 
+	engine := newSearchEngine(t)
+
 	b, err := browser.NewBrowser(browser.BrowserOptions{
 		Headless: true,
 		Timeout:  30 * time.Second,
@@ -270,12 +393,12 @@ func TestEndToEnd_BrowserOperations(t *testing.T) {
 	defer b.Close()
 
 	// Navigate to a page
-	err = b.Navigate("https://www.google.com")
+	err = b.Navigate(engine.URL)
 	require.NoError(t, err)
 
 	// Check if element exists
 	exists := b.ElementExists("textarea[name='q']")
-	assert.True(t, exists, "Search box should exist on Google homepage")
+	assert.True(t, exists, "Search box should exist on the home page")
 
 	// Type into search box
 	err = b.Type("textarea[name='q']", "golang")